@@ -0,0 +1,137 @@
+// Package boltstore opens embedded bolt data files for the beego blogs
+// and keeps bson documents in their buckets
+package boltstore
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/astaxie/beego"
+	"github.com/pkg/errors"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// OpenTimeout is how long Open waits for the data file lock,
+// bolt allows one process per file and would wait forever
+var OpenTimeout = 5 * time.Second
+
+var (
+	// ErrNotFound is returned when bucket has no document with requested id
+	ErrNotFound = errors.New("Document not found")
+	// ErrExists is returned when inserted id is already taken
+	ErrExists = errors.New("Document already exists")
+)
+
+// Open opens or creates data file at path and its buckets
+func Open(path string, buckets ...[]byte) (*bbolt.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: OpenTimeout})
+	if err == bbolt.ErrTimeout {
+		return nil, errors.Wrapf(err, "Data file %v is used by another process", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range buckets {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// DataPath resolves relative path against the app directory, the one
+// whose conf/app.conf beego loaded, so data files do not follow
+// the working directory the app was started from
+func DataPath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	dir := beego.AppPath
+	if conf := os.Getenv("BEEGO_CONFIG_PATH"); conf != "" {
+		if abs, err := filepath.Abs(conf); err == nil {
+			dir = filepath.Dir(filepath.Dir(abs))
+		}
+	} else if _, err := os.Stat(filepath.Join(beego.WorkPath, "conf", "app.conf")); err == nil {
+		dir = beego.WorkPath
+	}
+	return filepath.Join(dir, path)
+}
+
+// Bucket keeps bson documents keyed by string id
+type Bucket struct {
+	DB   *bbolt.DB
+	Name []byte
+}
+
+// All calls fn with every document, ordered by id
+func (b Bucket) All(fn func(doc []byte) error) error {
+	return b.DB.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(b.Name).ForEach(func(k, v []byte) error {
+			return fn(v)
+		})
+	})
+}
+
+// Get decodes document by id into doc
+func (b Bucket) Get(id string, doc interface{}) error {
+	return b.DB.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket(b.Name).Get([]byte(id))
+		if v == nil {
+			return ErrNotFound
+		}
+		return bson.Unmarshal(v, doc)
+	})
+}
+
+// Insert stores new document, the id must not be taken
+func (b Bucket) Insert(id string, doc interface{}) error {
+	return b.put(id, doc, false)
+}
+
+// Replace overwrites stored document
+func (b Bucket) Replace(id string, doc interface{}) error {
+	return b.put(id, doc, true)
+}
+
+// Delete removes document by id
+func (b Bucket) Delete(id string) error {
+	return b.DB.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(b.Name)
+		if bucket.Get([]byte(id)) == nil {
+			return ErrNotFound
+		}
+		return bucket.Delete([]byte(id))
+	})
+}
+
+func (b Bucket) put(id string, doc interface{}, exists bool) error {
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return b.DB.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket(b.Name)
+		stored := bucket.Get([]byte(id)) != nil
+		if stored && !exists {
+			return ErrExists
+		}
+		if !stored && exists {
+			return ErrNotFound
+		}
+		return bucket.Put([]byte(id), data)
+	})
+}
@@ -0,0 +1,76 @@
+package boltstore
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type doc struct {
+	ID    string
+	Title string
+}
+
+func TestOpenLocked(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "blog.db")
+	db, err := Open(path, []byte("posts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	defer func(timeout time.Duration) { OpenTimeout = timeout }(OpenTimeout)
+	OpenTimeout = 100 * time.Millisecond
+	if _, err := Open(path); errors.Cause(err) != bbolt.ErrTimeout {
+		t.Errorf("Open of locked file should time out, got %v", err)
+	}
+}
+
+func TestBucket(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "blog.db"), []byte("posts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	b := Bucket{DB: db, Name: []byte("posts")}
+
+	if err := b.Insert("1", doc{ID: "1", Title: "First"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Insert("1", doc{ID: "1"}); err != ErrExists {
+		t.Errorf("Taken id should be ErrExists, got %v", err)
+	}
+	if err := b.Replace("2", doc{ID: "2"}); err != ErrNotFound {
+		t.Errorf("Replace of missing id should be ErrNotFound, got %v", err)
+	}
+	if err := b.Replace("1", doc{ID: "1", Title: "Edited"}); err != nil {
+		t.Fatal(err)
+	}
+	got := doc{}
+	if err := b.Get("1", &got); err != nil || got.Title != "Edited" {
+		t.Errorf("Wrong document %v %v", got, err)
+	}
+
+	b.Insert("0", doc{ID: "0"})
+	ids := []string{}
+	b.All(func(raw []byte) error {
+		d := doc{}
+		err := bson.Unmarshal(raw, &d)
+		ids = append(ids, d.ID)
+		return err
+	})
+	if len(ids) != 2 || ids[0] != "0" {
+		t.Errorf("All should go in id order, got %v", ids)
+	}
+
+	if err := b.Delete("1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Get("1", &got); err != ErrNotFound {
+		t.Errorf("Deleted document should be ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,260 @@
+// Package journal persists in-memory records as a compacted snapshot
+// plus an append-only write-ahead log
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	snapshotFile = "snapshot.json"
+	logFile      = "journal.log"
+)
+
+// ErrCorrupt is returned by Load when a log record before the last one
+// is damaged. A crash can only tear the last record, anything else
+// means the log was changed and records would be lost silently.
+var ErrCorrupt = errors.New("Journal log is corrupt")
+
+// record is one write-ahead log entry
+type record struct {
+	Op   string          `json:"op"`
+	Key  string          `json:"key"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Journal keeps keyed records as a snapshot file and a log of changes since it.
+// Every log line is prefixed with its crc32, so a torn write at the
+// end of the log is detected and dropped on replay.
+type Journal struct {
+	dir           string
+	log           *os.File
+	records       int
+	snapshotEvery int
+}
+
+// Open creates journal in dir, the log is compacted into a new snapshot
+// after snapshotEvery records, 0 never compacts
+func Open(dir string, snapshotEvery int) (*Journal, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &Journal{dir: dir, snapshotEvery: snapshotEvery}, nil
+}
+
+// Load reads the snapshot, replays the log on top of it calling put
+// with every stored record in order, and opens the log for appending.
+// It tells if any data was found.
+func (j *Journal) Load(put func(key string, data []byte) error) (bool, error) {
+	found, err := j.readSnapshot(put)
+	if err != nil {
+		return false, err
+	}
+
+	f, err := os.OpenFile(j.path(logFile), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return false, err
+	}
+
+	records, good, err := replay(f, put)
+	if err != nil {
+		f.Close()
+		return false, err
+	}
+
+	// cut off a partially written tail left by a crash
+	if err := f.Truncate(good); err != nil {
+		f.Close()
+		return false, err
+	}
+	if _, err := f.Seek(good, io.SeekStart); err != nil {
+		f.Close()
+		return false, err
+	}
+
+	j.log = f
+	j.records = records
+	return found || records > 0, nil
+}
+
+// Append writes v as record with key to the log and syncs it to disk
+func (j *Journal) Append(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(record{Op: "put", Key: key, Data: data})
+	if err != nil {
+		return err
+	}
+
+	if _, err := j.log.WriteString(fmt.Sprintf("%08x %s\n", crc32.ChecksumIEEE(line), line)); err != nil {
+		return err
+	}
+	if err := j.log.Sync(); err != nil {
+		return err
+	}
+
+	j.records++
+	return nil
+}
+
+// NeedsSnapshot tells if the log has grown enough to be compacted
+func (j *Journal) NeedsSnapshot() bool {
+	return j.snapshotEvery > 0 && j.records >= j.snapshotEvery
+}
+
+// Snapshot atomically replaces the snapshot with records and empties the log,
+// records must encode to a json object by key like a map does
+func (j *Journal) Snapshot(records interface{}) error {
+	data, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+
+	tmp := j.path(snapshotFile + ".tmp")
+	if err := writeFileSync(tmp, data); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, j.path(snapshotFile)); err != nil {
+		return err
+	}
+	if err := syncDir(j.dir); err != nil {
+		return err
+	}
+
+	// replaying the old log over the new snapshot is harmless,
+	// so a crash before this point loses nothing
+	if err := j.log.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := j.log.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	j.records = 0
+	return j.log.Sync()
+}
+
+// Close closes the log
+func (j *Journal) Close() error {
+	if j.log == nil {
+		return nil
+	}
+	return j.log.Close()
+}
+
+func (j *Journal) path(name string) string {
+	return filepath.Join(j.dir, name)
+}
+
+func (j *Journal) readSnapshot(put func(key string, data []byte) error) (bool, error) {
+	data, err := ioutil.ReadFile(j.path(snapshotFile))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	records := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &records); err != nil {
+		return false, errors.Wrap(err, "Can not read snapshot")
+	}
+	for key, r := range records {
+		if err := put(key, r); err != nil {
+			return false, errors.Wrap(err, "Can not read snapshot")
+		}
+	}
+	return true, nil
+}
+
+// replay applies log records and returns their number and the offset
+// of the end of the last valid one. A damaged last line is a torn write
+// and is left for truncation, a damaged line before others is ErrCorrupt.
+func replay(r io.Reader, put func(key string, data []byte) error) (int, int64, error) {
+	reader := bufio.NewReader(r)
+	records := 0
+	var good int64
+	for {
+		line, err := reader.ReadString('\n')
+		if err == io.EOF {
+			// a line without newline was cut by a crash while written
+			return records, good, nil
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+
+		rec, ok := parseRecord(strings.TrimSuffix(line, "\n"))
+		if !ok {
+			if _, err := reader.Peek(1); err == io.EOF {
+				return records, good, nil
+			}
+			return 0, 0, errors.Wrapf(ErrCorrupt, "Bad record at offset %v", good)
+		}
+		if rec.Op == "put" {
+			if err := put(rec.Key, rec.Data); err != nil {
+				return 0, 0, errors.Wrapf(err, "Can not apply record at offset %v", good)
+			}
+		}
+
+		records++
+		good += int64(len(line))
+	}
+}
+
+func parseRecord(line string) (*record, bool) {
+	parts := strings.SplitN(line, " ", 2)
+	if len(parts) != 2 {
+		return nil, false
+	}
+
+	var sum uint32
+	if _, err := fmt.Sscanf(parts[0], "%08x", &sum); err != nil {
+		return nil, false
+	}
+	if crc32.ChecksumIEEE([]byte(parts[1])) != sum {
+		return nil, false
+	}
+
+	rec := &record{}
+	if err := json.Unmarshal([]byte(parts[1]), rec); err != nil {
+		return nil, false
+	}
+	return rec, true
+}
+
+func writeFileSync(name string, data []byte) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,150 @@
+package journal
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type post struct {
+	ID    int
+	Title string
+}
+
+// load opens journal in dir and reads its records by key
+func load(t *testing.T, dir string, snapshotEvery int) (*Journal, map[string]post, bool) {
+	j, err := Open(dir, snapshotEvery)
+	if err != nil {
+		t.Fatal(err)
+	}
+	posts := map[string]post{}
+	found, err := j.Load(func(key string, data []byte) error {
+		p := post{}
+		err := json.Unmarshal(data, &p)
+		posts[key] = p
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { j.Close() })
+	return j, posts, found
+}
+
+func logSize(t *testing.T, dir string) int64 {
+	info, err := os.Stat(filepath.Join(dir, logFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Size()
+}
+
+func TestReplay(t *testing.T) {
+	dir := t.TempDir()
+	j, posts, found := load(t, dir, 0)
+	if found || len(posts) != 0 {
+		t.Fatal("New journal should be empty")
+	}
+	if err := j.Snapshot(map[int]post{0: {ID: 0, Title: "First"}, 1: {ID: 0, Title: "Same id"}}); err != nil {
+		t.Fatal(err)
+	}
+	j.Append("2", post{ID: 2, Title: "Second"})
+	j.Append("2", post{ID: 2, Title: "Second edited"})
+	j.Close()
+
+	_, posts, found = load(t, dir, 0)
+	if !found || len(posts) != 3 || posts["1"].Title != "Same id" || posts["2"].Title != "Second edited" {
+		t.Errorf("Reopened journal should have snapshot and log records by key, got %v", posts)
+	}
+}
+
+func TestTornTail(t *testing.T) {
+	dir := t.TempDir()
+	j, _, _ := load(t, dir, 0)
+	j.Append("1", post{ID: 1, Title: "Kept"})
+	j.Close()
+	good := logSize(t, dir)
+
+	for _, tail := range []string{`0000`, "00000000 {\"op\":\"put\"}\n"} {
+		f, err := os.OpenFile(filepath.Join(dir, logFile), os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString(tail)
+		f.Close()
+
+		j, posts, _ := load(t, dir, 0)
+		if len(posts) != 1 || posts["1"].Title != "Kept" {
+			t.Errorf("Torn tail %q should be dropped, got %v", tail, posts)
+		}
+		if size := logSize(t, dir); size != good {
+			t.Errorf("Torn tail %q should be truncated, log has %v bytes, want %v", tail, size, good)
+		}
+		j.Close()
+	}
+
+	j, _, _ = load(t, dir, 0)
+	j.Append("2", post{ID: 2, Title: "After crash"})
+	j.Close()
+	if _, posts, _ := load(t, dir, 0); len(posts) != 2 {
+		t.Errorf("Records appended after truncation should be replayed, got %v", posts)
+	}
+}
+
+func TestCorruptLog(t *testing.T) {
+	dir := t.TempDir()
+	j, _, _ := load(t, dir, 0)
+	j.Append("1", post{ID: 1, Title: "First"})
+	j.Append("2", post{ID: 2, Title: "Second"})
+	j.Close()
+
+	path := filepath.Join(dir, logFile)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len("00000000 ")+5] ^= 1
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err = Open(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.Load(func(string, []byte) error { return nil }); errors.Cause(err) != ErrCorrupt {
+		t.Errorf("Damaged record before others should be ErrCorrupt, got %v", err)
+	}
+	if size := logSize(t, dir); size != int64(len(data)) {
+		t.Error("Corrupt log should be left as it is")
+	}
+}
+
+func TestSnapshotEvery(t *testing.T) {
+	dir := t.TempDir()
+	j, _, _ := load(t, dir, 2)
+	all := map[int]post{}
+	for i := 1; i <= 3; i++ {
+		p := post{ID: i}
+		j.Append(string(rune('0'+i)), p)
+		all[i] = p
+		if j.NeedsSnapshot() {
+			if err := j.Snapshot(all); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	j.Close()
+
+	_, posts, _ := load(t, dir, 2)
+	if len(posts) != 3 {
+		t.Errorf("Snapshot with log should have every record, got %v", posts)
+	}
+	if j.records != 1 {
+		t.Errorf("Snapshot should empty the log, got %v records", j.records)
+	}
+}
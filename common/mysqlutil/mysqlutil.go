@@ -0,0 +1,73 @@
+// Package mysqlutil has helpers shared by the mysql post stores:
+// typed errors and preparing statement lists
+package mysqlutil
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// ConstraintError is returned when a write violates a table constraint
+type ConstraintError struct {
+	Err *mysql.MySQLError
+}
+
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("Constraint violation: %v", e.Err.Message)
+}
+
+// Cause returns underlying mysql error
+func (e *ConstraintError) Cause() error {
+	return e.Err
+}
+
+// mysql error numbers for constraint violations
+var constraintErrors = map[uint16]bool{
+	1048: true, // column cannot be null
+	1062: true, // duplicate entry
+	1216: true, // foreign key, no parent row
+	1217: true, // foreign key, row is referenced
+	1451: true, // foreign key, row is referenced
+	1452: true, // foreign key, no parent row
+	3819: true, // check constraint
+}
+
+// Wrap gives err a type callers can check: no rows becomes notFound
+// and constraint violations become *ConstraintError
+func Wrap(err, notFound error) error {
+	if err == sql.ErrNoRows {
+		return notFound
+	}
+	if myErr, ok := err.(*mysql.MySQLError); ok && constraintErrors[myErr.Number] {
+		return &ConstraintError{Err: myErr}
+	}
+	return err
+}
+
+// Preparer prepares statements until the first failure, so a store
+// prepares all of its statements and checks Err once
+type Preparer struct {
+	DB  *sql.DB
+	Err error
+}
+
+// Prepare prepares query, it returns nil once a statement has failed
+func (p *Preparer) Prepare(query string) *sql.Stmt {
+	if p.Err != nil {
+		return nil
+	}
+	var stmt *sql.Stmt
+	stmt, p.Err = p.DB.Prepare(query)
+	return stmt
+}
+
+// CloseStmts closes prepared statements skipping ones never prepared
+func CloseStmts(stmts ...*sql.Stmt) {
+	for _, stmt := range stmts {
+		if stmt != nil {
+			stmt.Close()
+		}
+	}
+}
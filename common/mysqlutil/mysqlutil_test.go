@@ -0,0 +1,85 @@
+package mysqlutil
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+)
+
+var errNotFound = errors.New("not found")
+
+func TestWrap(t *testing.T) {
+	if err := Wrap(sql.ErrNoRows, errNotFound); err != errNotFound {
+		t.Errorf("No rows should be not found error, got %v", err)
+	}
+	dup := &mysql.MySQLError{Number: 1062, Message: "Duplicate entry"}
+	err := Wrap(dup, errNotFound)
+	if _, ok := err.(*ConstraintError); !ok || errors.Cause(err) != dup {
+		t.Errorf("Duplicate entry should be ConstraintError caused by mysql error, got %#v", err)
+	}
+	other := &mysql.MySQLError{Number: 1146, Message: "No such table"}
+	if err := Wrap(other, errNotFound); err != other {
+		t.Errorf("Other errors should be kept, got %v", err)
+	}
+}
+
+// failingConnector makes connections failing to prepare one query
+type failingConnector struct {
+	failOn   string
+	prepared []string
+}
+
+func (c *failingConnector) Connect(context.Context) (driver.Conn, error) {
+	return c, nil
+}
+
+func (c *failingConnector) Driver() driver.Driver {
+	return nil
+}
+
+func (c *failingConnector) Prepare(query string) (driver.Stmt, error) {
+	c.prepared = append(c.prepared, query)
+	if query == c.failOn {
+		return nil, errors.New("bad query")
+	}
+	return c, nil
+}
+
+func (c *failingConnector) Close() error {
+	return nil
+}
+
+func (c *failingConnector) Begin() (driver.Tx, error) {
+	return nil, errors.New("no transactions")
+}
+
+func (c *failingConnector) NumInput() int {
+	return -1
+}
+
+func (c *failingConnector) Exec(args []driver.Value) (driver.Result, error) {
+	return driver.RowsAffected(0), nil
+}
+
+func (c *failingConnector) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("no rows")
+}
+
+func TestPreparer(t *testing.T) {
+	conn := &failingConnector{failOn: "second"}
+	p := &Preparer{DB: sql.OpenDB(conn)}
+	first := p.Prepare("first")
+	second := p.Prepare("second")
+	third := p.Prepare("third")
+	if first == nil || second != nil || third != nil || p.Err == nil {
+		t.Errorf("Preparing should stop at first failure, got %v %v %v %v", first, second, third, p.Err)
+	}
+	if len(conn.prepared) != 2 {
+		t.Errorf("Statements after failure should not be prepared, got %v", conn.prepared)
+	}
+	CloseStmts(first, second, third)
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"common/journal"
+	"encoding/json"
 	"html/template"
 	"log"
 	"net/http"
@@ -17,6 +19,11 @@ const (
 	listPosts = "listPosts.html"
 )
 
+const (
+	dataDir       = "data"
+	snapshotEvery = 100
+)
+
 var templateFiles = []string{
 	editPost,
 	showPost,
@@ -26,6 +33,7 @@ var templateFiles = []string{
 // BlogServer struct
 type BlogServer struct {
 	mu        sync.Mutex
+	journal   *journal.Journal
 	Title     string
 	Posts     map[int]*BlogPost
 	Templates map[string]*template.Template
@@ -70,6 +78,41 @@ func (s *BlogServer) unLock() {
 }
 
 func (s *BlogServer) loadPosts() map[int]*BlogPost {
+	j, err := journal.Open(dataDir, snapshotEvery)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s.journal = j
+
+	// posts are kept by their map key, it is not always post id
+	r := make(map[int]*BlogPost)
+	found, err := j.Load(func(key string, data []byte) error {
+		id, err := strconv.Atoi(key)
+		if err != nil {
+			return err
+		}
+		post := &BlogPost{}
+		if err := json.Unmarshal(data, post); err != nil {
+			return err
+		}
+		r[id] = post
+		return nil
+	})
+	if err != nil {
+		log.Fatal(errors.Wrap(err, "Can not load posts"))
+	}
+	if found {
+		return r
+	}
+
+	r = samplePosts()
+	if err := j.Snapshot(r); err != nil {
+		log.Fatal(errors.Wrap(err, "Can not write snapshot"))
+	}
+	return r
+}
+
+func samplePosts() map[int]*BlogPost {
 	r := make(map[int]*BlogPost)
 	r[0] = &BlogPost{
 		ID:      0,
@@ -140,14 +183,31 @@ func (s *BlogServer) createNewPost(wr http.ResponseWriter, req *http.Request) {
 		p.Date = req.FormValue("date")
 		p.Link = req.FormValue("link")
 		p.Content = req.FormValue("content")
-		s.addPost(p)
+		if err := s.addPost(p); err != nil {
+			err = errors.Wrap(err, "Can not save post")
+			http.Error(wr, err.Error(), http.StatusInternalServerError)
+			log.Print(err)
+			return
+		}
 	}
 }
 
-func (s *BlogServer) addPost(post *BlogPost) {
+// addPost writes post to the journal before it becomes visible
+func (s *BlogServer) addPost(post *BlogPost) error {
 	s.lock()
 	defer s.unLock()
+
+	if err := s.journal.Append(strconv.Itoa(post.ID), post); err != nil {
+		return err
+	}
 	s.Posts[post.ID] = post
+
+	if s.journal.NeedsSnapshot() {
+		if err := s.journal.Snapshot(s.Posts); err != nil {
+			log.Print(errors.Wrap(err, "Can not write snapshot"))
+		}
+	}
+	return nil
 }
 
 func (s *BlogServer) handleRoot(wr http.ResponseWriter, req *http.Request) {
@@ -10,7 +10,7 @@ CREATE TABLE blog.posts (
   UNIQUE INDEX `id_UNIQUE` (id ASC) VISIBLE);
 
 INSERT INTO blog.posts (title, postdate, link, content)
-VALUES ("Title1", '2020-21-02', "https://google/link1", "Test content1");
+VALUES ("Title1", '2020-02-21', "https://google/link1", "Test content1");
 
 INSERT INTO blog.posts (title, postdate, link, content)
-VALUES ("Title2", '2020-22-02', "https://google/link2", "Test content2");
\ No newline at end of file
+VALUES ("Title2", '2020-02-22', "https://google/link2", "Test content2");
\ No newline at end of file
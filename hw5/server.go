@@ -1,7 +1,6 @@
 package main
 
 import (
-	"fmt"
 	"html/template"
 	"log"
 	"net/http"
@@ -10,13 +9,12 @@ import (
 	"sync"
 
 	"database/sql"
+	"hw5_2/models"
 
-	_ "github.com/go-sql-driver/MySQL"
+	_ "github.com/go-sql-driver/mysql"
 	"github.com/pkg/errors"
 )
 
-var database *sql.DB
-
 const (
 	editPost  = "editPost.html"
 	showPost  = "showPost.html"
@@ -31,6 +29,7 @@ var templateFiles = []string{
 
 // BlogServer struct
 type BlogServer struct {
+	posts     *models.PostRepository
 	mu        sync.Mutex
 	Title     string
 	Posts     map[int]*BlogPost
@@ -47,16 +46,21 @@ type BlogPost struct {
 }
 
 func main() {
-	db, err := sql.Open("MySQL", "root:pwd1234567@/blog")
+	db, err := sql.Open("mysql", "root:pwd1234567@/blog")
 	if err != nil {
 		log.Fatal(err)
 	}
-	database = db
-	defer database.Close()
+	defer db.Close()
+
+	posts, err := models.NewPostRepository(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer posts.Close()
 
 	router := http.NewServeMux()
 
-	server := BlogServer{}
+	server := BlogServer{posts: posts}
 	server.init()
 
 	router.HandleFunc("/", server.handleRoot)
@@ -82,35 +86,31 @@ func (s *BlogServer) unLock() {
 }
 
 func (s *BlogServer) getAllPosts() (map[int]*BlogPost, error) {
-	r := make(map[int]*BlogPost)
-	rows, err := database.Query("select * from blog.posts")
+	posts, err := s.posts.ListPosts()
 	if err != nil {
-		return r, err
+		return nil, err
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		post := &BlogPost{}
-		err := rows.Scan(&post.ID, &post.Title, &post.Date, &post.Link, &post.Content)
-		if err != nil {
-			log.Println(err)
-			continue
-		}
-		r[post.ID] = post
+	r := make(map[int]*BlogPost, len(posts))
+	for _, p := range posts {
+		post := BlogPost(p)
+		r[post.ID] = &post
 	}
 	return r, nil
 }
 
 func (s *BlogServer) getPostByID(postID string) (*BlogPost, error) {
-	post := &BlogPost{}
-
-	row := database.QueryRow(fmt.Sprintf("select * from blog.posts where posts.id = %v", postID))
-	err := row.Scan(&post.ID, &post.Title, &post.Date, &post.Link, &post.Content)
+	id, err := strconv.Atoi(postID)
 	if err != nil {
-		return post, err
+		return nil, errors.Wrapf(err, "Can not parse id value: %v", postID)
 	}
 
-	return post, nil
+	post, err := s.posts.GetPost(id)
+	if err != nil {
+		return nil, err
+	}
+	r := BlogPost(post)
+	return &r, nil
 }
 
 func (s *BlogServer) getTemplate(name string) *template.Template {
@@ -132,7 +132,9 @@ func (s *BlogServer) loadTemplates() map[string]*template.Template {
 	return r
 }
 
-func (s *BlogServer) createNewPost(wr http.ResponseWriter, req *http.Request) {
+// createNewPost adds post sent in form, failures are sent
+// to the client and returned so nothing else is written
+func (s *BlogServer) createNewPost(wr http.ResponseWriter, req *http.Request) error {
 	postID := req.FormValue("id")
 	if len(postID) > 0 {
 		id, err := strconv.Atoi(postID)
@@ -140,7 +142,7 @@ func (s *BlogServer) createNewPost(wr http.ResponseWriter, req *http.Request) {
 			err := errors.Wrapf(err, "Can not parse id value: %v", postID)
 			http.Error(wr, err.Error(), http.StatusInternalServerError)
 			log.Print(err)
-			return
+			return err
 		}
 
 		p := &BlogPost{}
@@ -149,24 +151,37 @@ func (s *BlogServer) createNewPost(wr http.ResponseWriter, req *http.Request) {
 		p.Date = req.FormValue("date")
 		p.Link = req.FormValue("link")
 		p.Content = req.FormValue("content")
-		s.addPost(p)
+		if err := s.addPost(p); err != nil {
+			err = errors.Wrap(err, "Can not create post")
+			http.Error(wr, err.Error(), postErrorStatus(err))
+			log.Print(err)
+			return err
+		}
 	}
+	return nil
 }
 
 func (s *BlogServer) addPost(post *BlogPost) error {
-	cmd := fmt.Sprintf("insert into blog.posts (title, postdate, link, content) values(\"%v\", \"%v\", \"%v\", \"%v\")", post.Title, post.Date, post.Link, post.Content)
-	res, err := database.Exec(cmd)
-	if err != nil {
-		return err
-	}
-	id, err := res.LastInsertId()
-	if err != nil {
+	p := models.BlogPost(*post)
+	if err := s.posts.CreatePost(&p); err != nil {
 		return err
 	}
-	post.ID = int(id)
+	post.ID = p.ID
 	return nil
 }
 
+// postErrorStatus is response status of post repository error
+func postErrorStatus(err error) int {
+	cause := errors.Cause(err)
+	if cause == models.ErrPostNotFound {
+		return http.StatusNotFound
+	}
+	if _, ok := cause.(*models.ConstraintError); ok {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
 func (s *BlogServer) handleRoot(wr http.ResponseWriter, req *http.Request) {
 	t := s.getTemplate(listPosts)
 	if t == nil {
@@ -176,7 +191,9 @@ func (s *BlogServer) handleRoot(wr http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	s.createNewPost(wr, req)
+	if err := s.createNewPost(wr, req); err != nil {
+		return
+	}
 
 	posts, err := s.getAllPosts()
 	if err != nil {
@@ -209,7 +226,7 @@ func (s *BlogServer) handlePost(wr http.ResponseWriter, req *http.Request) {
 	post, err := s.getPostByID(postID)
 	if err != nil {
 		err := errors.Wrap(err, "No post found")
-		http.Error(wr, err.Error(), http.StatusInternalServerError)
+		http.Error(wr, err.Error(), postErrorStatus(err))
 		log.Print(err)
 		return
 	}
@@ -236,7 +253,7 @@ func (s *BlogServer) handleEdit(wr http.ResponseWriter, req *http.Request) {
 	post, err := s.getPostByID(postID)
 	if err != nil {
 		err := errors.Wrap(err, "No post found")
-		http.Error(wr, err.Error(), http.StatusInternalServerError)
+		http.Error(wr, err.Error(), postErrorStatus(err))
 		log.Print(err)
 		return
 	}
@@ -2,16 +2,17 @@ package main
 
 import (
 	"database/sql"
-	"fmt"
+	"html/template"
+	"hw5_2/models"
 	"log"
 	"net/http"
 	"path"
 	"strconv"
 	"sync"
-	"text/template"
 
 	"github.com/astaxie/beego"
 	"github.com/astaxie/beego/context"
+	_ "github.com/go-sql-driver/mysql"
 	"github.com/pkg/errors"
 )
 
@@ -30,6 +31,7 @@ var templateFiles = []string{
 // BlogServer struct
 type BlogServer struct {
 	database  *sql.DB
+	posts     *models.PostRepository
 	mu        sync.Mutex
 	Title     string
 	Posts     map[int]*BlogPost
@@ -61,11 +63,16 @@ func (s *BlogServer) init() {
 	s.Title = "Blog"
 	s.Templates = s.loadTemplates()
 
-	db, err := sql.Open("MySQL", "root:pwd1234567@/blog")
+	db, err := sql.Open("mysql", "root:pwd1234567@/blog")
 	if err != nil {
 		log.Fatal(err)
 	}
 	s.database = db
+
+	s.posts, err = models.NewPostRepository(db)
+	if err != nil {
+		log.Fatal(err)
+	}
 }
 
 func (s *BlogServer) loadTemplates() map[string]*template.Template {
@@ -78,6 +85,7 @@ func (s *BlogServer) loadTemplates() map[string]*template.Template {
 }
 
 func (s *BlogServer) shutDown() {
+	s.posts.Close()
 	s.database.Close()
 }
 
@@ -90,7 +98,9 @@ func (s *BlogServer) handleRoot(ctx *context.Context) {
 		return
 	}
 
-	s.createNewPost(ctx.ResponseWriter, ctx.Request)
+	if err := s.createNewPost(ctx.ResponseWriter, ctx.Request); err != nil {
+		return
+	}
 
 	posts, err := s.getAllPosts()
 	if err != nil {
@@ -123,7 +133,7 @@ func (s *BlogServer) handlePost(ctx *context.Context) {
 	post, err := s.getPostByID(postID)
 	if err != nil {
 		err := errors.Wrap(err, "No post found")
-		http.Error(ctx.ResponseWriter, err.Error(), http.StatusInternalServerError)
+		http.Error(ctx.ResponseWriter, err.Error(), postErrorStatus(err))
 		log.Print(err)
 		return
 	}
@@ -150,7 +160,7 @@ func (s *BlogServer) handleEdit(ctx *context.Context) {
 	post, err := s.getPostByID(postID)
 	if err != nil {
 		err := errors.Wrap(err, "No post found")
-		http.Error(ctx.ResponseWriter, err.Error(), http.StatusInternalServerError)
+		http.Error(ctx.ResponseWriter, err.Error(), postErrorStatus(err))
 		log.Print(err)
 		return
 	}
@@ -174,7 +184,9 @@ func (s *BlogServer) getTemplate(name string) *template.Template {
 	return nil
 }
 
-func (s *BlogServer) createNewPost(wr http.ResponseWriter, req *http.Request) {
+// createNewPost adds post sent in form, failures are sent
+// to the client and returned so nothing else is written
+func (s *BlogServer) createNewPost(wr http.ResponseWriter, req *http.Request) error {
 	postID := req.FormValue("id")
 	if len(postID) > 0 {
 		id, err := strconv.Atoi(postID)
@@ -182,7 +194,7 @@ func (s *BlogServer) createNewPost(wr http.ResponseWriter, req *http.Request) {
 			err := errors.Wrapf(err, "Can not parse id value: %v", postID)
 			http.Error(wr, err.Error(), http.StatusInternalServerError)
 			log.Print(err)
-			return
+			return err
 		}
 
 		p := &BlogPost{}
@@ -191,52 +203,61 @@ func (s *BlogServer) createNewPost(wr http.ResponseWriter, req *http.Request) {
 		p.Date = req.FormValue("date")
 		p.Link = req.FormValue("link")
 		p.Content = req.FormValue("content")
-		s.addPost(p)
+		if err := s.addPost(p); err != nil {
+			err = errors.Wrap(err, "Can not create post")
+			http.Error(wr, err.Error(), postErrorStatus(err))
+			log.Print(err)
+			return err
+		}
 	}
+	return nil
 }
 
 func (s *BlogServer) getAllPosts() (map[int]*BlogPost, error) {
-	r := make(map[int]*BlogPost)
-	rows, err := s.database.Query("select * from blog.posts")
+	posts, err := s.posts.ListPosts()
 	if err != nil {
-		return r, err
+		return nil, err
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		post := &BlogPost{}
-		err := rows.Scan(&post.ID, &post.Title, &post.Date, &post.Link, &post.Content)
-		if err != nil {
-			log.Println(err)
-			continue
-		}
-		r[post.ID] = post
+	r := make(map[int]*BlogPost, len(posts))
+	for _, p := range posts {
+		post := BlogPost(p)
+		r[post.ID] = &post
 	}
 	return r, nil
 }
 
 func (s *BlogServer) getPostByID(postID string) (*BlogPost, error) {
-	post := &BlogPost{}
-
-	row := s.database.QueryRow(fmt.Sprintf("select * from blog.posts where posts.id = %v", postID))
-	err := row.Scan(&post.ID, &post.Title, &post.Date, &post.Link, &post.Content)
+	id, err := strconv.Atoi(postID)
 	if err != nil {
-		return post, err
+		return nil, errors.Wrapf(err, "Can not parse id value: %v", postID)
 	}
 
-	return post, nil
+	post, err := s.posts.GetPost(id)
+	if err != nil {
+		return nil, err
+	}
+	r := BlogPost(post)
+	return &r, nil
 }
 
 func (s *BlogServer) addPost(post *BlogPost) error {
-	cmd := fmt.Sprintf("insert into blog.posts (title, postdate, link, content) values(\"%v\", \"%v\", \"%v\", \"%v\")", post.Title, post.Date, post.Link, post.Content)
-	res, err := s.database.Exec(cmd)
-	if err != nil {
-		return err
-	}
-	id, err := res.LastInsertId()
-	if err != nil {
+	p := models.BlogPost(*post)
+	if err := s.posts.CreatePost(&p); err != nil {
 		return err
 	}
-	post.ID = int(id)
+	post.ID = p.ID
 	return nil
 }
+
+// postErrorStatus is response status of post repository error
+func postErrorStatus(err error) int {
+	cause := errors.Cause(err)
+	if cause == models.ErrPostNotFound {
+		return http.StatusNotFound
+	}
+	if _, ok := cause.(*models.ConstraintError); ok {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
@@ -1,8 +1,6 @@
 package controllers
 
 import (
-	"database/sql"
-	"fmt"
 	"hw5_2/models"
 	"log"
 	"net/http"
@@ -15,7 +13,7 @@ import (
 // MainController controller
 type MainController struct {
 	beego.Controller
-	DB *sql.DB
+	Posts models.PostStore
 }
 
 // Get gets main page
@@ -55,7 +53,7 @@ func (c *MainController) Post() {
 		err = c.addPost(p)
 		if err != nil {
 			err = errors.Wrap(err, "Can not create post")
-			http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusInternalServerError)
+			http.Error(c.Ctx.ResponseWriter, err.Error(), postErrorStatus(err))
 			log.Print(err)
 			return
 		}
@@ -74,7 +72,7 @@ func (c *MainController) ShowPost() {
 	post, err := c.getPostByID(postID)
 	if err != nil {
 		err := errors.Wrap(err, "No post found")
-		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusInternalServerError)
+		http.Error(c.Ctx.ResponseWriter, err.Error(), postErrorStatus(err))
 		log.Print(err)
 		return
 	}
@@ -92,7 +90,7 @@ func (c *MainController) EditPost() {
 	post, err := c.getPostByID(postID)
 	if err != nil {
 		err := errors.Wrap(err, "No post found")
-		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusInternalServerError)
+		http.Error(c.Ctx.ResponseWriter, err.Error(), postErrorStatus(err))
 		log.Print(err)
 		return
 	}
@@ -103,47 +101,28 @@ func (c *MainController) EditPost() {
 }
 
 func (c *MainController) getAllPosts() ([]models.BlogPost, error) {
-	r := make([]models.BlogPost, 0)
-	rows, err := c.DB.Query("select * from blog.posts")
-	if err != nil {
-		return r, err
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		post := models.BlogPost{}
-		err := rows.Scan(&post.ID, &post.Title, &post.Date, &post.Link, &post.Content)
-		if err != nil {
-			log.Println(err)
-			continue
-		}
-		r = append(r, post)
-	}
-	return r, nil
+	return c.Posts.ListPosts()
 }
 
 func (c *MainController) addPost(post *models.BlogPost) error {
-	cmd := fmt.Sprintf("insert into blog.posts (title, postdate, link, content) values(\"%v\", \"%v\", \"%v\", \"%v\")", post.Title, post.Date, post.Link, post.Content)
-	res, err := c.DB.Exec(cmd)
-	if err != nil {
-		return err
-	}
-	id, err := res.LastInsertId()
-	if err != nil {
-		return err
-	}
-	post.ID = int(id)
-	return nil
+	return c.Posts.CreatePost(post)
 }
 
 func (c *MainController) getPostByID(postID string) (models.BlogPost, error) {
-	post := models.BlogPost{}
-
-	row := c.DB.QueryRow(fmt.Sprintf("select * from blog.posts where posts.id = %v", postID))
-	err := row.Scan(&post.ID, &post.Title, &post.Date, &post.Link, &post.Content)
+	id, err := strconv.Atoi(postID)
 	if err != nil {
-		return post, err
+		return models.BlogPost{}, errors.Wrapf(err, "Can not parse id value: %v", postID)
 	}
+	return c.Posts.GetPost(id)
+}
 
-	return post, nil
+func postErrorStatus(err error) int {
+	cause := errors.Cause(err)
+	if cause == models.ErrPostNotFound {
+		return http.StatusNotFound
+	}
+	if _, ok := cause.(*models.ConstraintError); ok {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
 }
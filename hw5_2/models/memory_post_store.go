@@ -0,0 +1,80 @@
+package models
+
+import (
+	"sort"
+	"sync"
+)
+
+// MemoryPostStore keeps posts in memory, data is lost on restart
+type MemoryPostStore struct {
+	mu     sync.Mutex
+	lastID int
+	posts  map[int]BlogPost
+}
+
+// NewMemoryPostStore creates empty memory post store
+func NewMemoryPostStore() *MemoryPostStore {
+	return &MemoryPostStore{posts: make(map[int]BlogPost)}
+}
+
+// ListPosts gets all posts ordered by id
+func (s *MemoryPostStore) ListPosts() ([]BlogPost, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	posts := make([]BlogPost, 0, len(s.posts))
+	for _, p := range s.posts {
+		posts = append(posts, p)
+	}
+	sort.Slice(posts, func(i, j int) bool {
+		return posts[i].ID < posts[j].ID
+	})
+	return posts, nil
+}
+
+// GetPost gets post by id
+func (s *MemoryPostStore) GetPost(id int) (BlogPost, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	post, ok := s.posts[id]
+	if !ok {
+		return BlogPost{}, ErrPostNotFound
+	}
+	return post, nil
+}
+
+// CreatePost adds new post
+func (s *MemoryPostStore) CreatePost(post *BlogPost) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID++
+	post.ID = s.lastID
+	s.posts[post.ID] = *post
+	return nil
+}
+
+// UpdatePost updates post
+func (s *MemoryPostStore) UpdatePost(post *BlogPost) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.posts[post.ID]; !ok {
+		return ErrPostNotFound
+	}
+	s.posts[post.ID] = *post
+	return nil
+}
+
+// DeletePost deletes post
+func (s *MemoryPostStore) DeletePost(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.posts[id]; !ok {
+		return ErrPostNotFound
+	}
+	delete(s.posts, id)
+	return nil
+}
@@ -0,0 +1,123 @@
+package models
+
+import (
+	"common/mysqlutil"
+	"database/sql"
+
+	"github.com/pkg/errors"
+)
+
+// ConstraintError is returned when a write violates a table constraint
+type ConstraintError = mysqlutil.ConstraintError
+
+func wrapError(err error) error {
+	return mysqlutil.Wrap(err, ErrPostNotFound)
+}
+
+const postColumns = "id, title, postdate, link, content"
+
+// PostRepository is PostStore keeping posts in mysql posts table,
+// it reads and writes posts with prepared statements
+type PostRepository struct {
+	list   *sql.Stmt
+	get    *sql.Stmt
+	insert *sql.Stmt
+	update *sql.Stmt
+	delete *sql.Stmt
+}
+
+// NewPostRepository prepares post statements
+func NewPostRepository(db *sql.DB) (*PostRepository, error) {
+	r := &PostRepository{}
+	p := &mysqlutil.Preparer{DB: db}
+	prepare := p.Prepare
+
+	r.list = prepare("select " + postColumns + " from posts order by id")
+	r.get = prepare("select " + postColumns + " from posts where id = ?")
+	r.insert = prepare("insert into posts (title, postdate, link, content) values (?, ?, ?, ?)")
+	r.update = prepare("update posts set title = ?, postdate = ?, link = ?, content = ? where id = ?")
+	r.delete = prepare("delete from posts where id = ?")
+	if p.Err != nil {
+		r.Close()
+		return nil, errors.Wrap(p.Err, "Can not prepare post statements")
+	}
+	return r, nil
+}
+
+// Close releases prepared statements
+func (r *PostRepository) Close() error {
+	mysqlutil.CloseStmts(r.list, r.get, r.insert, r.update, r.delete)
+	return nil
+}
+
+// ListPosts gets all posts, a row that can not be read fails the list
+func (r *PostRepository) ListPosts() ([]BlogPost, error) {
+	rows, err := r.list.Query()
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer rows.Close()
+
+	posts := make([]BlogPost, 0)
+	for rows.Next() {
+		post := BlogPost{}
+		err := rows.Scan(&post.ID, &post.Title, &post.Date, &post.Link, &post.Content)
+		if err != nil {
+			return nil, errors.Wrap(err, "Can not read post")
+		}
+		posts = append(posts, post)
+	}
+	return posts, rows.Err()
+}
+
+// GetPost gets post by id
+func (r *PostRepository) GetPost(id int) (BlogPost, error) {
+	post := BlogPost{}
+	row := r.get.QueryRow(id)
+	err := row.Scan(&post.ID, &post.Title, &post.Date, &post.Link, &post.Content)
+	if err != nil {
+		return post, wrapError(err)
+	}
+	return post, nil
+}
+
+// CreatePost inserts post and sets its id
+func (r *PostRepository) CreatePost(post *BlogPost) error {
+	res, err := r.insert.Exec(post.Title, post.Date, post.Link, post.Content)
+	if err != nil {
+		return wrapError(err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	post.ID = int(id)
+	return nil
+}
+
+// UpdatePost updates post, dsn needs clientFoundRows=true
+// so updates of unchanged posts are not taken for missing ones
+func (r *PostRepository) UpdatePost(post *BlogPost) error {
+	return execAffected(r.update, post.Title, post.Date, post.Link, post.Content, post.ID)
+}
+
+// DeletePost deletes post by id
+func (r *PostRepository) DeletePost(id int) error {
+	return execAffected(r.delete, id)
+}
+
+// execAffected runs statement which must change a row
+func execAffected(stmt *sql.Stmt, args ...interface{}) error {
+	res, err := stmt.Exec(args...)
+	if err != nil {
+		return wrapError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrPostNotFound
+	}
+	return nil
+}
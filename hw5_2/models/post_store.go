@@ -0,0 +1,20 @@
+package models
+
+import "github.com/pkg/errors"
+
+// ErrPostNotFound is returned when no post matches the requested id
+var ErrPostNotFound = errors.New("Post not found")
+
+// PostStore is a storage backend for blog posts
+type PostStore interface {
+	// ListPosts returns all posts
+	ListPosts() ([]BlogPost, error)
+	// GetPost returns post by id
+	GetPost(id int) (BlogPost, error)
+	// CreatePost stores new post and sets its id
+	CreatePost(post *BlogPost) error
+	// UpdatePost updates stored post
+	UpdatePost(post *BlogPost) error
+	// DeletePost removes post by id
+	DeletePost(id int) error
+}
@@ -3,22 +3,39 @@ package routers
 import (
 	"database/sql"
 	"hw5_2/controllers"
+	"hw5_2/models"
 	"log"
 
 	"github.com/astaxie/beego"
 	// sql driver
 	_ "github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
 )
 
 func init() {
-	db, err := sql.Open("mysql", "root:root@/blog")
+	posts, err := newPostStore(beego.AppConfig.DefaultString("storeType", "mysql"))
 	if err != nil {
 		log.Fatal(err)
 		return
 	}
 
-	controller := &controllers.MainController{DB: db}
+	controller := &controllers.MainController{Posts: posts}
 	beego.Router("/", controller)
 	beego.Router("/post", controller, "get:ShowPost")
 	beego.Router("/edit", controller, "get:EditPost")
 }
+
+func newPostStore(storeType string) (models.PostStore, error) {
+	beego.Info("Starting db:", storeType)
+	switch storeType {
+	case "mysql":
+		db, err := sql.Open("mysql", beego.AppConfig.DefaultString("mysqlDsn", "root:root@/blog?clientFoundRows=true"))
+		if err != nil {
+			return nil, err
+		}
+		return models.NewPostRepository(db)
+	case "memory":
+		return models.NewMemoryPostStore(), nil
+	}
+	return nil, errors.Errorf("Unknown store type: %v", storeType)
+}
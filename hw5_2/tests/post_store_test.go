@@ -0,0 +1,183 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"hw5_2/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/astaxie/beego"
+	"github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+)
+
+// fakeDB answers post repository statements with canned results
+// so the repository is tested without mysql
+type fakeDB struct {
+	prepared []string
+	rows     [][]driver.Value
+	execErr  error
+	affected int64
+}
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) {
+	return db, nil
+}
+
+func (db *fakeDB) Driver() driver.Driver {
+	return nil
+}
+
+func (db *fakeDB) Prepare(query string) (driver.Stmt, error) {
+	db.prepared = append(db.prepared, query)
+	return &fakeStmt{db: db}, nil
+}
+
+func (db *fakeDB) Close() error {
+	return nil
+}
+
+func (db *fakeDB) Begin() (driver.Tx, error) {
+	return nil, errors.New("no transactions")
+}
+
+type fakeStmt struct {
+	db *fakeDB
+}
+
+func (st *fakeStmt) Close() error {
+	return nil
+}
+
+func (st *fakeStmt) NumInput() int {
+	return -1
+}
+
+func (st *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if st.db.execErr != nil {
+		return nil, st.db.execErr
+	}
+	return fakeResult{id: 7, affected: st.db.affected}, nil
+}
+
+func (st *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeRows{rows: st.db.rows}, nil
+}
+
+type fakeResult struct {
+	id, affected int64
+}
+
+func (r fakeResult) LastInsertId() (int64, error) {
+	return r.id, nil
+}
+
+func (r fakeResult) RowsAffected() (int64, error) {
+	return r.affected, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return []string{"id", "title", "postdate", "link", "content"}
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestPostRepository(t *testing.T) {
+	db := &fakeDB{}
+	repo, err := models.NewPostRepository(sql.OpenDB(db))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+	for _, q := range db.prepared {
+		if strings.Contains(q, "*") || (strings.Contains(q, "where") && !strings.Contains(q, "?")) {
+			t.Errorf("Statements should list columns and take parameters, got %v", q)
+		}
+	}
+
+	db.rows = [][]driver.Value{{int64(1), "Title1", "2020-02-21", "link1", "content1"}}
+	posts, err := repo.ListPosts()
+	if err != nil || len(posts) != 1 || posts[0].Title != "Title1" {
+		t.Errorf("Wrong posts %v %v", posts, err)
+	}
+	db.rows = [][]driver.Value{{int64(1), "Title1", "2020-02-21", "link1", "content1"}, {"bad id", "x", "x", "x", "x"}}
+	if posts, err := repo.ListPosts(); err == nil {
+		t.Errorf("Row that can not be read should fail the list, got %v", posts)
+	}
+
+	db.rows = nil
+	if _, err := repo.GetPost(1); err != models.ErrPostNotFound {
+		t.Errorf("Missing post should be ErrPostNotFound, got %v", err)
+	}
+
+	post := &models.BlogPost{Title: "New"}
+	if err := repo.CreatePost(post); err != nil || post.ID != 7 {
+		t.Errorf("Create should set inserted id, got %v %v", post.ID, err)
+	}
+	if err := repo.UpdatePost(post); err != models.ErrPostNotFound {
+		t.Errorf("Update of no rows should be ErrPostNotFound, got %v", err)
+	}
+	db.execErr = &mysql.MySQLError{Number: 1048, Message: "Column 'title' cannot be null"}
+	if _, ok := repo.CreatePost(post).(*models.ConstraintError); !ok {
+		t.Error("Constraint violation should be ConstraintError")
+	}
+}
+
+func TestMemoryPostStore(t *testing.T) {
+	store := models.NewMemoryPostStore()
+	first := &models.BlogPost{Title: "First"}
+	second := &models.BlogPost{Title: "Second"}
+	store.CreatePost(first)
+	store.CreatePost(second)
+	if posts, _ := store.ListPosts(); len(posts) != 2 || posts[0].ID != first.ID || first.ID == second.ID {
+		t.Errorf("Posts should get ids in creation order, got %v", posts)
+	}
+
+	second.Title = "Edited"
+	if err := store.UpdatePost(second); err != nil {
+		t.Fatal(err)
+	}
+	if p, err := store.GetPost(second.ID); err != nil || p.Title != "Edited" {
+		t.Errorf("Wrong updated post %v %v", p, err)
+	}
+	if err := store.DeletePost(first.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetPost(first.ID); err != models.ErrPostNotFound {
+		t.Errorf("Deleted post should be ErrPostNotFound, got %v", err)
+	}
+	if err := store.UpdatePost(first); err != models.ErrPostNotFound {
+		t.Errorf("Update of deleted post should be ErrPostNotFound, got %v", err)
+	}
+}
+
+func TestMissingPost(t *testing.T) {
+	for _, path := range []string{"/post?id=404", "/edit?id=404"} {
+		r, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		beego.BeeApp.Handlers.ServeHTTP(w, r)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%v should be 404, got %v", path, w.Code)
+		}
+	}
+}
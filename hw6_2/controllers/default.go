@@ -1,7 +1,6 @@
 package controllers
 
 import (
-	ctx "context"
 	"hw6/models"
 	"log"
 	"net/http"
@@ -9,15 +8,12 @@ import (
 
 	"github.com/astaxie/beego"
 	"github.com/pkg/errors"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // MainController controller
 type MainController struct {
 	beego.Controller
-	DB     *mongo.Client
-	DBName string
+	Store models.PostStore
 }
 
 // Get gets main page
@@ -123,55 +119,19 @@ func (c *MainController) NewPost() {
 }
 
 func (c *MainController) getAllPosts() ([]models.BlogPost, error) {
-	col := c.DB.Database(c.DBName).Collection("posts")
-
-	cur, err := col.Find(ctx.TODO(), bson.D{})
-	if err != nil {
-		return nil, err
-	}
-
-	posts := []models.BlogPost{}
-	err = cur.All(ctx.TODO(), &posts)
-
-	if err != nil {
-		return nil, err
-	}
-
-	return posts, nil
+	return c.Store.ListPosts()
 }
 
 func (c *MainController) getPostByID(postID string) (*models.BlogPost, error) {
-	col := c.DB.Database(c.DBName).Collection("posts")
-	filter := bson.M{"id": bson.M{"$eq": postID}}
-	res := col.FindOne(ctx.TODO(), filter)
-	post := &models.BlogPost{}
-	err := res.Decode(post)
-	if err != nil {
-		return nil, err
-	}
-	return post, nil
+	return c.Store.GetPost(postID)
 }
 
 func (c *MainController) addPost(post *models.BlogPost) error {
-	col := c.DB.Database(c.DBName).Collection("posts")
-	_, err := col.InsertOne(ctx.TODO(), post)
-	if err != nil {
-		return err
-	}
-	return nil
+	return c.Store.CreatePost(post)
 }
 
 func (c *MainController) updatePost(post *models.BlogPost) error {
-	col := c.DB.Database(c.DBName).Collection("posts")
-
-	filter := bson.M{"id": bson.M{"$eq": post.ID}}
-	update := bson.M{"$set": bson.M{"title": post.Title, "link": post.Link, "date": post.Date, "content": post.Content}}
-
-	_, err := col.UpdateOne(ctx.TODO(), filter, update)
-	if err != nil {
-		return err
-	}
-	return nil
+	return c.Store.UpdatePost(post)
 }
 
 func (c *MainController) createTestPost(wr http.ResponseWriter) {
@@ -1,7 +1,6 @@
 package controllers
 
 import (
-	ctx "context"
 	"hw7/models"
 	"log"
 	"net/http"
@@ -9,15 +8,12 @@ import (
 
 	"github.com/astaxie/beego"
 	"github.com/pkg/errors"
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // MainController controller
 type MainController struct {
 	beego.Controller
-	DB     *mongo.Client
-	DBName string
+	Store models.PostStore
 }
 
 // Get gets main page
@@ -130,58 +126,22 @@ func (c *MainController) NewPost() {
 
 // GetAllPosts gets all posts
 func (c *MainController) GetAllPosts() ([]models.BlogPost, error) {
-	col := c.DB.Database(c.DBName).Collection("posts")
-
-	cur, err := col.Find(ctx.TODO(), bson.D{})
-	if err != nil {
-		return nil, err
-	}
-
-	posts := []models.BlogPost{}
-	err = cur.All(ctx.TODO(), &posts)
-
-	if err != nil {
-		return nil, err
-	}
-
-	return posts, nil
+	return c.Store.ListPosts()
 }
 
 // GetPostByID gets post by id
 func (c *MainController) GetPostByID(postID string) (*models.BlogPost, error) {
-	col := c.DB.Database(c.DBName).Collection("posts")
-	filter := bson.M{"id": bson.M{"$eq": postID}}
-	res := col.FindOne(ctx.TODO(), filter)
-	post := &models.BlogPost{}
-	err := res.Decode(post)
-	if err != nil {
-		return nil, err
-	}
-	return post, nil
+	return c.Store.GetPost(postID)
 }
 
 // AddPost new post
 func (c *MainController) AddPost(post *models.BlogPost) error {
-	col := c.DB.Database(c.DBName).Collection("posts")
-	_, err := col.InsertOne(ctx.TODO(), post)
-	if err != nil {
-		return err
-	}
-	return nil
+	return c.Store.CreatePost(post)
 }
 
 // UpdatePost updates post
 func (c *MainController) UpdatePost(post *models.BlogPost) error {
-	col := c.DB.Database(c.DBName).Collection("posts")
-
-	filter := bson.M{"id": bson.M{"$eq": post.ID}}
-	update := bson.M{"$set": bson.M{"title": post.Title, "link": post.Link, "date": post.Date, "content": post.Content}}
-
-	_, err := col.UpdateOne(ctx.TODO(), filter, update)
-	if err != nil {
-		return err
-	}
-	return nil
+	return c.Store.UpdatePost(post)
 }
 
 // CreateTestPost creates new test post
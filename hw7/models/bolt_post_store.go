@@ -0,0 +1,74 @@
+package models
+
+import (
+	"common/boltstore"
+
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var postsBucket = []byte("posts")
+
+// BoltPostStore keeps posts in a single embedded bolt data file,
+// posts are bson documents like in mongo keyed by post id
+type BoltPostStore struct {
+	DB    *bbolt.DB
+	posts boltstore.Bucket
+}
+
+// NewBoltPostStore opens or creates data file at path
+func NewBoltPostStore(path string) (*BoltPostStore, error) {
+	db, err := boltstore.Open(path, postsBucket)
+	if err != nil {
+		return nil, err
+	}
+	return &BoltPostStore{DB: db, posts: boltstore.Bucket{DB: db, Name: postsBucket}}, nil
+}
+
+// Close closes data file
+func (s *BoltPostStore) Close() error {
+	return s.DB.Close()
+}
+
+// ListPosts gets all posts ordered by id
+func (s *BoltPostStore) ListPosts() ([]BlogPost, error) {
+	posts := []BlogPost{}
+	err := s.posts.All(func(doc []byte) error {
+		post := BlogPost{}
+		if err := bson.Unmarshal(doc, &post); err != nil {
+			return err
+		}
+		posts = append(posts, post)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// GetPost gets post by id
+func (s *BoltPostStore) GetPost(id string) (*BlogPost, error) {
+	post := &BlogPost{}
+	if err := s.posts.Get(id, post); err != nil {
+		return nil, boltError(err)
+	}
+	return post, nil
+}
+
+// CreatePost adds new post, its id must not be taken
+func (s *BoltPostStore) CreatePost(post *BlogPost) error {
+	return s.posts.Insert(post.ID, post)
+}
+
+// UpdatePost updates post
+func (s *BoltPostStore) UpdatePost(post *BlogPost) error {
+	return boltError(s.posts.Replace(post.ID, post))
+}
+
+func boltError(err error) error {
+	if err == boltstore.ErrNotFound {
+		return ErrPostNotFound
+	}
+	return err
+}
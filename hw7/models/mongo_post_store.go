@@ -0,0 +1,73 @@
+package models
+
+import (
+	ctx "context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoPostStore keeps posts in mongo posts collection
+type MongoPostStore struct {
+	DB     *mongo.Client
+	DBName string
+}
+
+// NewMongoPostStore creates store on database dbName
+func NewMongoPostStore(db *mongo.Client, dbName string) *MongoPostStore {
+	return &MongoPostStore{DB: db, DBName: dbName}
+}
+
+func (s *MongoPostStore) posts() *mongo.Collection {
+	return s.DB.Database(s.DBName).Collection("posts")
+}
+
+// ListPosts gets all posts
+func (s *MongoPostStore) ListPosts() ([]BlogPost, error) {
+	cur, err := s.posts().Find(ctx.TODO(), bson.D{})
+	if err != nil {
+		return nil, err
+	}
+
+	posts := []BlogPost{}
+	err = cur.All(ctx.TODO(), &posts)
+	if err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// GetPost gets post by id
+func (s *MongoPostStore) GetPost(id string) (*BlogPost, error) {
+	filter := bson.M{"id": bson.M{"$eq": id}}
+	post := &BlogPost{}
+	err := s.posts().FindOne(ctx.TODO(), filter).Decode(post)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrPostNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// CreatePost adds new post
+func (s *MongoPostStore) CreatePost(post *BlogPost) error {
+	_, err := s.posts().InsertOne(ctx.TODO(), post)
+	return err
+}
+
+// UpdatePost updates post
+func (s *MongoPostStore) UpdatePost(post *BlogPost) error {
+	filter := bson.M{"id": bson.M{"$eq": post.ID}}
+	update := bson.M{"$set": bson.M{"title": post.Title, "link": post.Link, "date": post.Date, "content": post.Content}}
+
+	res, err := s.posts().UpdateOne(ctx.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrPostNotFound
+	}
+	return nil
+}
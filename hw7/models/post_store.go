@@ -0,0 +1,18 @@
+package models
+
+import "github.com/pkg/errors"
+
+// ErrPostNotFound is returned when no post matches the requested id
+var ErrPostNotFound = errors.New("Post not found")
+
+// PostStore is a storage backend for blog posts
+type PostStore interface {
+	// ListPosts returns all posts
+	ListPosts() ([]BlogPost, error)
+	// GetPost returns post by id
+	GetPost(id string) (*BlogPost, error)
+	// CreatePost stores new post
+	CreatePost(post *BlogPost) error
+	// UpdatePost updates stored post
+	UpdatePost(post *BlogPost) error
+}
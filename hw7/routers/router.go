@@ -1,29 +1,47 @@
 package routers
 
 import (
+	"common/boltstore"
 	ctx "context"
 	"hw7/controllers"
+	"hw7/models"
 	"log"
 
 	"github.com/astaxie/beego"
+	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 func init() {
-	db, err := mongo.NewClient(options.Client().ApplyURI("mongodb://localhost:27017"))
+	store, err := newPostStore(beego.AppConfig.DefaultString("storeType", "bolt"))
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	err = db.Connect(ctx.TODO())
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	controller := &controllers.MainController{DB: db, DBName: "BlogData"}
+	controller := &controllers.MainController{Store: store}
 	beego.Router("/", controller)
 	beego.Router("/post", controller, "get:ShowPost")
 	beego.Router("/edit", controller, "get:EditPost")
 	beego.Router("/new", controller, "get:NewPost")
 }
+
+func newPostStore(storeType string) (models.PostStore, error) {
+	beego.Info("Starting db:", storeType)
+	switch storeType {
+	case "mongo":
+		db, err := mongo.NewClient(options.Client().ApplyURI(beego.AppConfig.DefaultString("dbUri", "mongodb://localhost:27017")))
+		if err != nil {
+			return nil, err
+		}
+
+		err = db.Connect(ctx.TODO())
+		if err != nil {
+			return nil, err
+		}
+		return models.NewMongoPostStore(db, beego.AppConfig.DefaultString("dbName", "BlogData")), nil
+	case "bolt":
+		return models.NewBoltPostStore(boltstore.DataPath(beego.AppConfig.DefaultString("boltPath", "data/blog.db")))
+	}
+	return nil, errors.Errorf("Unknown store type: %v", storeType)
+}
@@ -3,6 +3,7 @@ package tests
 import (
 	ctx "context"
 	"hw7/controllers"
+	"hw7/models"
 	_ "hw7/routers"
 	"log"
 	"net/http"
@@ -47,7 +48,7 @@ func TestPosts(t *testing.T) {
 		return
 	}
 
-	controller := &controllers.MainController{DB: db, DBName: "TestDb"}
+	controller := &controllers.MainController{Store: models.NewMongoPostStore(db, "TestDb")}
 	posts, err := controller.GetAllPosts()
 	if err != nil {
 		t.Error(err)
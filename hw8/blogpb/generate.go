@@ -0,0 +1,5 @@
+// Package blogpb has protobuf messages and gRPC service of the blog,
+// served by controllers.NewGRPCServer.
+package blogpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative blog.proto
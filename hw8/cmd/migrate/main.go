@@ -0,0 +1,116 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"hw8/migrations"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/astaxie/beego/config"
+	// sql driver
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+)
+
+const usage = `usage: migrate [-config app.conf | -dsn dsn] command
+
+commands:
+  up [-to version]   apply pending migrations
+  down [-steps n]    roll back applied migrations, one by default
+  status             print migrations state
+`
+
+func main() {
+	configPath := os.Getenv("BEEGO_CONFIG_PATH")
+	if configPath == "" {
+		configPath = filepath.Join("conf", "app.conf")
+	}
+	conf := flag.String("config", configPath, "app config with mysqlDsn, BEEGO_CONFIG_PATH by default")
+	dsn := flag.String("dsn", "", "mysql data source name, overrides config")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *dsn == "" {
+		var err error
+		if *dsn, err = configDsn(*conf); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	db, err := sql.Open("mysql", *dsn)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	migrator := migrations.NewMigrator(db)
+	cmd, args := flag.Arg(0), flag.Args()[1:]
+	switch cmd {
+	case "up":
+		fs := flag.NewFlagSet("up", flag.ExitOnError)
+		to := fs.Int("to", 0, "target version, all pending by default")
+		fs.Parse(args)
+		done, err := migrator.Up(*to)
+		printDone("applied", done)
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "down":
+		fs := flag.NewFlagSet("down", flag.ExitOnError)
+		steps := fs.Int("steps", 1, "number of migrations to roll back")
+		fs.Parse(args)
+		done, err := migrator.Down(*steps)
+		printDone("rolled back", done)
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "status":
+		status, err := migrator.Status()
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, s := range status {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%4d  %-30s %s  %s\n", s.Version, s.Name, s.Checksum()[:12], state)
+		}
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// configDsn reads mysqlDsn from app config at path, the path is taken
+// as given so the command does not depend on beego finding conf/ in
+// the working directory
+func configDsn(path string) (string, error) {
+	conf, err := config.NewConfig("ini", path)
+	if err != nil {
+		return "", errors.Wrapf(err, "Can not read config %v, pass -config or -dsn", path)
+	}
+	dsn := conf.String("mysqlDsn")
+	if dsn == "" {
+		return "", errors.Errorf("Config %v has no mysqlDsn, pass -dsn", path)
+	}
+	return dsn, nil
+}
+
+func printDone(action string, done []migrations.Migration) {
+	if len(done) == 0 {
+		fmt.Println("nothing to do")
+	}
+	for _, m := range done {
+		fmt.Printf("%v %d %s\n", action, m.Version, m.Name)
+	}
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"hw8/models"
+	"net/http"
+
+	"github.com/astaxie/beego"
+	"github.com/pkg/errors"
+)
+
+// ListUsers shows users with their roles
+func (c *MainController) ListUsers() {
+	beego.Info("ListUsers")
+
+	users, err := c.Store.ListUsers()
+	if err != nil {
+		err = errors.Wrap(err, "Can not load users")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusInternalServerError)
+		beego.Error(err)
+		return
+	}
+
+	c.Data["Title"] = "Users"
+	c.Data["Users"] = users
+	c.Data["Roles"] = models.Roles
+	c.TplName = "users.tpl"
+}
+
+// SetUserRole changes user role
+func (c *MainController) SetUserRole() {
+	beego.Info("SetUserRole")
+
+	req := c.Ctx.Request
+	userID, err := parseObjectID(req.FormValue("id"))
+	if err != nil {
+		err = errors.Wrap(err, "Can not parse user id")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusBadRequest)
+		beego.Error(err)
+		return
+	}
+
+	role := req.FormValue("role")
+	err = models.ChangeRole(c.Store, c.currentUser(), userID, role)
+	switch err {
+	case nil:
+	case models.ErrForbidden:
+		beego.Warn("Forbidden role change for user:", userID.Hex())
+		c.forbidden()
+		return
+	case models.ErrBadRole:
+		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusBadRequest)
+		return
+	case models.ErrUserNotFound:
+		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusNotFound)
+		return
+	default:
+		err = errors.Wrap(err, "Can not change role")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusInternalServerError)
+		beego.Error(err)
+		return
+	}
+
+	beego.Info("User", userID.Hex(), "is now", role)
+	c.Redirect("/admin/users", http.StatusSeeOther)
+}
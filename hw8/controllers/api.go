@@ -0,0 +1,388 @@
+package controllers
+
+import (
+	"encoding/json"
+	"hw8/models"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"github.com/pkg/errors"
+)
+
+const (
+	// apiPrefix starts paths answered with json
+	apiPrefix = "/api/"
+	// apiPostsPath is the posts collection of api version 1
+	apiPostsPath = "/api/v1/posts"
+	// apiMaxLimit caps posts per api page
+	apiMaxLimit = 100
+	// apiMaxBody caps size of api request body
+	apiMaxBody = 1 << 20
+)
+
+var (
+	errLoginRequired   = errors.New("Login required")
+	errVersionRequired = errors.New("Post version is required, send If-Match header or version field")
+	errValidation      = errors.New("Validation failed")
+)
+
+// apiPost is post as api sends it, doc tags describe fields in OpenAPI document
+type apiPost struct {
+	ID        string     `json:"id" doc:"Post id"`
+	Slug      string     `json:"slug" doc:"Unique permalink part made from title"`
+	URL       string     `json:"url" doc:"Absolute permalink of post page"`
+	Title     string     `json:"title"`
+	Date      time.Time  `json:"date" doc:"Date shown to readers"`
+	Link      string     `json:"link" doc:"Source address, http or https"`
+	Content   string     `json:"content" doc:"Markdown text"`
+	Tags      []string   `json:"tags" doc:"Normalized lower case tags"`
+	Category  string     `json:"category"`
+	Status    string     `json:"status"`
+	PublishAt *time.Time `json:"publish_at,omitempty" doc:"Publishing time of scheduled post"`
+	AuthorID  string     `json:"author_id,omitempty" doc:"Id of user who wrote post"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Version   int        `json:"version" doc:"Edit version, send it back on update"`
+}
+
+// apiPostInput is post sent to create or update. Update replaces text fields,
+// omitted date and status keep stored ones, new posts get now and draft.
+type apiPostInput struct {
+	Title     string     `json:"title"`
+	Date      *time.Time `json:"date" doc:"Date shown to readers, now for new posts"`
+	Link      string     `json:"link,omitempty" doc:"Source address, http or https"`
+	Content   string     `json:"content,omitempty" doc:"Markdown text"`
+	Tags      []string   `json:"tags,omitempty"`
+	Category  string     `json:"category,omitempty"`
+	Status    string     `json:"status,omitempty" doc:"Draft for new posts, stored status on update"`
+	PublishAt *time.Time `json:"publish_at" doc:"Publishing time, required for scheduled posts"`
+	Version   *int       `json:"version" doc:"Version being updated, or send If-Match header"`
+}
+
+// apiPostsPage is page of posts with cursors of neighbour pages
+type apiPostsPage struct {
+	Posts []apiPost `json:"posts"`
+	Next  string    `json:"next,omitempty" doc:"Cursor of older posts"`
+	Prev  string    `json:"prev,omitempty" doc:"Cursor of newer posts"`
+}
+
+// apiErrorBody is api error payload, Fields has validation messages by field
+type apiErrorBody struct {
+	Error  string            `json:"error"`
+	Fields map[string]string `json:"fields,omitempty" doc:"Validation messages by field"`
+}
+
+// apiCredentials is login request of api clients
+type apiCredentials struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+}
+
+// apiSession is session token to send as Authorization: Bearer header
+type apiSession struct {
+	Token     string    `json:"token" doc:"Send as Authorization: Bearer header"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// isAPI tells if path is answered with json
+func isAPI(path string) bool {
+	return strings.HasPrefix(path, apiPrefix) || path == graphqlPath
+}
+
+// writeAPIError sends json error with status
+func writeAPIError(ctx *context.Context, status int, message string, fields map[string]string) {
+	ctx.Output.SetStatus(status)
+	if err := ctx.Output.JSON(apiErrorBody{Error: message, Fields: fields}, false, false); err != nil {
+		beego.Error(err)
+	}
+}
+
+// newAPIPost converts post for api
+func newAPIPost(post *models.BlogPost) apiPost {
+	p := apiPost{
+		ID:        post.ID.Hex(),
+		Slug:      post.Slug,
+		URL:       baseURL() + postURL(post),
+		Title:     post.Title,
+		Date:      post.Date,
+		Link:      post.Link,
+		Content:   post.Content,
+		Tags:      post.Tags,
+		Category:  post.Category,
+		Status:    post.Status,
+		PublishAt: post.PublishAt,
+		CreatedAt: post.CreatedAt,
+		UpdatedAt: post.UpdatedAt,
+		Version:   post.Version,
+	}
+	if p.Tags == nil {
+		p.Tags = []string{}
+	}
+	if p.Status == "" {
+		p.Status = models.StatusPublished
+	}
+	if !post.AuthorID.IsZero() {
+		p.AuthorID = post.AuthorID.Hex()
+	}
+	return p
+}
+
+// apply validates input and copies it to post,
+// returned messages by field are empty when input is valid
+func (in *apiPostInput) apply(post *models.BlogPost) map[string]string {
+	fields := map[string]string{}
+	if strings.TrimSpace(in.Title) == "" {
+		fields["title"] = "is required"
+	}
+	if in.Link != "" {
+		u, err := url.Parse(in.Link)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fields["link"] = "must be http or https address"
+		}
+	}
+	if in.Status != "" {
+		switch post.SetStatus(in.Status, in.PublishAt) {
+		case models.ErrBadStatus:
+			fields["status"] = "must be one of " + strings.Join(models.Statuses, ", ")
+		case models.ErrNoPublishTime:
+			fields["publish_at"] = "is required for scheduled posts"
+		}
+	}
+
+	post.Title = strings.TrimSpace(in.Title)
+	post.Link = in.Link
+	post.Content = in.Content
+	post.Tags = models.ParseTags(strings.Join(in.Tags, ","))
+	post.Category = models.NormalizeTag(in.Category)
+	if in.Date != nil {
+		post.Date = *in.Date
+	}
+	return fields
+}
+
+// sendJSON sends value as json with status
+func (c *MainController) sendJSON(status int, v interface{}) {
+	c.Ctx.Output.SetStatus(status)
+	c.Data["json"] = v
+	c.ServeJSON()
+}
+
+// apiFail sends error as json, status comes from store error
+func (c *MainController) apiFail(err error, status int, msg string) {
+	err = errors.Wrap(err, msg)
+	beego.Error(err)
+	writeAPIError(c.Ctx, status, err.Error(), nil)
+}
+
+// readJSON decodes request body into v, unknown fields are errors
+func (c *MainController) readJSON(v interface{}) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(c.Ctx.ResponseWriter, c.Ctx.Request.Body, apiMaxBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		c.apiFail(err, http.StatusBadRequest, "Can not parse json body")
+		return false
+	}
+	return true
+}
+
+// apiPostParam loads post in path the user may see
+func (c *MainController) apiPostParam() (*models.BlogPost, bool) {
+	post, err := c.GetPostByID(c.Ctx.Input.Param(":id"))
+	if err == nil && !c.canSee(post) {
+		err = models.ErrPostNotFound
+	}
+	if err != nil {
+		c.apiFail(err, postErrorStatus(err), "No post found")
+		return nil, false
+	}
+	return post, true
+}
+
+// APIListPosts sends page of published posts, newest first
+func (c *MainController) APIListPosts() {
+	beego.Info("APIListPosts")
+
+	limit, err := c.GetInt("limit", beego.AppConfig.DefaultInt("pageSize", 10))
+	if err != nil || limit < 1 || limit > apiMaxLimit {
+		writeAPIError(c.Ctx, http.StatusBadRequest, errValidation.Error(),
+			map[string]string{"limit": "must be number from 1 to " + strconv.Itoa(apiMaxLimit)})
+		return
+	}
+	page, err := models.ListPage(c.Store, c.GetString("cursor"), limit)
+	if errors.Cause(err) == models.ErrBadCursor {
+		writeAPIError(c.Ctx, http.StatusBadRequest, errValidation.Error(), map[string]string{"cursor": err.Error()})
+		return
+	}
+	if err != nil {
+		c.apiFail(err, http.StatusInternalServerError, "Can not load posts")
+		return
+	}
+
+	result := apiPostsPage{Posts: []apiPost{}, Next: page.Next, Prev: page.Prev}
+	for i := range page.Posts {
+		result.Posts = append(result.Posts, newAPIPost(&page.Posts[i]))
+	}
+	c.sendJSON(http.StatusOK, result)
+}
+
+// APIGetPost sends post by id
+func (c *MainController) APIGetPost() {
+	beego.Info("APIGetPost")
+
+	post, ok := c.apiPostParam()
+	if !ok {
+		return
+	}
+	if c.setPostETag(post) {
+		c.Ctx.Output.SetStatus(http.StatusNotModified)
+		return
+	}
+	c.sendJSON(http.StatusOK, newAPIPost(post))
+}
+
+// APICreatePost creates post written by current user
+func (c *MainController) APICreatePost() {
+	beego.Info("APICreatePost")
+
+	in := &apiPostInput{}
+	if !c.readJSON(in) {
+		return
+	}
+	post := &models.BlogPost{Date: time.Now(), Status: models.StatusDraft}
+	if fields := in.apply(post); len(fields) > 0 {
+		writeAPIError(c.Ctx, http.StatusUnprocessableEntity, errValidation.Error(), fields)
+		return
+	}
+	if err := c.AddPost(post); err != nil {
+		c.apiFail(err, http.StatusInternalServerError, "Can not create post")
+		return
+	}
+
+	beego.Info("Created post:", post.Title)
+	c.Ctx.Output.Header("Location", apiPostsPath+"/"+post.ID.Hex())
+	c.Ctx.Output.Header("ETag", postETag(post))
+	c.sendJSON(http.StatusCreated, newAPIPost(post))
+}
+
+// APIUpdatePost replaces post, client sends version it has read
+// in If-Match header or version field
+func (c *MainController) APIUpdatePost() {
+	beego.Info("APIUpdatePost")
+
+	stored, ok := c.apiPostParam()
+	if !ok {
+		return
+	}
+	in := &apiPostInput{}
+	if !c.readJSON(in) {
+		return
+	}
+
+	post := *stored
+	// drafts get slug of their new title, see models.UpdatePostWithRevision
+	post.Slug = ""
+	fromHeader := c.Ctx.Input.Header("If-Match") != ""
+	switch {
+	case fromHeader:
+		version, _, err := c.expectedVersion(stored.ID)
+		if err != nil {
+			c.apiFail(err, http.StatusBadRequest, "Bad If-Match")
+			return
+		}
+		post.Version = version
+	case in.Version != nil:
+		post.Version = *in.Version
+	default:
+		writeAPIError(c.Ctx, http.StatusPreconditionRequired, errVersionRequired.Error(), nil)
+		return
+	}
+	if fields := in.apply(&post); len(fields) > 0 {
+		writeAPIError(c.Ctx, http.StatusUnprocessableEntity, errValidation.Error(), fields)
+		return
+	}
+
+	err := c.UpdateBlogPost(&post)
+	if errors.Cause(err) == models.ErrVersionConflict {
+		status := http.StatusConflict
+		if fromHeader {
+			status = http.StatusPreconditionFailed
+		}
+		beego.Warn("Edit conflict for post:", post.ID.Hex())
+		writeAPIError(c.Ctx, status, err.Error(), nil)
+		return
+	}
+	if err != nil {
+		c.apiFail(err, postErrorStatus(err), "Can not update post")
+		return
+	}
+
+	beego.Info("Updated post:", post.Title)
+	c.Ctx.Output.Header("ETag", postETag(&post))
+	c.sendJSON(http.StatusOK, newAPIPost(&post))
+}
+
+// APIDeletePost moves post to trash
+func (c *MainController) APIDeletePost() {
+	beego.Info("APIDeletePost")
+
+	post, ok := c.apiPostParam()
+	if !ok {
+		return
+	}
+	if err := c.Store.DeletePost(post.ID); err != nil {
+		c.apiFail(err, postErrorStatus(err), "Can not delete post")
+		return
+	}
+	c.unindexPost(post.ID)
+	c.queuePublishedEvent(models.EventPostDeleted, post)
+
+	beego.Info("Deleted post:", post.ID.Hex())
+	c.Ctx.Output.SetStatus(http.StatusNoContent)
+	c.Ctx.Output.Body(nil)
+}
+
+// APILogin starts session for api client and sends its token
+func (c *MainController) APILogin() {
+	beego.Info("APILogin")
+
+	in := &apiCredentials{}
+	if !c.readJSON(in) {
+		return
+	}
+	user, err := models.Login(c.Store, in.Name, in.Password)
+	if err == models.ErrBadCredentials {
+		beego.Warn("Failed api login for", in.Name, "from", c.Ctx.Input.IP())
+		writeAPIError(c.Ctx, http.StatusUnauthorized, err.Error(), nil)
+		return
+	}
+	var token string
+	if err == nil {
+		token, err = models.StartSession(c.Store, user.ID, sessionTTL())
+	}
+	if err != nil {
+		c.apiFail(err, http.StatusInternalServerError, "Can not log in")
+		return
+	}
+
+	beego.Info("Logged in over api:", user.Name)
+	c.sendJSON(http.StatusCreated, apiSession{Token: token, ExpiresAt: time.Now().Add(sessionTTL())})
+}
+
+// APILogout ends session of bearer token
+func (c *MainController) APILogout() {
+	beego.Info("APILogout")
+
+	if token := bearerToken(c.Ctx); token != "" {
+		if err := models.EndSession(c.Store, token); err != nil {
+			c.apiFail(err, http.StatusInternalServerError, "Can not log out")
+			return
+		}
+	}
+	c.Ctx.Output.SetStatus(http.StatusNoContent)
+	c.Ctx.Output.Body(nil)
+}
@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"hw8/datefmt"
+	"hw8/models"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/astaxie/beego"
+	"github.com/pkg/errors"
+)
+
+// sessionCookie keeps session token in browser
+const sessionCookie = "session"
+
+var errPasswordMismatch = errors.New("Passwords do not match")
+
+// Prepare takes user loaded by AuthFilter, or loads it when filter did not run,
+// and picks reader locale
+func (c *MainController) Prepare() {
+	user, ok := c.Ctx.Input.GetData(userDataKey).(*models.User)
+	if !ok {
+		user = sessionUser(c.Store, c.Ctx)
+	}
+	c.Data["User"] = user
+	c.Data["Locale"] = datefmt.Locale(c.Ctx.Input.Header("Accept-Language"))
+	c.Data["CSRFToken"] = ""
+	if user != nil {
+		c.Data["CSRFToken"] = sessionCSRFToken(c.Ctx)
+	} else if !isAPI(c.Ctx.Request.URL.Path) {
+		token, err := preSessionCSRFToken(c.Ctx)
+		if err != nil {
+			beego.Error(errors.Wrap(err, "Can not start pre-session"))
+		}
+		c.Data["CSRFToken"] = token
+	}
+}
+
+// currentUser is logged in user or nil
+func (c *MainController) currentUser() *models.User {
+	user, _ := c.Data["User"].(*models.User)
+	return user
+}
+
+// forbidden sends 403 page
+func (c *MainController) forbidden() {
+	c.Data["Title"] = "Forbidden"
+	c.TplName = "forbidden.tpl"
+	c.renderStatus(http.StatusForbidden)
+}
+
+// safeNext keeps only local redirect paths so login can not send users away
+func safeNext(next string) string {
+	if i := strings.Index(next, "://"); i >= 0 {
+		// referer is absolute, keep its path
+		rest := next[i+3:]
+		j := strings.Index(rest, "/")
+		if j < 0 {
+			return "/"
+		}
+		next = rest[j:]
+	}
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
+
+// sessionTTL is login session lifetime from config
+func sessionTTL() time.Duration {
+	return time.Duration(beego.AppConfig.DefaultInt("sessionHours", 14*24)) * time.Hour
+}
+
+// startSession logs user in with new session cookie
+func (c *MainController) startSession(user *models.User) error {
+	ttl := sessionTTL()
+	token, err := models.StartSession(c.Store, user.ID, ttl)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(c.Ctx.ResponseWriter, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(ttl.Seconds()),
+		HttpOnly: true,
+		Secure:   beego.AppConfig.DefaultBool("sessionSecure", true),
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+// ShowLogin shows login form
+func (c *MainController) ShowLogin() {
+	c.Data["Title"] = "Login"
+	c.Data["Next"] = safeNext(c.GetString("next"))
+	c.TplName = "login.tpl"
+}
+
+// Login checks password and starts session
+func (c *MainController) Login() {
+	beego.Info("Login")
+
+	req := c.Ctx.Request
+	name := req.FormValue("name")
+	next := safeNext(req.FormValue("next"))
+	user, err := models.Login(c.Store, name, req.FormValue("password"))
+	if err == models.ErrBadCredentials {
+		beego.Warn("Failed login for", name, "from", c.Ctx.Input.IP())
+		c.Data["Title"] = "Login"
+		c.Data["Name"] = name
+		c.Data["Next"] = next
+		c.Data["Error"] = err.Error()
+		c.TplName = "login.tpl"
+		c.renderStatus(http.StatusUnauthorized)
+		return
+	}
+	if err == nil {
+		err = c.startSession(user)
+	}
+	if err != nil {
+		err = errors.Wrap(err, "Can not log in")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusInternalServerError)
+		beego.Error(err)
+		return
+	}
+
+	beego.Info("Logged in:", user.Name)
+	c.Redirect(next, http.StatusSeeOther)
+}
+
+// ShowRegister shows registration form
+func (c *MainController) ShowRegister() {
+	c.Data["Title"] = "Register"
+	c.TplName = "register.tpl"
+}
+
+// Register creates user and logs it in
+func (c *MainController) Register() {
+	beego.Info("Register")
+
+	req := c.Ctx.Request
+	name := strings.TrimSpace(req.FormValue("name"))
+	password := req.FormValue("password")
+	var user *models.User
+	err := errPasswordMismatch
+	if password == req.FormValue("confirm") {
+		user, err = models.Register(c.Store, name, password)
+	}
+	switch err {
+	case errPasswordMismatch, models.ErrBadUserName, models.ErrWeakPassword, models.ErrUserExists:
+		c.Data["Title"] = "Register"
+		c.Data["Name"] = name
+		c.Data["Error"] = err.Error()
+		c.TplName = "register.tpl"
+		c.renderStatus(http.StatusBadRequest)
+		return
+	}
+	if err == nil {
+		err = c.startSession(user)
+	}
+	if err != nil {
+		err = errors.Wrap(err, "Can not register")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusInternalServerError)
+		beego.Error(err)
+		return
+	}
+
+	beego.Info("Registered:", user.Name)
+	c.Redirect("/", http.StatusSeeOther)
+}
+
+// Logout ends session
+func (c *MainController) Logout() {
+	beego.Info("Logout")
+
+	if cookie, err := c.Ctx.Request.Cookie(sessionCookie); err == nil {
+		if err := models.EndSession(c.Store, cookie.Value); err != nil {
+			beego.Error(errors.Wrap(err, "Can not end session"))
+		}
+	}
+	http.SetCookie(c.Ctx.ResponseWriter, &http.Cookie{Name: sessionCookie, Path: "/", MaxAge: -1})
+	c.Redirect("/", http.StatusSeeOther)
+}
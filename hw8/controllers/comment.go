@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"hw8/models"
+	"net/http"
+
+	"github.com/astaxie/beego"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// showPost shows post page with its comments thread
+func (c *MainController) showPost(post *models.BlogPost) {
+	comments, err := c.Store.ListComments(post.ID)
+	if err != nil {
+		err = errors.Wrap(err, "Can not load comments")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusInternalServerError)
+		beego.Error(err)
+		return
+	}
+
+	c.Data["Title"] = post.Title
+	c.Data["Post"] = post
+	c.Data["Comments"] = models.Thread(comments, beego.AppConfig.DefaultInt("commentDepth", 3))
+	c.TplName = "post.tpl"
+}
+
+// AddComment posts comment or reply to post
+func (c *MainController) AddComment() {
+	beego.Info("AddComment")
+
+	req := c.Ctx.Request
+	postID, err := parseObjectID(req.FormValue("id"))
+	if err != nil {
+		err = errors.Wrap(err, "Can not parse post id")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusBadRequest)
+		beego.Error(err)
+		return
+	}
+
+	comment := &models.Comment{
+		PostID: postID,
+		Author: req.FormValue("author"),
+		Text:   req.FormValue("text"),
+	}
+	if parent := req.FormValue("parent"); parent != "" {
+		comment.ParentID, err = parseObjectID(parent)
+		if err != nil {
+			err = errors.Wrap(err, "Can not parse parent comment id")
+			http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusBadRequest)
+			beego.Error(err)
+			return
+		}
+	}
+
+	err = models.PostComment(c.Store, comment, c.currentUser())
+	if err != nil {
+		status := postErrorStatus(err)
+		switch err {
+		case models.ErrBadComment, models.ErrCommentNotFound, models.ErrCommentAuthorTaken:
+			status = http.StatusBadRequest
+		}
+		err = errors.Wrap(err, "Can not add comment")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), status)
+		beego.Error(err)
+		return
+	}
+
+	beego.Info("Comment added to post:", postID.Hex())
+	target := "/post?id=" + postID.Hex()
+	if post, err := c.Store.GetPost(postID); err == nil {
+		target = postURL(post)
+	}
+	c.Redirect(target+"#comment-"+comment.ID.Hex(), http.StatusFound)
+}
+
+// commentCounts counts comments of posts by post id hex,
+// errors only hide the counts
+func (c *MainController) commentCounts(posts []models.BlogPost) map[string]int {
+	ids := make([]primitive.ObjectID, 0, len(posts))
+	for _, p := range posts {
+		ids = append(ids, p.ID)
+	}
+	counts, err := c.Store.CountComments(ids)
+	if err != nil {
+		beego.Error(errors.Wrap(err, "Can not count comments"))
+		return nil
+	}
+
+	byHex := make(map[string]int, len(counts))
+	for id, n := range counts {
+		byHex[id.Hex()] = n
+	}
+	return byHex
+}
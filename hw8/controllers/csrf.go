@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"html/template"
+	"hw8/models"
+	"net/http"
+
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"github.com/pkg/errors"
+)
+
+const (
+	// csrfField is form field with CSRF token
+	csrfField = "csrf_token"
+	// csrfHeader carries CSRF token for requests sent by scripts
+	csrfHeader = "X-CSRF-Token"
+	// preSessionCookie keeps random token of anonymous visitor,
+	// their forms like login and register carry CSRF token of it
+	preSessionCookie = "presession"
+)
+
+var errBadCSRF = errors.New("Form is outdated or was sent from another site, reload the page and try again")
+
+func init() {
+	beego.AddFuncMap("csrfField", csrfInput)
+}
+
+// csrfInput is template helper for hidden token field,
+// use it in every post form as {{csrfField $.CSRFToken}}
+func csrfInput(token string) template.HTML {
+	if token == "" {
+		return ""
+	}
+	return template.HTML(`<input type="hidden" name="` + csrfField + `" value="` + template.HTMLEscapeString(token) + `">`)
+}
+
+// sessionCSRFToken is CSRF token of request session or empty string
+func sessionCSRFToken(ctx *context.Context) string {
+	return models.CSRFToken(ctx.GetCookie(sessionCookie))
+}
+
+// preSessionCSRFToken is CSRF token of anonymous visitor,
+// the pre-session cookie is issued when visitor has none
+func preSessionCSRFToken(ctx *context.Context) (string, error) {
+	token := ctx.GetCookie(preSessionCookie)
+	if token == "" {
+		var err error
+		if token, err = models.NewPreSessionToken(); err != nil {
+			return "", err
+		}
+		http.SetCookie(ctx.ResponseWriter, &http.Cookie{
+			Name:     preSessionCookie,
+			Value:    token,
+			Path:     "/",
+			HttpOnly: true,
+			Secure:   beego.AppConfig.DefaultBool("sessionSecure", true),
+			SameSite: http.SameSiteLaxMode,
+		})
+	}
+	return models.CSRFToken(token), nil
+}
+
+// checkCSRF tells if state changing request carries token of its
+// session, or of its pre-session cookie for anonymous visitors,
+// so login and register forms can not be sent from another site.
+// Safe methods always pass. Requests with bearer token pass too,
+// browsers never add it on their own, and so do anonymous api calls,
+// they have no cookie to ride on and get no cookie back.
+func checkCSRF(ctx *context.Context, user *models.User, api bool) bool {
+	switch ctx.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	if bearerToken(ctx) != "" || (api && user == nil) {
+		return true
+	}
+	token := ctx.Request.Header.Get(csrfHeader)
+	if token == "" {
+		token = ctx.Request.FormValue(csrfField)
+	}
+	secret := ctx.GetCookie(sessionCookie)
+	if user == nil {
+		secret = ctx.GetCookie(preSessionCookie)
+	}
+	return models.CheckCSRF(secret, token)
+}
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"hw8/datefmt"
+	"hw8/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/astaxie/beego"
+	"github.com/pkg/errors"
+)
+
+// dates shows times in timezone from config
+var dates = newDateFormatter()
+
+func newDateFormatter() *datefmt.Formatter {
+	f, err := datefmt.NewFormatter(beego.AppConfig.DefaultString("timezone", "Local"))
+	if err != nil {
+		beego.Error("Unknown timezone, using server one:", err)
+		f = &datefmt.Formatter{Zone: time.Local}
+	}
+	return f
+}
+
+func init() {
+	beego.AddFuncMap("formatDate", dates.Date)
+	beego.AddFuncMap("formatDateTime", dates.DateTime)
+	beego.AddFuncMap("inputDate", func(t time.Time) string {
+		return dates.Input(t, models.DateLayout)
+	})
+	beego.AddFuncMap("inputDateTime", func(t time.Time) string {
+		return dates.Input(t, publishAtLayout)
+	})
+	beego.AddFuncMap("archiveURL", archiveURL)
+}
+
+// archiveURL is link to archive month of t
+func archiveURL(t time.Time) string {
+	t = t.In(dates.Zone)
+	return "/archive/" + strconv.Itoa(t.Year()) + "/" + strconv.Itoa(int(t.Month()))
+}
+
+// parsePostDate reads form date in configured timezone, empty date is now
+func parsePostDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Now(), nil
+	}
+	return models.ParseDate(value, dates.Zone)
+}
+
+// ListArchive shows posts dated in a month
+func (c *MainController) ListArchive() {
+	beego.Info("ListArchive")
+
+	year, _ := strconv.Atoi(c.Ctx.Input.Param(":year"))
+	month, _ := strconv.Atoi(c.Ctx.Input.Param(":month"))
+	if year < 1 || month < 1 || month > 12 {
+		err := errors.Errorf("No such month: %v-%v", year, month)
+		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusNotFound)
+		beego.Error(err)
+		return
+	}
+
+	from := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, dates.Zone)
+	posts, err := c.Store.ListPostsBetween(from, from.AddDate(0, 1, 0))
+	c.showPostList(dates.Month(year, time.Month(month), c.locale()), posts, err)
+}
+
+// locale is reader locale for dates
+func (c *MainController) locale() string {
+	locale, _ := c.Data["Locale"].(string)
+	return locale
+}
@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"fmt"
+	"hash/fnv"
+	"hw8/feed"
+	"hw8/models"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/astaxie/beego"
+	"github.com/pkg/errors"
+)
+
+// feedSummaryWords is the length of post text in summary feeds
+const feedSummaryWords = 50
+
+// feedFormat is a feed document type
+type feedFormat struct {
+	file        string
+	contentType string
+	write       func(f *feed.Feed) ([]byte, error)
+}
+
+var (
+	rssFormat  = feedFormat{"feed.xml", "application/rss+xml; charset=utf-8", (*feed.Feed).RSS}
+	atomFormat = feedFormat{"atom.xml", "application/atom+xml; charset=utf-8", (*feed.Feed).Atom}
+)
+
+// ShowRSS sends RSS feed of latest posts
+func (c *MainController) ShowRSS() {
+	c.serveFeed(rssFormat, "")
+}
+
+// ShowAtom sends Atom feed of latest posts
+func (c *MainController) ShowAtom() {
+	c.serveFeed(atomFormat, "")
+}
+
+// ShowTagRSS sends RSS feed of latest posts having tag
+func (c *MainController) ShowTagRSS() {
+	c.serveFeed(rssFormat, models.NormalizeTag(c.Ctx.Input.Param(":name")))
+}
+
+// ShowTagAtom sends Atom feed of latest posts having tag
+func (c *MainController) ShowTagAtom() {
+	c.serveFeed(atomFormat, models.NormalizeTag(c.Ctx.Input.Param(":name")))
+}
+
+// serveFeed sends feed of latest published posts, of all posts or with tag,
+// clients polling with validators of unchanged feed get 304.
+// Only the feed page is loaded, so polls stay cheap.
+func (c *MainController) serveFeed(format feedFormat, tag string) {
+	beego.Info("Feed", format.file, tag)
+
+	filter := models.PostFilter{Status: models.StatusPublished, Tag: tag}
+	posts, err := c.Store.ListPostsPage(filter, models.Cursor{}, beego.AppConfig.DefaultInt("feedSize", 20))
+	if err != nil {
+		err = errors.Wrap(err, "Can not load posts")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusInternalServerError)
+		beego.Error(err)
+		return
+	}
+
+	if c.feedNotModified(posts) {
+		c.Ctx.Output.SetStatus(http.StatusNotModified)
+		return
+	}
+
+	data, err := format.write(newFeed(format, tag, posts))
+	if err != nil {
+		err = errors.Wrap(err, "Can not write feed")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusInternalServerError)
+		beego.Error(err)
+		return
+	}
+	c.Ctx.Output.Header("Content-Type", format.contentType)
+	c.Ctx.Output.Body(data)
+}
+
+// baseURL is absolute blog address from config for links leaving the site
+func baseURL() string {
+	return strings.TrimSuffix(beego.AppConfig.DefaultString("baseURL", "http://localhost:8080"), "/")
+}
+
+// newFeed makes feed of posts, content is full html
+// or text summary by feedContent config
+func newFeed(format feedFormat, tag string, posts []models.BlogPost) *feed.Feed {
+	base := baseURL()
+	title := beego.AppConfig.DefaultString("feedTitle", "Blog")
+	f := &feed.Feed{
+		Title:       title,
+		Link:        base + "/",
+		Self:        base + "/" + format.file,
+		Description: title,
+		Author:      title,
+		Updated:     feedUpdated(posts),
+	}
+	if tag != "" {
+		f.Title = title + ": " + tag
+		f.Link = base + "/tag/" + tag
+		f.Self = base + "/tag/" + tag + "/" + format.file
+		f.Description = f.Title
+	}
+
+	summary := beego.AppConfig.DefaultString("feedContent", "full") == "summary"
+	for i := range posts {
+		p := &posts[i]
+		item := feed.Item{
+			// id link never changes and redirects to permalink
+			ID:        base + "/post?id=" + p.ID.Hex(),
+			Title:     p.Title,
+			Link:      base + postURL(p),
+			Published: p.Date,
+			Updated:   p.UpdatedAt,
+		}
+		if p.Category != "" {
+			item.Categories = append(item.Categories, p.Category)
+		}
+		item.Categories = append(item.Categories, p.Tags...)
+		if summary {
+			item.Summary = contentRenderer.Summary(p.Content, feedSummaryWords)
+		} else {
+			item.Content = string(contentRenderer.Render(p.Content))
+		}
+		f.Items = append(f.Items, item)
+	}
+	return f
+}
+
+// feedUpdated is the latest change of feed posts
+func feedUpdated(posts []models.BlogPost) time.Time {
+	updated := time.Time{}
+	for _, p := range posts {
+		if p.UpdatedAt.After(updated) {
+			updated = p.UpdatedAt
+		}
+	}
+	return updated
+}
+
+// feedNotModified sends feed validators and tells if client has current feed.
+// ETag covers post ids and versions, so removed posts change it too;
+// If-None-Match wins over If-Modified-Since when both are sent.
+func (c *MainController) feedNotModified(posts []models.BlogPost) bool {
+	h := fnv.New64a()
+	for _, p := range posts {
+		fmt.Fprintf(h, "%v.%v.%v;", p.ID.Hex(), p.Version, p.UpdatedAt.UnixNano())
+	}
+	etag := fmt.Sprintf(`"%x"`, h.Sum64())
+	c.Ctx.Output.Header("ETag", etag)
+
+	updated := feedUpdated(posts)
+	if !updated.IsZero() {
+		c.Ctx.Output.Header("Last-Modified", updated.UTC().Format(http.TimeFormat))
+	}
+
+	if match := c.Ctx.Input.Header("If-None-Match"); match != "" {
+		return match == etag || match == "*"
+	}
+	since, err := http.ParseTime(c.Ctx.Input.Header("If-Modified-Since"))
+	return err == nil && !updated.IsZero() && !updated.Truncate(time.Second).After(since)
+}
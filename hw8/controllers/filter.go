@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"bytes"
+	"hw8/models"
+	"net/http"
+	"strings"
+
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"github.com/pkg/errors"
+)
+
+// userDataKey keeps user loaded by AuthFilter for the controller
+const userDataKey = "user"
+
+// accessRule is what user needs to run an action,
+// ownPost actions are also allowed to authors of the post in id parameter
+type accessRule struct {
+	role    string
+	ownPost bool
+}
+
+// accessRules maps "METHOD /path" to its rule, other routes are public.
+// Api paths of one post are written with :id, see routeOf.
+var accessRules = map[string]accessRule{
+	"POST /new":                  {role: models.RoleAuthor},
+	"POST /preview":              {role: models.RoleAuthor},
+	"GET /drafts":                {role: models.RoleAuthor},
+	"GET /edit":                  {role: models.RoleEditor, ownPost: true},
+	"POST /edit":                 {role: models.RoleEditor, ownPost: true},
+	"POST /delete":               {role: models.RoleEditor, ownPost: true},
+	"POST /post/history/restore": {role: models.RoleEditor, ownPost: true},
+	"GET /trash":                 {role: models.RoleEditor},
+	"POST /trash/restore":        {role: models.RoleEditor},
+	"POST /trash/purge":          {role: models.RoleEditor},
+	"GET /admin/users":           {role: models.RoleAdmin},
+	"POST /admin/users/role":     {role: models.RoleAdmin},
+
+	"GET /admin/webhooks":            {role: models.RoleAdmin},
+	"POST /admin/webhooks":           {role: models.RoleAdmin},
+	"POST /admin/webhooks/delete":    {role: models.RoleAdmin},
+	"GET /admin/webhooks/deliveries": {role: models.RoleAdmin},
+	"POST /admin/webhooks/redeliver": {role: models.RoleAdmin},
+
+	"POST " + apiPostsPath:            {role: models.RoleAuthor},
+	"PUT " + apiPostsPath + "/:id":    {role: models.RoleEditor, ownPost: true},
+	"DELETE " + apiPostsPath + "/:id": {role: models.RoleEditor, ownPost: true},
+}
+
+// routeOf is accessRules path of request path and post id in it, if any
+func routeOf(path string) (route string, id string) {
+	if strings.HasPrefix(path, apiPostsPath+"/") {
+		return apiPostsPath + "/:id", strings.TrimPrefix(path, apiPostsPath+"/")
+	}
+	return path, ""
+}
+
+// AuthFilter loads session user and checks access rules before routing.
+// Anonymous users are sent to login, others get 403 page,
+// api clients get json errors instead.
+func AuthFilter(store models.Store) beego.FilterFunc {
+	return func(ctx *context.Context) {
+		user := sessionUser(store, ctx)
+		ctx.Input.SetData(userDataKey, user)
+
+		path := ctx.Request.URL.Path
+		if path != "/" {
+			path = strings.TrimSuffix(path, "/")
+		}
+		api := isAPI(path)
+		route, id := routeOf(path)
+		rule, ok := accessRules[ctx.Request.Method+" "+route]
+
+		if ok && user == nil {
+			next := ctx.Request.URL.RequestURI()
+			if ctx.Request.Method != http.MethodGet {
+				next = ctx.Request.Referer()
+			}
+			beego.Warn("Login required for", path)
+			if api {
+				writeAPIError(ctx, http.StatusUnauthorized, errLoginRequired.Error(), nil)
+				return
+			}
+			ctx.Redirect(http.StatusSeeOther, "/login?next="+safeNext(next))
+			return
+		}
+
+		if !checkCSRF(ctx, user, api) {
+			name := "anonymous"
+			if user != nil {
+				name = user.Name
+			}
+			beego.Warn("CSRF check failed for", ctx.Request.Method, path, "by", name, "from", ctx.Input.IP())
+			if api {
+				writeAPIError(ctx, http.StatusForbidden, errBadCSRF.Error(), nil)
+				return
+			}
+			renderForbidden(ctx, user, errBadCSRF.Error())
+			return
+		}
+		if !ok {
+			return
+		}
+		if rule.ownPost && id == "" {
+			id = ctx.Request.FormValue("id")
+		}
+
+		if user.HasRole(rule.role) || (rule.ownPost && ownsPost(store, user, id)) {
+			return
+		}
+		beego.Warn("Forbidden", ctx.Request.Method, path, "for", user.Name)
+		if api {
+			writeAPIError(ctx, http.StatusForbidden, models.ErrForbidden.Error(), nil)
+			return
+		}
+		renderForbidden(ctx, user, "")
+	}
+}
+
+// bearerToken is session token sent by scripts in Authorization header
+func bearerToken(ctx *context.Context) string {
+	auth := ctx.Input.Header("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(auth, "Bearer "))
+}
+
+// sessionUser finds user of request bearer token or session cookie
+func sessionUser(store models.Store, ctx *context.Context) *models.User {
+	token := bearerToken(ctx)
+	if token == "" {
+		cookie, err := ctx.Request.Cookie(sessionCookie)
+		if err != nil {
+			return nil
+		}
+		token = cookie.Value
+	}
+	user, err := models.SessionUser(store, token)
+	if err != nil {
+		if err != models.ErrSessionNotFound {
+			beego.Error(errors.Wrap(err, "Can not load session"))
+		}
+		return nil
+	}
+	return user
+}
+
+// ownsPost tells if user wrote post with id, bad ids and missing posts
+// are denied; editors pass by role and get 404 from the action
+func ownsPost(store models.Store, user *models.User, postID string) bool {
+	id, err := parseObjectID(postID)
+	if err != nil {
+		return false
+	}
+	post, err := store.GetPost(id)
+	if err != nil {
+		if errors.Cause(err) != models.ErrPostNotFound {
+			beego.Error(errors.Wrap(err, "Can not load post to check owner"))
+		}
+		return false
+	}
+	return user.CanEditPost(post)
+}
+
+// renderForbidden sends 403 page, message explains why when it is not about role
+func renderForbidden(ctx *context.Context, user *models.User, message string) {
+	data := map[interface{}]interface{}{"Title": "Forbidden", "User": user, "Message": message}
+	buf := &bytes.Buffer{}
+	if err := beego.ExecuteTemplate(buf, "forbidden.tpl", data); err != nil {
+		beego.Error(err)
+		http.Error(ctx.ResponseWriter, models.ErrForbidden.Error(), http.StatusForbidden)
+		return
+	}
+	ctx.Output.SetStatus(http.StatusForbidden)
+	ctx.Output.Body(buf.Bytes())
+}
@@ -0,0 +1,617 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"hw8/models"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/astaxie/beego"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/pkg/errors"
+)
+
+// graphqlPath is GraphQL endpoint, queries may use GET, mutations need POST
+const graphqlPath = "/graphql"
+
+var (
+	errNoOperation    = errors.New("No such operation in query")
+	errMutationMethod = errors.New("Mutations must be sent with POST")
+)
+
+// graphSchema is the blog GraphQL schema, see newGraphSchema
+var graphSchema graphql.Schema
+
+func init() {
+	schema, err := newGraphSchema()
+	if err != nil {
+		panic(err)
+	}
+	graphSchema = schema
+}
+
+// graphQuery is GraphQL request of a client
+type graphQuery struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// graphContextKey keeps graphRequest in resolver context
+type graphContextKey struct{}
+
+// graphRequest is state resolvers of one request share
+type graphRequest struct {
+	c      *MainController
+	loader *graphLoader
+}
+
+func graphRequestOf(p graphql.ResolveParams) *graphRequest {
+	return p.Context.Value(graphContextKey{}).(*graphRequest)
+}
+
+// graphValidationError has messages by input field like api validation errors
+type graphValidationError struct {
+	fields map[string]string
+}
+
+func (e graphValidationError) Error() string {
+	names := make([]string, 0, len(e.fields))
+	for name := range e.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	messages := []string{}
+	for _, name := range names {
+		messages = append(messages, name+" "+e.fields[name])
+	}
+	return errValidation.Error() + ": " + strings.Join(messages, ", ")
+}
+
+// Extensions sends fields with the error
+func (e graphValidationError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"fields": e.fields}
+}
+
+// graphListFields are fields returning lists, see graphCost.listSize
+var graphListFields = map[string]bool{"posts": true, "tags": true, "comments": true}
+
+// postPointers lets resolvers of list items share post values
+func postPointers(posts []models.BlogPost) []*models.BlogPost {
+	list := make([]*models.BlogPost, len(posts))
+	for i := range posts {
+		list[i] = &posts[i]
+	}
+	return list
+}
+
+// postField resolves Post field from post
+func postField(get func(p *models.BlogPost) interface{}) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		return get(p.Source.(*models.BlogPost)), nil
+	}
+}
+
+// newGraphSchema builds schema of posts, their authors, comments and tags
+func newGraphSchema() (graphql.Schema, error) {
+	statusValues := graphql.EnumValueConfigMap{}
+	for _, s := range models.Statuses {
+		statusValues[strings.ToUpper(s)] = &graphql.EnumValueConfig{Value: s}
+	}
+	statusEnum := graphql.NewEnum(graphql.EnumConfig{Name: "PostStatus", Values: statusValues})
+
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "User",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*models.User).ID.Hex(), nil
+			}},
+			"name": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*models.User).Name, nil
+			}},
+			"role": &graphql.Field{Type: graphql.String, Description: "Role, null unless caller is admin", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if !graphRequestOf(p).c.currentUser().HasRole(models.RoleAdmin) {
+					return nil, nil
+				}
+				return p.Source.(*models.User).Role, nil
+			}},
+		},
+	})
+
+	commentField := func(t graphql.Output, get func(c models.Comment) interface{}) *graphql.Field {
+		return &graphql.Field{Type: t, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return get(p.Source.(models.Comment)), nil
+		}}
+	}
+	commentType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Comment",
+		Fields: graphql.Fields{
+			"id": commentField(graphql.NewNonNull(graphql.ID), func(c models.Comment) interface{} { return c.ID.Hex() }),
+			"parentId": commentField(graphql.ID, func(c models.Comment) interface{} {
+				if !c.IsReply() {
+					return nil
+				}
+				return c.ParentID.Hex()
+			}),
+			"author":    commentField(graphql.NewNonNull(graphql.String), func(c models.Comment) interface{} { return c.Author }),
+			"text":      commentField(graphql.NewNonNull(graphql.String), func(c models.Comment) interface{} { return c.Text }),
+			"createdAt": commentField(graphql.NewNonNull(graphql.DateTime), func(c models.Comment) interface{} { return c.CreatedAt }),
+		},
+	})
+
+	str := graphql.NewNonNull(graphql.String)
+	date := graphql.NewNonNull(graphql.DateTime)
+	postType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Post",
+		Description: "Blog post, see models.BlogPost",
+		Fields: graphql.Fields{
+			"id":       &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: postField(func(p *models.BlogPost) interface{} { return p.ID.Hex() })},
+			"slug":     &graphql.Field{Type: str, Resolve: postField(func(p *models.BlogPost) interface{} { return p.Slug })},
+			"url":      &graphql.Field{Type: str, Resolve: postField(func(p *models.BlogPost) interface{} { return baseURL() + postURL(p) })},
+			"title":    &graphql.Field{Type: str, Resolve: postField(func(p *models.BlogPost) interface{} { return p.Title })},
+			"date":     &graphql.Field{Type: date, Resolve: postField(func(p *models.BlogPost) interface{} { return p.Date })},
+			"link":     &graphql.Field{Type: str, Resolve: postField(func(p *models.BlogPost) interface{} { return p.Link })},
+			"content":  &graphql.Field{Type: str, Description: "Markdown text", Resolve: postField(func(p *models.BlogPost) interface{} { return p.Content })},
+			"html":     &graphql.Field{Type: str, Resolve: postField(func(p *models.BlogPost) interface{} { return string(contentRenderer.Render(p.Content)) })},
+			"category": &graphql.Field{Type: str, Resolve: postField(func(p *models.BlogPost) interface{} { return p.Category })},
+			"tags": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(str)), Resolve: postField(func(p *models.BlogPost) interface{} {
+				if p.Tags == nil {
+					return []string{}
+				}
+				return p.Tags
+			})},
+			"status": &graphql.Field{Type: graphql.NewNonNull(statusEnum), Resolve: postField(func(p *models.BlogPost) interface{} {
+				if p.IsPublished() {
+					return models.StatusPublished
+				}
+				return p.Status
+			})},
+			"publishAt": &graphql.Field{Type: graphql.DateTime, Resolve: postField(func(p *models.BlogPost) interface{} {
+				if p.PublishAt == nil {
+					return nil
+				}
+				return *p.PublishAt
+			})},
+			"createdAt": &graphql.Field{Type: date, Resolve: postField(func(p *models.BlogPost) interface{} { return p.CreatedAt })},
+			"updatedAt": &graphql.Field{Type: date, Resolve: postField(func(p *models.BlogPost) interface{} { return p.UpdatedAt })},
+			"version":   &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: postField(func(p *models.BlogPost) interface{} { return p.Version })},
+			"author": &graphql.Field{Type: userType, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				post := p.Source.(*models.BlogPost)
+				if post.AuthorID.IsZero() {
+					return nil, nil
+				}
+				return graphRequestOf(p).loader.authors.get(post.AuthorID.Hex()), nil
+			}},
+			"comments": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(commentType))), Description: "Comments, oldest first",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return graphRequestOf(p).loader.comments.get(p.Source.(*models.BlogPost).ID.Hex()), nil
+				}},
+			"commentCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return graphRequestOf(p).loader.counts.get(p.Source.(*models.BlogPost).ID.Hex()), nil
+			}},
+		},
+	})
+	postList := graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(postType)))
+	first := &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 10, Description: "Page size, at most 100"}
+
+	connectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "PostConnection",
+		Description: "Page of posts, pass endCursor as after to get next one",
+		Fields: graphql.Fields{
+			"nodes": &graphql.Field{Type: postList, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return postPointers(p.Source.(*models.Page).Posts), nil
+			}},
+			"endCursor": &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if next := p.Source.(*models.Page).Next; next != "" {
+					return next, nil
+				}
+				return nil, nil
+			}},
+			"hasNextPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*models.Page).Next != "", nil
+			}},
+		},
+	})
+
+	tagType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Tag",
+		Fields: graphql.Fields{
+			"name": &graphql.Field{Type: str, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(models.TagCount).Name, nil
+			}},
+			"count": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "Published posts with tag",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(models.TagCount).Count, nil
+				}},
+			"posts": &graphql.Field{Type: postList, Description: "Latest published posts with tag",
+				Args: graphql.FieldConfigArgument{"first": first},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					size, err := pageSize(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					load := graphRequestOf(p).loader.tagPosts.get(p.Source.(models.TagCount).Name)
+					return func() (interface{}, error) {
+						v, err := load()
+						if err != nil {
+							return nil, err
+						}
+						posts, _ := v.([]models.BlogPost)
+						if len(posts) > size {
+							posts = posts[:size]
+						}
+						return postPointers(posts), nil
+					}, nil
+				}},
+		},
+	})
+
+	filterType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "PostFilter",
+		Description: "Posts matching all set fields, status is published by default",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"tag":      &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"category": &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"status":   &graphql.InputObjectFieldConfig{Type: statusEnum},
+			"authorId": &graphql.InputObjectFieldConfig{Type: graphql.ID},
+			"year":     &graphql.InputObjectFieldConfig{Type: graphql.Int},
+			"month":    &graphql.InputObjectFieldConfig{Type: graphql.Int, Description: "Month of year, needs year"},
+		},
+	})
+
+	inputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "PostInput",
+		Description: "Post to create or update, omitted date and status keep stored ones",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"title":     &graphql.InputObjectFieldConfig{Type: str},
+			"date":      &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+			"link":      &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"content":   &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"tags":      &graphql.InputObjectFieldConfig{Type: graphql.NewList(str)},
+			"category":  &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"status":    &graphql.InputObjectFieldConfig{Type: statusEnum},
+			"publishAt": &graphql.InputObjectFieldConfig{Type: graphql.DateTime},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"posts": &graphql.Field{
+				Type: graphql.NewNonNull(connectionType),
+				Args: graphql.FieldConfigArgument{
+					"filter": &graphql.ArgumentConfig{Type: filterType},
+					"first":  first,
+					"after":  &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return graphRequestOf(p).posts(p.Args)
+				},
+			},
+			"post": &graphql.Field{
+				Type:        postType,
+				Description: "Post by id or slug, null when there is none",
+				Args: graphql.FieldConfigArgument{
+					"id":   &graphql.ArgumentConfig{Type: graphql.ID},
+					"slug": &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return graphRequestOf(p).post(p.Args)
+				},
+			},
+			"tags": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(tagType))),
+				Description: "Tags of published posts, ordered by name",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return graphRequestOf(p).c.Store.ListTags()
+				},
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createPost": &graphql.Field{
+				Type:        graphql.NewNonNull(postType),
+				Description: "Create post written by current user, it is a draft unless status is set",
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(inputType)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return graphRequestOf(p).createPost(p.Args)
+				},
+			},
+			"updatePost": &graphql.Field{
+				Type:        graphql.NewNonNull(postType),
+				Description: "Update post of version client has read",
+				Args: graphql.FieldConfigArgument{
+					"id":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"version": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					"input":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(inputType)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return graphRequestOf(p).updatePost(p.Args)
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+// pageSize is first argument checked against apiMaxLimit
+func pageSize(args map[string]interface{}) (int, error) {
+	size, _ := args["first"].(int)
+	if size < 1 || size > apiMaxLimit {
+		return 0, errors.Errorf("first must be from 1 to %v", apiMaxLimit)
+	}
+	return size, nil
+}
+
+// postFilter reads filter argument, status is published unless set
+func postFilter(args map[string]interface{}) (models.PostFilter, error) {
+	f := models.PostFilter{Status: models.StatusPublished}
+	in, _ := args["filter"].(map[string]interface{})
+	if tag, ok := in["tag"].(string); ok {
+		f.Tag = models.NormalizeTag(tag)
+	}
+	if category, ok := in["category"].(string); ok {
+		f.Category = models.NormalizeTag(category)
+	}
+	if status, ok := in["status"].(string); ok {
+		f.Status = status
+	}
+	if author, ok := in["authorId"].(string); ok {
+		id, err := parseObjectID(author)
+		if err != nil {
+			return f, errors.Errorf("Bad author id %q", author)
+		}
+		f.AuthorID = id
+	}
+	year, hasYear := in["year"].(int)
+	month, hasMonth := in["month"].(int)
+	switch {
+	case hasMonth && (!hasYear || month < 1 || month > 12):
+		return f, errors.New("month must be from 1 to 12 and needs year")
+	case hasMonth:
+		f.From = time.Date(year, time.Month(month), 1, 0, 0, 0, 0, dates.Zone)
+		f.To = f.From.AddDate(0, 1, 0)
+	case hasYear:
+		f.From = time.Date(year, time.January, 1, 0, 0, 0, 0, dates.Zone)
+		f.To = f.From.AddDate(1, 0, 0)
+	}
+	return f, nil
+}
+
+// posts loads page of posts matching filter the user may see,
+// unpublished posts are listed for editors and for their own authors
+func (r *graphRequest) posts(args map[string]interface{}) (*models.Page, error) {
+	size, err := pageSize(args)
+	if err != nil {
+		return nil, err
+	}
+	f, err := postFilter(args)
+	if err != nil {
+		return nil, err
+	}
+	after, _ := args["after"].(string)
+	user := r.c.currentUser()
+	if f.Status != models.StatusPublished && !user.HasRole(models.RoleEditor) {
+		if user == nil || (!f.AuthorID.IsZero() && f.AuthorID != user.ID) {
+			return &models.Page{Posts: []models.BlogPost{}}, nil
+		}
+		f.AuthorID = user.ID
+	}
+	page, err := models.ListFilteredPage(r.c.Store, f, after, size)
+	if err != nil && err != models.ErrBadCursor {
+		return nil, errors.Wrap(err, "Can not load posts")
+	}
+	return page, err
+}
+
+// post loads post by id or slug, posts the user may not see are null
+func (r *graphRequest) post(args map[string]interface{}) (interface{}, error) {
+	var post *models.BlogPost
+	var err error
+	if id, ok := args["id"].(string); ok {
+		post, err = r.c.GetPostByID(id)
+	} else if slug, ok := args["slug"].(string); ok {
+		post, err = r.c.Store.GetPostBySlug(slug)
+	} else {
+		return nil, errors.New("Post id or slug is required")
+	}
+	if errors.Cause(err) == models.ErrPostNotFound || (err == nil && !r.c.canSee(post)) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Can not load post")
+	}
+	return post, nil
+}
+
+// postInput converts input argument for api validation
+func postInput(args map[string]interface{}) *apiPostInput {
+	in := &apiPostInput{}
+	fields, _ := args["input"].(map[string]interface{})
+	in.Title, _ = fields["title"].(string)
+	in.Link, _ = fields["link"].(string)
+	in.Content, _ = fields["content"].(string)
+	in.Category, _ = fields["category"].(string)
+	in.Status, _ = fields["status"].(string)
+	if tags, ok := fields["tags"].([]interface{}); ok {
+		for _, tag := range tags {
+			if s, ok := tag.(string); ok {
+				in.Tags = append(in.Tags, s)
+			}
+		}
+	}
+	if d, ok := fields["date"].(time.Time); ok {
+		in.Date = &d
+	}
+	if d, ok := fields["publishAt"].(time.Time); ok {
+		in.PublishAt = &d
+	}
+	return in
+}
+
+// createPost stores new post of current user
+func (r *graphRequest) createPost(args map[string]interface{}) (interface{}, error) {
+	user := r.c.currentUser()
+	if user == nil {
+		return nil, errLoginRequired
+	}
+	if !user.HasRole(models.RoleAuthor) {
+		return nil, models.ErrForbidden
+	}
+	post := &models.BlogPost{Date: time.Now(), Status: models.StatusDraft}
+	if fields := postInput(args).apply(post); len(fields) > 0 {
+		return nil, graphValidationError{fields}
+	}
+	if err := r.c.AddPost(post); err != nil {
+		return nil, errors.Wrap(err, "Can not create post")
+	}
+	beego.Info("Created post over graphql:", post.Title)
+	return post, nil
+}
+
+// updatePost changes post the user may edit
+func (r *graphRequest) updatePost(args map[string]interface{}) (interface{}, error) {
+	user := r.c.currentUser()
+	if user == nil {
+		return nil, errLoginRequired
+	}
+	id, _ := args["id"].(string)
+	stored, err := r.c.GetPostByID(id)
+	if err == nil && !r.c.canSee(stored) {
+		err = models.ErrPostNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !user.CanEditPost(stored) {
+		return nil, models.ErrForbidden
+	}
+
+	post := *stored
+	// drafts get slug of their new title, see models.UpdatePostWithRevision
+	post.Slug = ""
+	post.Version, _ = args["version"].(int)
+	if fields := postInput(args).apply(&post); len(fields) > 0 {
+		return nil, graphValidationError{fields}
+	}
+	if err := r.c.UpdateBlogPost(&post); err != nil {
+		if errors.Cause(err) == models.ErrVersionConflict {
+			return nil, models.ErrVersionConflict
+		}
+		return nil, errors.Wrap(err, "Can not update post")
+	}
+	beego.Info("Updated post over graphql:", post.Title)
+	return &post, nil
+}
+
+// sendGraphErrors sends request errors in GraphQL response format
+func (c *MainController) sendGraphErrors(status int, errs ...error) {
+	formatted := make([]gqlerrors.FormattedError, len(errs))
+	for i, err := range errs {
+		formatted[i] = gqlerrors.FormatError(err)
+	}
+	c.sendJSON(status, &graphql.Result{Errors: formatted})
+}
+
+// readGraphQuery reads query from url of GET or json body of POST
+func (c *MainController) readGraphQuery() (*graphQuery, error) {
+	q := &graphQuery{}
+	if c.Ctx.Request.Method == http.MethodGet {
+		q.Query = c.GetString("query")
+		q.OperationName = c.GetString("operationName")
+		if vars := c.GetString("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &q.Variables); err != nil {
+				return nil, errors.Wrap(err, "Can not parse variables")
+			}
+		}
+		return q, nil
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(c.Ctx.ResponseWriter, c.Ctx.Request.Body, apiMaxBody))
+	if err := dec.Decode(q); err != nil {
+		return nil, errors.Wrap(err, "Can not parse json body")
+	}
+	return q, nil
+}
+
+// operationOf finds operation to run, name may be empty when there is one
+func operationOf(doc *ast.Document, name string) *ast.OperationDefinition {
+	var found *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if name == "" && found != nil {
+			return nil
+		}
+		if name == "" || (op.Name != nil && op.Name.Value == name) {
+			found = op
+		}
+	}
+	return found
+}
+
+// GraphQL runs query or mutation. Malformed, invalid and too
+// expensive queries are 400, resolver errors come with partial data.
+func (c *MainController) GraphQL() {
+	beego.Info("GraphQL")
+
+	q, err := c.readGraphQuery()
+	if err != nil {
+		c.sendGraphErrors(http.StatusBadRequest, err)
+		return
+	}
+	doc, err := parser.Parse(parser.ParseParams{Source: source.NewSource(&source.Source{Body: []byte(q.Query), Name: "GraphQL request"})})
+	if err != nil {
+		c.sendGraphErrors(http.StatusBadRequest, err)
+		return
+	}
+	if result := graphql.ValidateDocument(&graphSchema, doc, nil); !result.IsValid {
+		c.sendJSON(http.StatusBadRequest, &graphql.Result{Errors: result.Errors})
+		return
+	}
+	op := operationOf(doc, q.OperationName)
+	if op == nil {
+		c.sendGraphErrors(http.StatusBadRequest, errNoOperation)
+		return
+	}
+	if op.Operation == ast.OperationTypeMutation && c.Ctx.Request.Method != http.MethodPost {
+		c.sendGraphErrors(http.StatusMethodNotAllowed, errMutationMethod)
+		return
+	}
+	limits := graphLimits{
+		depth:      beego.AppConfig.DefaultInt("graphqlMaxDepth", 8),
+		complexity: beego.AppConfig.DefaultInt("graphqlMaxComplexity", 1000),
+	}
+	if err := limits.check(doc, op, q.Variables); err != nil {
+		beego.Warn("Rejected graphql query:", err)
+		c.sendGraphErrors(http.StatusBadRequest, err)
+		return
+	}
+
+	ctx := context.WithValue(c.Ctx.Request.Context(), graphContextKey{}, &graphRequest{c: c, loader: newGraphLoader(c.Store)})
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        graphSchema,
+		AST:           doc,
+		OperationName: q.OperationName,
+		Args:          q.Variables,
+		Context:       ctx,
+	})
+	for _, e := range result.Errors {
+		beego.Warn("GraphQL error:", e.Message)
+	}
+	c.sendJSON(http.StatusOK, result)
+}
@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/pkg/errors"
+)
+
+// graphListCost is assumed length of lists without first argument
+const graphListCost = 10
+
+// graphLimits are maximal query depth and complexity, complexity
+// counts fields, fields below a list count once per list item
+type graphLimits struct {
+	depth      int
+	complexity int
+}
+
+// graphCost measures operation of document
+type graphCost struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// check tells why operation is too expensive, nil when it is not
+func (l graphLimits) check(doc *ast.Document, op *ast.OperationDefinition, variables map[string]interface{}) error {
+	cost := &graphCost{fragments: map[string]*ast.FragmentDefinition{}, variables: variables}
+	for _, def := range doc.Definitions {
+		if f, ok := def.(*ast.FragmentDefinition); ok {
+			cost.fragments[f.Name.Value] = f
+		}
+	}
+	if depth := cost.depth(op.SelectionSet, map[string]bool{}); depth > l.depth {
+		return errors.Errorf("Query depth %v exceeds limit %v", depth, l.depth)
+	}
+	if complexity := cost.complexity(op.SelectionSet, map[string]bool{}); complexity > l.complexity {
+		return errors.Errorf("Query complexity %v exceeds limit %v", complexity, l.complexity)
+	}
+	return nil
+}
+
+// fields lists fields of selection set with fragments spread,
+// introspection fields are free
+func (g *graphCost) fields(set *ast.SelectionSet, seen map[string]bool) []*ast.Field {
+	if set == nil {
+		return nil
+	}
+	fields := []*ast.Field{}
+	for _, sel := range set.Selections {
+		switch s := sel.(type) {
+		case *ast.Field:
+			if !strings.HasPrefix(s.Name.Value, "__") {
+				fields = append(fields, s)
+			}
+		case *ast.InlineFragment:
+			fields = append(fields, g.fields(s.SelectionSet, seen)...)
+		case *ast.FragmentSpread:
+			f := g.fragments[s.Name.Value]
+			if f == nil || seen[s.Name.Value] {
+				continue
+			}
+			seen[s.Name.Value] = true
+			fields = append(fields, g.fields(f.SelectionSet, seen)...)
+			delete(seen, s.Name.Value)
+		}
+	}
+	return fields
+}
+
+func (g *graphCost) depth(set *ast.SelectionSet, seen map[string]bool) int {
+	max := 0
+	for _, f := range g.fields(set, seen) {
+		if d := 1 + g.depth(f.SelectionSet, seen); d > max {
+			max = d
+		}
+	}
+	return max
+}
+
+func (g *graphCost) complexity(set *ast.SelectionSet, seen map[string]bool) int {
+	total := 0
+	for _, f := range g.fields(set, seen) {
+		total++
+		if f.SelectionSet != nil {
+			total += g.listSize(f) * g.complexity(f.SelectionSet, seen)
+		}
+	}
+	return total
+}
+
+// listSize is first argument of field, fields returning objects count as 1
+// and lists without first as graphListCost
+func (g *graphCost) listSize(f *ast.Field) int {
+	for _, arg := range f.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(v.Value); err == nil {
+				return n
+			}
+		case *ast.Variable:
+			if n, ok := g.variables[v.Name.Value].(float64); ok {
+				return int(n)
+			}
+		}
+		return apiMaxLimit
+	}
+	if graphListFields[f.Name.Value] {
+		return graphListCost
+	}
+	return 1
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"hw8/models"
+	"sort"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// batch loads values by key for one GraphQL request. Resolvers queue keys
+// and return thunks, graphql runs thunks after resolving all sibling
+// fields, so the first thunk loads every queued key with one store call.
+type batch struct {
+	mu      sync.Mutex
+	pending map[string]bool
+	values  map[string]interface{}
+	load    func(keys []string) (map[string]interface{}, error)
+}
+
+func newBatch(load func(keys []string) (map[string]interface{}, error)) *batch {
+	return &batch{pending: map[string]bool{}, values: map[string]interface{}{}, load: load}
+}
+
+// get queues key and returns thunk of its value
+func (b *batch) get(key string) func() (interface{}, error) {
+	b.mu.Lock()
+	if _, ok := b.values[key]; !ok {
+		b.pending[key] = true
+	}
+	b.mu.Unlock()
+
+	return func() (interface{}, error) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if len(b.pending) > 0 {
+			keys := make([]string, 0, len(b.pending))
+			for k := range b.pending {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			b.pending = map[string]bool{}
+			loaded, err := b.load(keys)
+			if err != nil {
+				return nil, err
+			}
+			for k, v := range loaded {
+				b.values[k] = v
+			}
+		}
+		return b.values[key], nil
+	}
+}
+
+// graphLoader has batches of data posts refer to
+type graphLoader struct {
+	comments *batch
+	counts   *batch
+	authors  *batch
+	tagPosts *batch
+}
+
+func newGraphLoader(store models.Store) *graphLoader {
+	return &graphLoader{
+		comments: newBatch(func(keys []string) (map[string]interface{}, error) {
+			byPost, err := store.ListCommentsOf(objectIDs(keys))
+			if err != nil {
+				return nil, err
+			}
+			values := map[string]interface{}{}
+			for id, comments := range byPost {
+				values[id.Hex()] = comments
+			}
+			return values, nil
+		}),
+		counts: newBatch(func(keys []string) (map[string]interface{}, error) {
+			counts, err := store.CountComments(objectIDs(keys))
+			if err != nil {
+				return nil, err
+			}
+			values := map[string]interface{}{}
+			for id, n := range counts {
+				values[id.Hex()] = n
+			}
+			return values, nil
+		}),
+		// users are few, all of them are read with one query
+		authors: newBatch(func(keys []string) (map[string]interface{}, error) {
+			users, err := store.ListUsers()
+			if err != nil {
+				return nil, err
+			}
+			values := map[string]interface{}{}
+			for i := range users {
+				values[users[i].ID.Hex()] = &users[i]
+			}
+			return values, nil
+		}),
+		tagPosts: newBatch(func(keys []string) (map[string]interface{}, error) {
+			values := map[string]interface{}{}
+			if len(keys) == 1 {
+				posts, err := store.ListPostsByTag(keys[0])
+				values[keys[0]] = posts
+				return values, err
+			}
+			posts, err := store.ListPosts()
+			if err != nil {
+				return nil, err
+			}
+			for _, tag := range keys {
+				values[tag] = models.FilterPosts(posts, models.PostFilter{Tag: tag, Status: models.StatusPublished})
+			}
+			return values, nil
+		}),
+	}
+}
+
+// objectIDs parses hex keys, keys come from stored ids so they are valid
+func objectIDs(keys []string) []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, 0, len(keys))
+	for _, k := range keys {
+		if id, err := primitive.ObjectIDFromHex(k); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
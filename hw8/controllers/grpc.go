@@ -0,0 +1,333 @@
+package controllers
+
+import (
+	"context"
+	"hw8/blogpb"
+	"hw8/models"
+	"hw8/search"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/astaxie/beego"
+	beecontext "github.com/astaxie/beego/context"
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcPageSize is posts read from store at once while streaming
+const grpcPageSize = 50
+
+// BlogServer serves blogpb.BlogService on the store web routes use
+type BlogServer struct {
+	blogpb.UnimplementedBlogServiceServer
+	Store models.Store
+	Index *search.Index
+}
+
+// NewGRPCServer makes gRPC server with blog, health and reflection services
+func NewGRPCServer(store models.Store, index *search.Index) *grpc.Server {
+	srv := grpc.NewServer()
+	blogpb.RegisterBlogServiceServer(srv, &BlogServer{Store: store, Index: index})
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(blogpb.BlogService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, healthServer)
+	reflection.Register(srv)
+	return srv
+}
+
+// controller is MainController acting for the caller, so calls share
+// validation, revisions and search indexing with web actions.
+// Caller is the user of bearer token in authorization metadata.
+func (s *BlogServer) controller(ctx context.Context) *MainController {
+	c := &MainController{Store: s.Store, Index: s.Index}
+	req := &http.Request{Header: http.Header{}}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		req.RemoteAddr = p.Addr.String()
+	}
+	c.Ctx = beecontext.NewContext()
+	c.Ctx.Reset(nil, req)
+
+	var user *models.User
+	md, _ := metadata.FromIncomingContext(ctx)
+	if auth := md.Get("authorization"); len(auth) > 0 && strings.HasPrefix(auth[0], "Bearer ") {
+		var err error
+		user, err = models.SessionUser(s.Store, strings.TrimSpace(strings.TrimPrefix(auth[0], "Bearer ")))
+		if err != nil && err != models.ErrSessionNotFound {
+			beego.Error(errors.Wrap(err, "Can not load session"))
+		}
+	}
+	c.Data = map[interface{}]interface{}{"User": user}
+	return c
+}
+
+// grpcError converts error to status, unexpected errors are logged
+func grpcError(err error, msg string) error {
+	code := codes.Internal
+	switch errors.Cause(err) {
+	case models.ErrPostNotFound:
+		code = codes.NotFound
+	case models.ErrVersionConflict:
+		code = codes.Aborted
+	case models.ErrForbidden:
+		code = codes.PermissionDenied
+	case errLoginRequired:
+		code = codes.Unauthenticated
+	case errVersionRequired:
+		code = codes.FailedPrecondition
+	case models.ErrBadCursor:
+		code = codes.InvalidArgument
+	}
+	if code == codes.Internal {
+		err = errors.Wrap(err, msg)
+		beego.Error(err)
+	}
+	return status.Error(code, err.Error())
+}
+
+// validationError is InvalidArgument status with field violations
+func validationError(fields map[string]string) error {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	details := &errdetails.BadRequest{}
+	for _, name := range names {
+		details.FieldViolations = append(details.FieldViolations,
+			&errdetails.BadRequest_FieldViolation{Field: name, Description: fields[name]})
+	}
+	st, err := status.New(codes.InvalidArgument, errValidation.Error()).WithDetails(details)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, errValidation.Error())
+	}
+	return st.Err()
+}
+
+// protoStatus is enum value of post status
+func protoStatus(s string) blogpb.Status {
+	return blogpb.Status(blogpb.Status_value["STATUS_"+strings.ToUpper(s)])
+}
+
+// protoTime is timestamp of time, nil for nil time
+func protoTime(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// newProtoPost converts post for gRPC, fields are the ones api sends
+func newProtoPost(post *models.BlogPost) *blogpb.Post {
+	p := newAPIPost(post)
+	return &blogpb.Post{
+		Id:        p.ID,
+		Slug:      p.Slug,
+		Url:       p.URL,
+		Title:     p.Title,
+		Date:      timestamppb.New(p.Date),
+		Link:      p.Link,
+		Content:   p.Content,
+		Tags:      p.Tags,
+		Category:  p.Category,
+		Status:    protoStatus(p.Status),
+		PublishAt: protoTime(p.PublishAt),
+		AuthorId:  p.AuthorID,
+		CreatedAt: timestamppb.New(p.CreatedAt),
+		UpdatedAt: timestamppb.New(p.UpdatedAt),
+		Version:   int32(p.Version),
+	}
+}
+
+// newPostInput converts gRPC input for api validation
+func newPostInput(in *blogpb.PostInput) *apiPostInput {
+	p := &apiPostInput{
+		Title:    in.GetTitle(),
+		Link:     in.GetLink(),
+		Content:  in.GetContent(),
+		Tags:     in.GetTags(),
+		Category: in.GetCategory(),
+	}
+	if in.GetStatus() != blogpb.Status_STATUS_UNSPECIFIED {
+		p.Status = strings.ToLower(strings.TrimPrefix(in.GetStatus().String(), "STATUS_"))
+	}
+	if in.GetDate() != nil {
+		d := in.GetDate().AsTime()
+		p.Date = &d
+	}
+	if in.GetPublishAt() != nil {
+		d := in.GetPublishAt().AsTime()
+		p.PublishAt = &d
+	}
+	return p
+}
+
+// visiblePost loads post by id the caller may see
+func (c *MainController) visiblePost(id string) (*models.BlogPost, error) {
+	post, err := c.GetPostByID(id)
+	if err == nil && !c.canSee(post) {
+		err = models.ErrPostNotFound
+	}
+	return post, err
+}
+
+// editablePost loads post by id the caller may change
+func (c *MainController) editablePost(id string) (*models.BlogPost, error) {
+	user := c.currentUser()
+	if user == nil {
+		return nil, errLoginRequired
+	}
+	post, err := c.visiblePost(id)
+	if err != nil {
+		return nil, err
+	}
+	if !user.CanEditPost(post) {
+		return nil, models.ErrForbidden
+	}
+	return post, nil
+}
+
+// GetPost sends post by id
+func (s *BlogServer) GetPost(ctx context.Context, req *blogpb.GetPostRequest) (*blogpb.Post, error) {
+	beego.Info("GRPC GetPost")
+
+	post, err := s.controller(ctx).visiblePost(req.GetId())
+	if err != nil {
+		return nil, grpcError(err, "No post found")
+	}
+	return newProtoPost(post), nil
+}
+
+// CreatePost creates post written by caller
+func (s *BlogServer) CreatePost(ctx context.Context, req *blogpb.CreatePostRequest) (*blogpb.Post, error) {
+	beego.Info("GRPC CreatePost")
+
+	c := s.controller(ctx)
+	user := c.currentUser()
+	if user == nil {
+		return nil, grpcError(errLoginRequired, "")
+	}
+	if !user.HasRole(models.RoleAuthor) {
+		return nil, grpcError(models.ErrForbidden, "")
+	}
+	post := &models.BlogPost{Date: time.Now(), Status: models.StatusDraft}
+	if fields := newPostInput(req.GetPost()).apply(post); len(fields) > 0 {
+		return nil, validationError(fields)
+	}
+	if err := c.AddPost(post); err != nil {
+		return nil, grpcError(err, "Can not create post")
+	}
+
+	beego.Info("Created post over grpc:", post.Title)
+	return newProtoPost(post), nil
+}
+
+// UpdatePost replaces post of version caller has read
+func (s *BlogServer) UpdatePost(ctx context.Context, req *blogpb.UpdatePostRequest) (*blogpb.Post, error) {
+	beego.Info("GRPC UpdatePost")
+
+	c := s.controller(ctx)
+	stored, err := c.editablePost(req.GetId())
+	if err != nil {
+		return nil, grpcError(err, "No post found")
+	}
+	if req.Version == nil {
+		return nil, grpcError(errVersionRequired, "")
+	}
+
+	post := *stored
+	// drafts get slug of their new title, see models.UpdatePostWithRevision
+	post.Slug = ""
+	post.Version = int(req.GetVersion())
+	if fields := newPostInput(req.GetPost()).apply(&post); len(fields) > 0 {
+		return nil, validationError(fields)
+	}
+	if err := c.UpdateBlogPost(&post); err != nil {
+		return nil, grpcError(err, "Can not update post")
+	}
+
+	beego.Info("Updated post over grpc:", post.Title)
+	return newProtoPost(&post), nil
+}
+
+// DeletePost moves post to trash
+func (s *BlogServer) DeletePost(ctx context.Context, req *blogpb.DeletePostRequest) (*blogpb.DeletePostResponse, error) {
+	beego.Info("GRPC DeletePost")
+
+	c := s.controller(ctx)
+	post, err := c.editablePost(req.GetId())
+	if err == nil {
+		err = s.Store.DeletePost(post.ID)
+	}
+	if err != nil {
+		return nil, grpcError(err, "Can not delete post")
+	}
+	c.unindexPost(post.ID)
+	c.queuePublishedEvent(models.EventPostDeleted, post)
+
+	beego.Info("Deleted post over grpc:", post.ID.Hex())
+	return &blogpb.DeletePostResponse{}, nil
+}
+
+// ListPosts streams published posts. All posts are read page by page,
+// tag and category lists are read at once by the store.
+func (s *BlogServer) ListPosts(req *blogpb.ListPostsRequest, stream blogpb.BlogService_ListPostsServer) error {
+	beego.Info("GRPC ListPosts", req.GetTag(), req.GetCategory())
+
+	limit := int(req.GetLimit())
+	if limit < 0 {
+		return validationError(map[string]string{"limit": "must not be negative"})
+	}
+	sent := 0
+	send := func(posts []models.BlogPost) (bool, error) {
+		for i := range posts {
+			if limit > 0 && sent >= limit {
+				return false, nil
+			}
+			if err := stream.Send(newProtoPost(&posts[i])); err != nil {
+				return false, err
+			}
+			sent++
+		}
+		return true, nil
+	}
+
+	tag, category := models.NormalizeTag(req.GetTag()), models.NormalizeTag(req.GetCategory())
+	if tag != "" || category != "" {
+		var posts []models.BlogPost
+		var err error
+		if tag != "" {
+			posts, err = s.Store.ListPostsByTag(tag)
+		} else {
+			posts, err = s.Store.ListPostsByCategory(category)
+		}
+		if err != nil {
+			return grpcError(err, "Can not load posts")
+		}
+		_, err = send(models.FilterPosts(posts, models.PostFilter{Tag: tag, Category: category, Status: models.StatusPublished}))
+		return err
+	}
+
+	cursor := ""
+	for {
+		page, err := models.ListPage(s.Store, cursor, grpcPageSize)
+		if err != nil {
+			return grpcError(err, "Can not load posts")
+		}
+		more, err := send(page.Posts)
+		if err != nil || !more || page.Next == "" {
+			return err
+		}
+		cursor = page.Next
+	}
+}
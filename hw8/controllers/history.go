@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"fmt"
+	"hw8/diff"
+	"hw8/models"
+	"net/http"
+	"strconv"
+
+	"github.com/astaxie/beego"
+	"github.com/pkg/errors"
+)
+
+// editorName identifies who changes posts,
+// anonymous editors are known by their address only
+func (c *MainController) editorName() string {
+	if user := c.currentUser(); user != nil {
+		return user.Name
+	}
+	if c.Ctx == nil {
+		return ""
+	}
+	return c.Ctx.Input.IP()
+}
+
+// revisionText is revision shown as text for diff
+func revisionText(rev *models.Revision) string {
+	date := dates.Input(rev.Date, models.DateLayout)
+	return fmt.Sprintf("Title: %v\nDate: %v\nLink: %v\n\n%v", rev.Title, date, rev.Link, rev.Content)
+}
+
+// ShowHistory shows post revisions and diff between two of them
+func (c *MainController) ShowHistory() {
+	beego.Info("ShowHistory")
+
+	req := c.Ctx.Request
+	post, err := c.GetPostByID(req.URL.Query().Get("id"))
+	if err == nil && !c.canSee(post) {
+		err = models.ErrPostNotFound
+	}
+	if err != nil {
+		err = errors.Wrap(err, "No post found")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), postErrorStatus(err))
+		beego.Error(err)
+		return
+	}
+
+	revs, err := c.Store.ListRevisions(post.ID)
+	if err != nil {
+		err = errors.Wrap(err, "Can not load revisions")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusInternalServerError)
+		beego.Error(err)
+		return
+	}
+
+	from, _ := strconv.Atoi(req.URL.Query().Get("from"))
+	to, _ := strconv.Atoi(req.URL.Query().Get("to"))
+	if from > 0 && to > 0 {
+		fromRev, err := c.Store.GetRevision(post.ID, from)
+		if err == nil {
+			var toRev *models.Revision
+			toRev, err = c.Store.GetRevision(post.ID, to)
+			if err == nil {
+				c.Data["Diff"] = diff.Lines(revisionText(fromRev), revisionText(toRev))
+			}
+		}
+		if err != nil {
+			err = errors.Wrap(err, "Can not load revision")
+			http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusNotFound)
+			beego.Error(err)
+			return
+		}
+	}
+
+	c.Data["Title"] = post.Title
+	c.Data["Post"] = post
+	c.Data["Revisions"] = revs
+	c.Data["From"] = from
+	c.Data["To"] = to
+	c.TplName = "history.tpl"
+}
+
+// RestoreRevision makes old revision current
+func (c *MainController) RestoreRevision() {
+	beego.Info("RestoreRevision")
+
+	req := c.Ctx.Request
+	objID, err := parseObjectID(req.FormValue("id"))
+	if err != nil {
+		err = errors.Wrap(err, "Can not parse post id")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusBadRequest)
+		beego.Error(err)
+		return
+	}
+
+	number, err := strconv.Atoi(req.FormValue("revision"))
+	if err != nil {
+		err = errors.Wrap(err, "Can not parse revision")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusBadRequest)
+		beego.Error(err)
+		return
+	}
+
+	rev, err := models.RestoreRevision(c.Store, objID, number, c.editorName())
+	if err != nil {
+		status := postErrorStatus(err)
+		if errors.Cause(err) == models.ErrRevisionNotFound {
+			status = http.StatusNotFound
+		}
+		err = errors.Wrap(err, "Can not restore revision")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), status)
+		beego.Error(err)
+		return
+	}
+
+	beego.Info("Restored revision", number, "as", rev.Number)
+	c.queuePublishedEvent(models.EventPostUpdated, c.reindexPost(objID))
+	c.Redirect("/post/history?id="+objID.Hex(), http.StatusFound)
+}
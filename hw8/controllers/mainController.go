@@ -1,23 +1,22 @@
 package controllers
 
 import (
-	ctx "context"
 	"hw8/models"
+	"hw8/search"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/astaxie/beego"
 	"github.com/pkg/errors"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // MainController controller
 type MainController struct {
 	beego.Controller
-	DB     *mongo.Client
-	DBName string
+	Store models.Store
+	Index *search.Index
 }
 
 func parseObjectID(str string) (primitive.ObjectID, error) {
@@ -26,26 +25,54 @@ func parseObjectID(str string) (primitive.ObjectID, error) {
 	return primitive.ObjectIDFromHex(hex)
 }
 
+// postErrorStatus maps store error to http status
+func postErrorStatus(err error) int {
+	switch errors.Cause(err) {
+	case models.ErrPostNotFound:
+		return http.StatusNotFound
+	case models.ErrForbidden:
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
+// renderStatus renders template with error status,
+// beego skips auto render once status is set
+func (c *MainController) renderStatus(status int) {
+	c.Ctx.Output.SetStatus(status)
+	if err := c.Render(); err != nil {
+		beego.Error(err)
+	}
+}
+
 // ListPosts gets main page
 func (c *MainController) ListPosts() {
 	beego.Info("ListPosts")
 
-	posts, err := c.GetAllPosts()
+	page, err := c.GetPostsPage(c.GetString("cursor"))
 	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Cause(err) == models.ErrBadCursor {
+			status = http.StatusBadRequest
+		}
 		err = errors.Wrap(err, "Can not load posts")
-		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusInternalServerError)
+		http.Error(c.Ctx.ResponseWriter, err.Error(), status)
 		beego.Error(err)
 		return
 	}
 
-	beego.Info("Loaded %v posts", len(posts))
+	beego.Info("Loaded %v posts", len(page.Posts))
 
 	c.Data["Title"] = "Blog"
-	c.Data["Posts"] = posts
+	c.Data["Posts"] = page.Posts
+	c.Data["Page"] = page
+	c.Data["Tags"] = c.tagCloud()
+	c.Data["CommentCounts"] = c.commentCounts(page.Posts)
 	c.TplName = "index.tpl"
 }
 
-// ReadPost shows post
+// ReadPost shows post by id, posts having slug are permanently
+// redirected to their permalink
 func (c *MainController) ReadPost() {
 	beego.Info("ReadPost")
 
@@ -53,18 +80,22 @@ func (c *MainController) ReadPost() {
 
 	postID := req.URL.Query().Get("id")
 	post, err := c.GetPostByID(postID)
+	if err == nil && !c.canSee(post) {
+		err = models.ErrPostNotFound
+	}
 	if err != nil {
 		err := errors.Wrap(err, "No post found")
-		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusInternalServerError)
+		http.Error(c.Ctx.ResponseWriter, err.Error(), postErrorStatus(err))
 		beego.Error(err)
 		return
 	}
 
+	if post.Slug != "" {
+		c.Redirect(postURL(post), http.StatusMovedPermanently)
+		return
+	}
 	beego.Info("Post loaded: %v", post.Title)
-
-	c.Data["Title"] = post.Title
-	c.Data["Post"] = post
-	c.TplName = "post.tpl"
+	c.showPost(post)
 }
 
 // EditPost shows post
@@ -77,15 +108,18 @@ func (c *MainController) EditPost() {
 	post, err := c.GetPostByID(postID)
 	if err != nil {
 		err := errors.Wrap(err, "No post found")
-		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusInternalServerError)
+		http.Error(c.Ctx.ResponseWriter, err.Error(), postErrorStatus(err))
 		beego.Error(err)
 		return
 	}
 
 	beego.Info("Edit post: %v", post.Title)
+	// the edit form may be submitted with If-Match instead of version field
+	c.Ctx.Output.Header("ETag", postETag(post))
 
 	c.Data["Title"] = post.Title
 	c.Data["Post"] = post
+	c.Data["Statuses"] = models.Statuses
 	c.TplName = "editPost.tpl"
 }
 
@@ -105,24 +139,56 @@ func (c *MainController) UpdatePost() {
 			return
 		}
 
+		version, fromHeader, err := c.expectedVersion(objID)
+		if err != nil {
+			status := http.StatusPreconditionRequired
+			if errors.Cause(err) == models.ErrPostNotFound {
+				status = http.StatusPreconditionFailed
+			}
+			http.Error(c.Ctx.ResponseWriter, err.Error(), status)
+			beego.Error(err)
+			return
+		}
+
 		post.ID = objID
 		post.Title = req.FormValue("title")
-		post.Date = req.FormValue("date")
+		post.Date, err = parsePostDate(req.FormValue("date"))
+		if err != nil {
+			http.Error(c.Ctx.ResponseWriter, err.Error(), formErrorStatus(err))
+			beego.Error(err)
+			return
+		}
 		post.Link = req.FormValue("link")
 		post.Content = req.FormValue("content")
+		post.Tags = models.ParseTags(req.FormValue("tags"))
+		post.Category = models.NormalizeTag(req.FormValue("category"))
+		post.Version = version
+		if err = c.readStatus(post); err != nil {
+			err = errors.Wrap(err, "Can not set post status")
+			http.Error(c.Ctx.ResponseWriter, err.Error(), formErrorStatus(err))
+			beego.Error(err)
+			return
+		}
 		err = c.UpdateBlogPost(post)
+		if errors.Cause(err) == models.ErrVersionConflict {
+			beego.Warn("Edit conflict for post:", objID.Hex())
+			if fromHeader {
+				http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusPreconditionFailed)
+				return
+			}
+			c.showConflict(post)
+			return
+		}
 		if err != nil {
 			err = errors.Wrap(err, "Can not create post")
-			http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusInternalServerError)
+			http.Error(c.Ctx.ResponseWriter, err.Error(), postErrorStatus(err))
 			beego.Error(err)
 			return
 		}
 
 		beego.Info("Updated post: %v", post.Title)
-
-		c.Data["Title"] = post.Title
-		c.Data["Post"] = post
-		c.TplName = "post.tpl"
+		c.Ctx.Output.Header("ETag", postETag(post))
+		c.showPost(post)
 	}
 }
 
@@ -148,68 +214,52 @@ func (c *MainController) NewPost() {
 
 // GetAllPosts gets all posts
 func (c *MainController) GetAllPosts() ([]models.BlogPost, error) {
-	col := c.DB.Database(c.DBName).Collection("posts")
-
-	cur, err := col.Find(ctx.TODO(), bson.D{})
-	if err != nil {
-		return nil, err
-	}
-
-	posts := []models.BlogPost{}
-	err = cur.All(ctx.TODO(), &posts)
+	return c.Store.ListPosts()
+}
 
-	if err != nil {
-		return nil, err
+// GetPostsPage gets page of posts at cursor, page size is pageSize config
+func (c *MainController) GetPostsPage(cursor string) (*models.Page, error) {
+	size := beego.AppConfig.DefaultInt("pageSize", 10)
+	if size < 1 {
+		size = 10
 	}
-
-	return posts, nil
+	return models.ListPage(c.Store, cursor, size)
 }
 
-// GetPostByID gets post by id
+// GetPostByID gets post by id, malformed ids are ErrPostNotFound
 func (c *MainController) GetPostByID(postID string) (*models.BlogPost, error) {
-	col := c.DB.Database(c.DBName).Collection("posts")
-
 	objID, err := parseObjectID(postID)
 	if err != nil {
-		err = errors.Wrap(err, "Can not parse id")
-		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusInternalServerError)
-		beego.Error(err)
-		return nil, err
-	}
-
-	filter := bson.M{"_id": bson.M{"$eq": objID}}
-	res := col.FindOne(ctx.TODO(), filter)
-	post := &models.BlogPost{}
-	err = res.Decode(post)
-	if err != nil {
-		return nil, err
+		// malformed id matches no post
+		return nil, errors.Wrapf(models.ErrPostNotFound, "Bad post id %q", postID)
 	}
-	return post, nil
+	return c.Store.GetPost(objID)
 }
 
-// AddPost new post
+// AddPost new post, written by current user, webhooks hear about it
 func (c *MainController) AddPost(post *models.BlogPost) error {
-	col := c.DB.Database(c.DBName).Collection("posts")
-	result, err := col.InsertOne(ctx.TODO(), post)
-	if err != nil {
+	if user := c.currentUser(); user != nil && post.AuthorID.IsZero() {
+		post.AuthorID = user.ID
+	}
+	if err := models.CreatePostWithRevision(c.Store, post, c.editorName()); err != nil {
 		return err
 	}
-	objID := result.InsertedID.(primitive.ObjectID)
-	post.ID = objID
+	c.indexPost(post)
+	c.queueEvent(models.PostChangeEvent(nil, post), post)
 	return nil
 }
 
-// UpdateBlogPost updates post
+// UpdateBlogPost updates post, saves its revision and notifies webhooks
 func (c *MainController) UpdateBlogPost(post *models.BlogPost) error {
-	col := c.DB.Database(c.DBName).Collection("posts")
-
-	filter := bson.M{"_id": bson.M{"$eq": post.ID}}
-	update := bson.M{"$set": bson.M{"title": post.Title, "link": post.Link, "date": post.Date, "content": post.Content}}
-
-	_, err := col.UpdateOne(ctx.TODO(), filter, update)
+	before, err := c.Store.GetPost(post.ID)
 	if err != nil {
 		return err
 	}
+	if _, err := models.UpdatePostWithRevision(c.Store, post, c.editorName()); err != nil {
+		return err
+	}
+	c.indexPost(post)
+	c.queueEvent(models.PostChangeEvent(before, post), post)
 	return nil
 }
 
@@ -218,13 +268,12 @@ func (c *MainController) CreateTestPost(wr http.ResponseWriter) {
 	c.CreateNewPost(wr)
 }
 
-// CreateNewPost creates new post
+// CreateNewPost creates new draft post, readers see it once it is published
 func (c *MainController) CreateNewPost(wr http.ResponseWriter) (*models.BlogPost, error) {
 	post := &models.BlogPost{}
-	post.Title = "TestPost1"
-	post.Date = "2019-10-01"
-	post.Link = "TestLink"
-	post.Content = "TestContent"
+	post.Title = "Untitled"
+	post.Date = time.Now()
+	post.Status = models.StatusDraft
 	err := c.AddPost(post)
 	if err != nil {
 		err = errors.Wrap(err, "Can not create post")
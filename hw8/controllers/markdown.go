@@ -0,0 +1,21 @@
+package controllers
+
+import (
+	"hw8/markdown"
+
+	"github.com/astaxie/beego"
+)
+
+// contentRenderer renders post content for templates and preview
+var contentRenderer = markdown.NewRenderer(beego.AppConfig.DefaultInt("markdownCache", 500))
+
+func init() {
+	beego.AddFuncMap("markdown", contentRenderer.Render)
+}
+
+// PreviewPost renders Markdown sent by the editor
+func (c *MainController) PreviewPost() {
+	html := contentRenderer.Render(c.Ctx.Request.FormValue("content"))
+	c.Ctx.Output.Header("Content-Type", "text/html; charset=utf-8")
+	c.Ctx.Output.Body([]byte(html))
+}
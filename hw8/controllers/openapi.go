@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"hw8/models"
+	"hw8/openapi"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/astaxie/beego"
+)
+
+// apiSpecPath is where OpenAPI document of the api is served
+const apiSpecPath = "/api/openapi.json"
+
+// apiOperation describes api route for OpenAPI document. Routes
+// registered in routers must match these, tests check they do.
+// Path parameters come from path, security from accessRules.
+type apiOperation struct {
+	method  string
+	path    string
+	handler string
+	summary string
+	params  []openapi.Parameter
+	// body and result are sent json values, nil when there is none
+	body   interface{}
+	status int
+	result interface{}
+	// other responses, those with body are json errors
+	others []int
+}
+
+var apiOperations = []apiOperation{
+	{
+		method: "GET", path: apiPostsPath, handler: "APIListPosts",
+		summary: "List published posts, newest first",
+		params: []openapi.Parameter{
+			{Name: "limit", In: "query", Description: "Posts per page",
+				Schema: &openapi.Schema{Type: "integer", Minimum: intPtr(1), Maximum: intPtr(apiMaxLimit)}},
+			{Name: "cursor", In: "query", Description: "Next or prev cursor of previous page",
+				Schema: &openapi.Schema{Type: "string"}},
+		},
+		status: http.StatusOK, result: apiPostsPage{},
+		others: []int{http.StatusBadRequest},
+	},
+	{
+		method: "POST", path: apiPostsPath, handler: "APICreatePost",
+		summary: "Create post written by current user",
+		body:    apiPostInput{}, status: http.StatusCreated, result: apiPost{},
+		others: []int{http.StatusBadRequest, http.StatusUnprocessableEntity},
+	},
+	{
+		method: "GET", path: apiPostsPath + "/:id", handler: "APIGetPost",
+		summary: "Get post, drafts are sent to their authors and editors",
+		params: []openapi.Parameter{
+			{Name: "If-None-Match", In: "header", Description: "ETag of post client has",
+				Schema: &openapi.Schema{Type: "string"}},
+		},
+		status: http.StatusOK, result: apiPost{},
+		others: []int{http.StatusNotModified, http.StatusNotFound},
+	},
+	{
+		method: "PUT", path: apiPostsPath + "/:id", handler: "APIUpdatePost",
+		summary: "Update post of version client has read",
+		params: []openapi.Parameter{
+			{Name: "If-Match", In: "header", Description: "ETag of post being updated, replaces version field",
+				Schema: &openapi.Schema{Type: "string"}},
+		},
+		body: apiPostInput{}, status: http.StatusOK, result: apiPost{},
+		others: []int{http.StatusBadRequest, http.StatusNotFound, http.StatusConflict,
+			http.StatusPreconditionFailed, http.StatusUnprocessableEntity, http.StatusPreconditionRequired},
+	},
+	{
+		method: "DELETE", path: apiPostsPath + "/:id", handler: "APIDeletePost",
+		summary: "Move post to trash",
+		status:  http.StatusNoContent,
+		others:  []int{http.StatusNotFound},
+	},
+	{
+		method: "POST", path: "/api/v1/session", handler: "APILogin",
+		summary: "Log in and get session token",
+		body:    apiCredentials{}, status: http.StatusCreated, result: apiSession{},
+		others: []int{http.StatusBadRequest, http.StatusUnauthorized},
+	},
+	{
+		method: "DELETE", path: "/api/v1/session", handler: "APILogout",
+		summary: "End session of bearer token",
+		status:  http.StatusNoContent,
+	},
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// newAPISpec generates OpenAPI document of apiOperations,
+// schemas are reflected from api types
+func newAPISpec() *openapi.Document {
+	doc := openapi.New(openapi.Info{
+		Title:   beego.AppConfig.DefaultString("feedTitle", "Blog") + " API",
+		Version: "1",
+	})
+	doc.Servers = []openapi.Server{{URL: baseURL()}}
+	doc.Components.SecuritySchemes = map[string]openapi.SecurityScheme{
+		"bearer": {Type: "http", Scheme: "bearer", Description: "Token from POST /api/v1/session"},
+	}
+
+	errorRef := doc.Define("Error", apiErrorBody{})
+	doc.Define("Post", apiPost{})
+	doc.Define("PostInput", apiPostInput{})
+	doc.Define("PostsPage", apiPostsPage{})
+	doc.Define("Credentials", apiCredentials{})
+	doc.Define("Session", apiSession{})
+	for _, name := range []string{"Post", "PostInput"} {
+		doc.Schema(name).Properties["status"].Enum = models.Statuses
+	}
+
+	for _, api := range apiOperations {
+		op := &openapi.Operation{
+			OperationID: api.handler,
+			Summary:     api.summary,
+			Tags:        []string{strings.Split(strings.TrimPrefix(api.path, "/api/v1/"), "/")[0]},
+			Responses:   map[string]openapi.Response{},
+		}
+		for _, part := range strings.Split(api.path, "/") {
+			if strings.HasPrefix(part, ":") {
+				op.Parameters = append(op.Parameters, openapi.Parameter{
+					Name: part[1:], In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}})
+			}
+		}
+		op.Parameters = append(op.Parameters, api.params...)
+
+		if api.body != nil {
+			op.RequestBody = &openapi.RequestBody{Required: true, Content: jsonContent(doc, api.body)}
+		}
+		op.Responses[strconv.Itoa(api.status)] = openapi.Response{
+			Description: http.StatusText(api.status),
+			Content:     jsonContent(doc, api.result),
+		}
+
+		statuses := append([]int{}, api.others...)
+		if _, ok := accessRules[api.method+" "+api.path]; ok {
+			op.Security = []map[string][]string{{"bearer": {}}}
+			statuses = append(statuses, http.StatusUnauthorized, http.StatusForbidden)
+		}
+		for _, status := range statuses {
+			response := openapi.Response{Description: http.StatusText(status)}
+			if status != http.StatusNotModified {
+				response.Content = map[string]openapi.MediaType{"application/json": {Schema: errorRef}}
+			}
+			op.Responses[strconv.Itoa(status)] = response
+		}
+		doc.Add(api.method, api.path, op)
+	}
+	return doc
+}
+
+// jsonContent is json media of value type, nil for nil value
+func jsonContent(doc *openapi.Document, v interface{}) map[string]openapi.MediaType {
+	if v == nil {
+		return nil
+	}
+	return map[string]openapi.MediaType{"application/json": {Schema: doc.SchemaOf(reflect.TypeOf(v))}}
+}
+
+// ShowAPISpec sends OpenAPI document of the api
+func (c *MainController) ShowAPISpec() {
+	beego.Info("ShowAPISpec")
+	c.sendJSON(http.StatusOK, newAPISpec())
+}
+
+// ShowAPIDocs shows api docs page, it reads OpenAPI document in browser
+func (c *MainController) ShowAPIDocs() {
+	beego.Info("ShowAPIDocs")
+	c.Data["Title"] = "API"
+	c.Data["SpecURL"] = apiSpecPath
+	c.TplName = "apidocs.tpl"
+}
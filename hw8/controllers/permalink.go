@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"hw8/models"
+	"net/http"
+	"time"
+
+	"github.com/astaxie/beego"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	beego.AddFuncMap("postURL", postURL)
+}
+
+// permalinked is post or search hit
+type permalinked interface {
+	Permalink(loc *time.Location) string
+}
+
+// postURL is post permalink in configured timezone
+func postURL(post permalinked) string {
+	return post.Permalink(dates.Zone)
+}
+
+// ReadPostBySlug shows post by permalink, links with another year or month,
+// left from before the post date was changed, are redirected to current one
+func (c *MainController) ReadPostBySlug() {
+	beego.Info("ReadPostBySlug")
+
+	post, err := c.Store.GetPostBySlug(c.Ctx.Input.Param(":slug"))
+	if err == nil && !c.canSee(post) {
+		err = models.ErrPostNotFound
+	}
+	if err != nil {
+		err = errors.Wrap(err, "No post found")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), postErrorStatus(err))
+		beego.Error(err)
+		return
+	}
+
+	if url := postURL(post); url != c.Ctx.Request.URL.Path {
+		c.Redirect(url, http.StatusMovedPermanently)
+		return
+	}
+	beego.Info("Post loaded: %v", post.Title)
+	c.showPost(post)
+}
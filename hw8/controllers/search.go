@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"hw8/models"
+	"hw8/search"
+
+	"github.com/astaxie/beego"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SearchPosts shows posts matching q query
+func (c *MainController) SearchPosts() {
+	beego.Info("SearchPosts")
+
+	query := c.GetString("q")
+	hits := []search.Hit{}
+	if c.Index != nil {
+		hits = c.Index.Search(query, beego.AppConfig.DefaultInt("searchLimit", 50))
+	}
+
+	beego.Info("Found %v posts for %q", len(hits), query)
+
+	c.Data["Title"] = "Search"
+	c.Data["Query"] = query
+	c.Data["Search"] = true
+	c.Data["Posts"] = hits
+	c.TplName = "search.tpl"
+}
+
+// indexPost adds post to search index or updates it there
+func (c *MainController) indexPost(post *models.BlogPost) {
+	if c.Index != nil {
+		c.Index.Add(*post)
+	}
+}
+
+// unindexPost removes post from search index
+func (c *MainController) unindexPost(id primitive.ObjectID) {
+	if c.Index != nil {
+		c.Index.Remove(id)
+	}
+}
+
+// reindexPost reloads post from store into search index and returns it,
+// nil when it can not be loaded
+func (c *MainController) reindexPost(id primitive.ObjectID) *models.BlogPost {
+	post, err := c.Store.GetPost(id)
+	if err != nil {
+		beego.Error("Can not reindex post:", err)
+		return nil
+	}
+	c.indexPost(post)
+	return post
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"hw8/models"
+	"net/http"
+	"time"
+
+	"github.com/astaxie/beego"
+	"github.com/pkg/errors"
+)
+
+// publishAtLayout is format of datetime-local input, in configured timezone
+const publishAtLayout = "2006-01-02T15:04"
+
+// errBadPublishAt is returned when publish time can not be parsed
+var errBadPublishAt = errors.New("Publish time must look like 2006-01-02T15:04")
+
+// readStatus sets post status from form fields status and publish_at,
+// post keeps its stored status when form has none
+func (c *MainController) readStatus(post *models.BlogPost) error {
+	req := c.Ctx.Request
+	status := req.FormValue("status")
+	if status == "" {
+		stored, err := c.Store.GetPost(post.ID)
+		if err != nil {
+			return err
+		}
+		post.Status = stored.Status
+		post.PublishAt = stored.PublishAt
+		return nil
+	}
+
+	var publishAt *time.Time
+	if value := req.FormValue("publish_at"); value != "" {
+		t, err := time.ParseInLocation(publishAtLayout, value, dates.Zone)
+		if err != nil {
+			return errBadPublishAt
+		}
+		publishAt = &t
+	}
+	return post.SetStatus(status, publishAt)
+}
+
+// formErrorStatus maps post form error to http status
+func formErrorStatus(err error) int {
+	switch errors.Cause(err) {
+	case models.ErrBadStatus, models.ErrNoPublishTime, errBadPublishAt, models.ErrBadDate:
+		return http.StatusBadRequest
+	}
+	return postErrorStatus(err)
+}
+
+// canSee tells if current user may read post, unpublished posts
+// are shown only to those who can edit them
+func (c *MainController) canSee(post *models.BlogPost) bool {
+	return post.IsPublished() || c.currentUser().CanEditPost(post)
+}
+
+// ListDrafts shows draft and scheduled posts of current user
+func (c *MainController) ListDrafts() {
+	beego.Info("ListDrafts")
+
+	posts, err := c.Store.ListDrafts(c.currentUser().ID)
+	c.showPostList("My drafts", posts, err)
+}
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"hw8/models"
+	"net/http"
+	"strings"
+
+	"github.com/astaxie/beego"
+	"github.com/pkg/errors"
+)
+
+// maxTagSuggestions limits tag autocomplete list
+const maxTagSuggestions = 10
+
+// ListByTag shows posts having tag
+func (c *MainController) ListByTag() {
+	beego.Info("ListByTag")
+
+	tag := models.NormalizeTag(c.Ctx.Input.Param(":name"))
+	posts, err := c.Store.ListPostsByTag(tag)
+	c.Data["FeedURL"] = "/tag/" + tag + "/" + rssFormat.file
+	c.showPostList("Tag: "+tag, posts, err)
+}
+
+// ListByCategory shows posts in category
+func (c *MainController) ListByCategory() {
+	beego.Info("ListByCategory")
+
+	category := models.NormalizeTag(c.Ctx.Input.Param(":name"))
+	posts, err := c.Store.ListPostsByCategory(category)
+	c.showPostList("Category: "+category, posts, err)
+}
+
+func (c *MainController) showPostList(title string, posts []models.BlogPost, err error) {
+	if err != nil {
+		err = errors.Wrap(err, "Can not load posts")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusInternalServerError)
+		beego.Error(err)
+		return
+	}
+
+	beego.Info("Loaded %v posts", len(posts))
+
+	c.Data["Title"] = title
+	c.Data["Posts"] = posts
+	c.TplName = "list.tpl"
+}
+
+// SuggestTags sends json list of known tags starting with q for tag editor
+func (c *MainController) SuggestTags() {
+	prefix := models.NormalizeTag(c.GetString("q"))
+	tags, err := c.Store.ListTags()
+	if err != nil {
+		err = errors.Wrap(err, "Can not load tags")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusInternalServerError)
+		beego.Error(err)
+		return
+	}
+
+	names := []string{}
+	for _, t := range tags {
+		if strings.HasPrefix(t.Name, prefix) && len(names) < maxTagSuggestions {
+			names = append(names, t.Name)
+		}
+	}
+	c.Data["json"] = names
+	c.ServeJSON()
+}
+
+// tagCloud loads tags for tag cloud, errors only hide the cloud
+func (c *MainController) tagCloud() []models.TagCount {
+	tags, err := c.Store.ListTags()
+	if err != nil {
+		beego.Error(errors.Wrap(err, "Can not load tags"))
+		return nil
+	}
+	return models.TagCloud(tags)
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"hw8/models"
+	"net/http"
+
+	"github.com/astaxie/beego"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DeletePost moves post to trash
+func (c *MainController) DeletePost() {
+	beego.Info("DeletePost")
+
+	c.trashAction("Can not delete post", func(id primitive.ObjectID) error {
+		post, err := c.Store.GetPost(id)
+		if err != nil {
+			return err
+		}
+		if err := c.Store.DeletePost(id); err != nil {
+			return err
+		}
+		c.unindexPost(id)
+		c.queuePublishedEvent(models.EventPostDeleted, post)
+		return nil
+	}, "/")
+}
+
+// ShowTrash shows deleted posts
+func (c *MainController) ShowTrash() {
+	beego.Info("ShowTrash")
+
+	posts, err := c.Store.ListTrash()
+	if err != nil {
+		err = errors.Wrap(err, "Can not load trash")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusInternalServerError)
+		beego.Error(err)
+		return
+	}
+
+	c.Data["Title"] = "Trash"
+	c.Data["Posts"] = posts
+	c.Data["RetentionDays"] = beego.AppConfig.DefaultInt("trashRetentionDays", 30)
+	c.TplName = "trash.tpl"
+}
+
+// RestorePost moves post from trash back
+func (c *MainController) RestorePost() {
+	beego.Info("RestorePost")
+
+	c.trashAction("Can not restore post", func(id primitive.ObjectID) error {
+		if err := c.Store.RestorePost(id); err != nil {
+			return err
+		}
+		c.queuePublishedEvent(models.EventPostRestored, c.reindexPost(id))
+		return nil
+	}, "/trash")
+}
+
+// PurgePost removes post from trash permanently
+func (c *MainController) PurgePost() {
+	beego.Info("PurgePost")
+
+	c.trashAction("Can not purge post", c.Store.PurgePost, "/trash")
+}
+
+func (c *MainController) trashAction(msg string, action func(primitive.ObjectID) error, redirect string) {
+	objID, err := parseObjectID(c.Ctx.Request.FormValue("id"))
+	if err != nil {
+		err = errors.Wrap(err, "Can not parse post id")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusBadRequest)
+		beego.Error(err)
+		return
+	}
+
+	err = action(objID)
+	if err != nil {
+		err = errors.Wrap(err, msg)
+		http.Error(c.Ctx.ResponseWriter, err.Error(), postErrorStatus(err))
+		beego.Error(err)
+		return
+	}
+
+	beego.Info("Trash action done for post:", objID.Hex())
+	c.Redirect(redirect, http.StatusFound)
+}
@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"hw8/models"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// postETag is entity tag of post version
+func postETag(post *models.BlogPost) string {
+	return `"` + strconv.Itoa(post.Version) + `"`
+}
+
+// setPostETag sends post version as ETag and tells
+// if client already has this version from If-None-Match.
+// It is for api clients only, html pages carry session data
+// like CSRF tokens, so they are never answered with 304.
+func (c *MainController) setPostETag(post *models.BlogPost) bool {
+	etag := postETag(post)
+	c.Ctx.Output.Header("ETag", etag)
+	match := c.Ctx.Input.Header("If-None-Match")
+	return match == etag || match == "*"
+}
+
+// expectedVersion reads post version the client has edited,
+// from If-Match header for api clients or from version form field.
+// If-Match: * matches any version, so the stored one is expected.
+func (c *MainController) expectedVersion(id primitive.ObjectID) (version int, fromHeader bool, err error) {
+	if match := c.Ctx.Input.Header("If-Match"); match == "*" {
+		current, err := c.Store.GetPost(id)
+		if err != nil {
+			return 0, true, errors.Wrap(err, "No post matches If-Match: *")
+		}
+		return current.Version, true, nil
+	} else if match != "" {
+		v := strings.Trim(strings.TrimPrefix(match, "W/"), `"`)
+		version, err = strconv.Atoi(v)
+		if err != nil {
+			return 0, true, errors.Wrapf(err, "Can not parse If-Match: %v", match)
+		}
+		return version, true, nil
+	}
+
+	v := c.Ctx.Request.FormValue("version")
+	if v == "" {
+		return 0, false, errors.New("Post version is required")
+	}
+	version, err = strconv.Atoi(v)
+	if err != nil {
+		return 0, false, errors.Wrapf(err, "Can not parse version: %v", v)
+	}
+	return version, false, nil
+}
+
+// showConflict shows user edit next to the stored post so they can merge
+func (c *MainController) showConflict(mine *models.BlogPost) {
+	current, err := c.Store.GetPost(mine.ID)
+	if err != nil {
+		err = errors.Wrap(err, "No post found")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	c.Ctx.Output.Header("ETag", postETag(current))
+	c.Data["Title"] = current.Title
+	c.Data["Mine"] = mine
+	c.Data["Current"] = current
+	c.TplName = "conflict.tpl"
+	c.renderStatus(http.StatusConflict)
+}
@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"hw8/models"
+	"net/http"
+
+	"github.com/astaxie/beego"
+	"github.com/pkg/errors"
+)
+
+// queueEvent queues webhook deliveries of post event, empty event
+// is not sent. Failing to queue is logged and does not fail the action.
+func (c *MainController) queueEvent(event string, post *models.BlogPost) {
+	if event == "" {
+		return
+	}
+	if err := models.QueueEvent(c.Store, event, newAPIPost(post)); err != nil {
+		beego.Error(errors.Wrap(err, "Can not queue webhook event "+event))
+	}
+}
+
+// queuePublishedEvent queues event of post readers can see, others are private
+func (c *MainController) queuePublishedEvent(event string, post *models.BlogPost) {
+	if post != nil && post.IsPublished() {
+		c.queueEvent(event, post)
+	}
+}
+
+// Published indexes post published by the scheduler and notifies webhooks
+func (c *MainController) Published(post models.BlogPost) {
+	c.indexPost(&post)
+	c.queueEvent(models.EventPostPublished, &post)
+}
+
+// webhookError sends webhook store error with its status
+func (c *MainController) webhookError(err error, msg string) {
+	status := http.StatusInternalServerError
+	switch errors.Cause(err) {
+	case models.ErrWebhookNotFound, models.ErrDeliveryNotFound:
+		status = http.StatusNotFound
+	case models.ErrBadWebhook:
+		status = http.StatusBadRequest
+	}
+	err = errors.Wrap(err, msg)
+	http.Error(c.Ctx.ResponseWriter, err.Error(), status)
+	beego.Error(err)
+}
+
+// ListWebhooks shows webhooks with form adding new one
+func (c *MainController) ListWebhooks() {
+	beego.Info("ListWebhooks")
+
+	hooks, err := c.Store.ListWebhooks()
+	if err != nil {
+		c.webhookError(err, "Can not load webhooks")
+		return
+	}
+
+	c.Data["Title"] = "Webhooks"
+	c.Data["Webhooks"] = hooks
+	c.Data["Events"] = models.WebhookEvents
+	c.TplName = "webhooks.tpl"
+}
+
+// AddWebhook registers webhook url for checked events
+func (c *MainController) AddWebhook() {
+	beego.Info("AddWebhook")
+
+	req := c.Ctx.Request
+	req.ParseForm()
+	hook, err := models.AddWebhook(c.Store, req.FormValue("url"), req.Form["events"])
+	if err != nil {
+		c.webhookError(err, "Can not add webhook")
+		return
+	}
+
+	beego.Info("Added webhook:", hook.URL)
+	c.Redirect("/admin/webhooks", http.StatusSeeOther)
+}
+
+// DeleteWebhook removes webhook with its delivery log
+func (c *MainController) DeleteWebhook() {
+	beego.Info("DeleteWebhook")
+
+	id, err := parseObjectID(c.Ctx.Request.FormValue("id"))
+	if err != nil {
+		err = errors.Wrap(err, "Can not parse webhook id")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusBadRequest)
+		beego.Error(err)
+		return
+	}
+	if err = c.Store.DeleteWebhook(id); err != nil {
+		c.webhookError(err, "Can not delete webhook")
+		return
+	}
+
+	beego.Info("Deleted webhook:", id.Hex())
+	c.Redirect("/admin/webhooks", http.StatusSeeOther)
+}
+
+// ShowDeliveries shows latest deliveries of webhook
+func (c *MainController) ShowDeliveries() {
+	beego.Info("ShowDeliveries")
+
+	id, err := parseObjectID(c.Ctx.Request.FormValue("id"))
+	if err != nil {
+		err = errors.Wrap(err, "Can not parse webhook id")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusBadRequest)
+		beego.Error(err)
+		return
+	}
+	hook, err := c.Store.GetWebhook(id)
+	if err != nil {
+		c.webhookError(err, "Can not load webhook")
+		return
+	}
+	deliveries, err := c.Store.ListDeliveries(id, beego.AppConfig.DefaultInt("webhookLogSize", 50))
+	if err != nil {
+		c.webhookError(err, "Can not load deliveries")
+		return
+	}
+
+	c.Data["Title"] = "Deliveries to " + hook.URL
+	c.Data["Webhook"] = hook
+	c.Data["Deliveries"] = deliveries
+	c.TplName = "deliveries.tpl"
+}
+
+// RedeliverWebhook queues delivery again with the same payload
+func (c *MainController) RedeliverWebhook() {
+	beego.Info("RedeliverWebhook")
+
+	id, err := parseObjectID(c.Ctx.Request.FormValue("id"))
+	if err != nil {
+		err = errors.Wrap(err, "Can not parse delivery id")
+		http.Error(c.Ctx.ResponseWriter, err.Error(), http.StatusBadRequest)
+		beego.Error(err)
+		return
+	}
+	d, err := models.Redeliver(c.Store, id)
+	if err != nil {
+		c.webhookError(err, "Can not redeliver")
+		return
+	}
+
+	beego.Info("Queued redelivery of:", id.Hex())
+	c.Redirect("/admin/webhooks/deliveries?id="+d.WebhookID.Hex(), http.StatusSeeOther)
+}
@@ -0,0 +1,116 @@
+package datefmt
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultLocale is used when reader asks for no supported locale
+const DefaultLocale = "en"
+
+// locale knows how to spell dates in one language,
+// months are used inside dates and monthTitles alone
+type locale struct {
+	months      [12]string
+	monthTitles [12]string
+	date        func(l *locale, t time.Time) string
+	dateTime    func(l *locale, t time.Time) string
+}
+
+var englishMonths = [12]string{"January", "February", "March", "April", "May", "June",
+	"July", "August", "September", "October", "November", "December"}
+
+var locales = map[string]*locale{
+	"en": {
+		months:      englishMonths,
+		monthTitles: englishMonths,
+		date: func(l *locale, t time.Time) string {
+			return fmt.Sprintf("%v %d, %d", l.months[t.Month()-1], t.Day(), t.Year())
+		},
+		dateTime: func(l *locale, t time.Time) string {
+			return fmt.Sprintf("%v %d, %d %v", l.months[t.Month()-1], t.Day(), t.Year(), t.Format("3:04 PM"))
+		},
+	},
+	"ru": {
+		// month follows the day in genitive case
+		months: [12]string{"января", "февраля", "марта", "апреля", "мая", "июня",
+			"июля", "августа", "сентября", "октября", "ноября", "декабря"},
+		monthTitles: [12]string{"Январь", "Февраль", "Март", "Апрель", "Май", "Июнь",
+			"Июль", "Август", "Сентябрь", "Октябрь", "Ноябрь", "Декабрь"},
+		date: func(l *locale, t time.Time) string {
+			return fmt.Sprintf("%d %v %d", t.Day(), l.months[t.Month()-1], t.Year())
+		},
+		dateTime: func(l *locale, t time.Time) string {
+			return fmt.Sprintf("%d %v %d, %v", t.Day(), l.months[t.Month()-1], t.Year(), t.Format("15:04"))
+		},
+	},
+}
+
+// Formatter shows times in one time zone
+type Formatter struct {
+	Zone *time.Location
+}
+
+// NewFormatter makes formatter for time zone name like Europe/Moscow,
+// empty name or Local is the server zone
+func NewFormatter(zone string) (*Formatter, error) {
+	if zone == "" {
+		zone = "Local"
+	}
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return nil, err
+	}
+	return &Formatter{Zone: loc}, nil
+}
+
+func find(name string) *locale {
+	if l, ok := locales[name]; ok {
+		return l
+	}
+	return locales[DefaultLocale]
+}
+
+// Date spells date in locale, zero time is empty
+func (f *Formatter) Date(t time.Time, localeName string) string {
+	if t.IsZero() {
+		return ""
+	}
+	l := find(localeName)
+	return l.date(l, t.In(f.Zone))
+}
+
+// DateTime spells date and time of day in locale, zero time is empty
+func (f *Formatter) DateTime(t time.Time, localeName string) string {
+	if t.IsZero() {
+		return ""
+	}
+	l := find(localeName)
+	return l.dateTime(l, t.In(f.Zone))
+}
+
+// Month spells month and year in locale, used for archive titles
+func (f *Formatter) Month(year int, month time.Month, localeName string) string {
+	return fmt.Sprintf("%v %d", find(localeName).monthTitles[month-1], year)
+}
+
+// Input formats time for date or datetime-local form inputs
+func (f *Formatter) Input(t time.Time, layout string) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(f.Zone).Format(layout)
+}
+
+// Locale picks the first supported locale of Accept-Language header
+func Locale(acceptLanguage string) string {
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		tag = strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+		if _, ok := locales[tag]; ok {
+			return tag
+		}
+	}
+	return DefaultLocale
+}
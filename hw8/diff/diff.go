@@ -0,0 +1,85 @@
+package diff
+
+import "strings"
+
+// Op is a kind of diff line
+type Op int
+
+// Diff line kinds
+const (
+	Equal Op = iota
+	Insert
+	Delete
+)
+
+// Line is one line of diff
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// IsInsert tells if line was added
+func (l Line) IsInsert() bool {
+	return l.Op == Insert
+}
+
+// IsDelete tells if line was removed
+func (l Line) IsDelete() bool {
+	return l.Op == Delete
+}
+
+// Lines returns line level diff turning a into b,
+// built from the longest common subsequence of lines
+func Lines(a, b string) []Line {
+	x := splitLines(a)
+	y := splitLines(b)
+
+	// lcs[i][j] is the common subsequence length of x[i:] and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	r := make([]Line, 0, len(x)+len(y))
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			r = append(r, Line{Equal, x[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			r = append(r, Line{Delete, x[i]})
+			i++
+		default:
+			r = append(r, Line{Insert, y[j]})
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		r = append(r, Line{Delete, x[i]})
+	}
+	for ; j < len(y); j++ {
+		r = append(r, Line{Insert, y[j]})
+	}
+	return r
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	s = strings.Replace(s, "\r\n", "\n", -1)
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
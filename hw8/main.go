@@ -1,8 +1,9 @@
 package main
 
 import (
-	_ "hw8/routers"
+	"hw8/routers"
 	"log"
+	"net"
 
 	"github.com/astaxie/beego"
 	"github.com/astaxie/beego/logs"
@@ -17,6 +18,19 @@ func main() {
 		}
 	}
 
+	if grpcAddr := beego.AppConfig.String("grpcAddr"); grpcAddr != "" {
+		lis, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			beego.Critical(err)
+			log.Fatal(err)
+		}
+		go func() {
+			if err := routers.ServeGRPC(lis); err != nil {
+				beego.Error(err)
+			}
+		}()
+	}
+
 	beego.Info("Starting blog server")
 	beego.Run()
 }
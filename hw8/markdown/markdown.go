@@ -0,0 +1,133 @@
+package markdown
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	stdhtml "html"
+	"html/template"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// Renderer turns post Markdown into sanitized HTML and keeps
+// recently rendered texts in memory
+type Renderer struct {
+	md     goldmark.Markdown
+	policy *bluemonday.Policy
+
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	cache map[[sha256.Size]byte]*list.Element
+}
+
+type cacheEntry struct {
+	key  [sha256.Size]byte
+	html template.HTML
+}
+
+// NewRenderer makes renderer of CommonMark with tables and autolinks,
+// cacheSize is the number of rendered texts kept
+func NewRenderer(cacheSize int) *Renderer {
+	return &Renderer{
+		md: goldmark.New(
+			goldmark.WithExtensions(
+				extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute)),
+				extension.Linkify,
+				extension.Strikethrough,
+			),
+			// raw html is kept here and cleaned by the sanitizer
+			goldmark.WithRendererOptions(html.WithUnsafe()),
+		),
+		policy: newPolicy(),
+		size:   cacheSize,
+		order:  list.New(),
+		cache:  make(map[[sha256.Size]byte]*list.Element),
+	}
+}
+
+// newPolicy allows user content tags, fenced code language classes
+// and table alignment, links get rel=nofollow
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+-]+$`)).OnElements("code")
+	p.AllowAttrs("align").Matching(regexp.MustCompile(`^(left|center|right)$`)).OnElements("th", "td")
+	p.RequireNoFollowOnLinks(true)
+	return p
+}
+
+// textPolicy drops all tags leaving escaped text
+var textPolicy = bluemonday.StrictPolicy()
+
+// Summary is plain text of Markdown source cut to words,
+// cut texts end with ellipsis
+func (r *Renderer) Summary(src string, words int) string {
+	text := stdhtml.UnescapeString(textPolicy.Sanitize(string(r.Render(src))))
+	fields := strings.Fields(text)
+	if len(fields) <= words {
+		return strings.Join(fields, " ")
+	}
+	return strings.Join(fields[:words], " ") + " …"
+}
+
+// Render converts Markdown source to safe HTML
+func (r *Renderer) Render(src string) template.HTML {
+	key := sha256.Sum256([]byte(src))
+	if out, ok := r.cached(key); ok {
+		return out
+	}
+
+	buf := &bytes.Buffer{}
+	if err := r.md.Convert([]byte(src), buf); err != nil {
+		// goldmark only fails on writer errors, show text as is
+		return template.HTML(template.HTMLEscapeString(src))
+	}
+	out := template.HTML(r.policy.SanitizeBytes(buf.Bytes()))
+	r.store(key, out)
+	return out
+}
+
+func (r *Renderer) cached(key [sha256.Size]byte) (template.HTML, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.cache[key]
+	if !ok {
+		return "", false
+	}
+	r.order.MoveToFront(e)
+	return e.Value.(*cacheEntry).html, true
+}
+
+func (r *Renderer) store(key [sha256.Size]byte, out template.HTML) {
+	if r.size <= 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if e, ok := r.cache[key]; ok {
+		r.order.MoveToFront(e)
+		return
+	}
+	r.cache[key] = r.order.PushFront(&cacheEntry{key: key, html: out})
+	for r.order.Len() > r.size {
+		oldest := r.order.Back()
+		r.order.Remove(oldest)
+		delete(r.cache, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// Cached is the number of rendered texts in cache
+func (r *Renderer) Cached() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.order.Len()
+}
@@ -0,0 +1,204 @@
+package migrations
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Migration is one versioned schema change
+type Migration struct {
+	Version int
+	Name    string
+	Up      []string
+	Down    []string
+}
+
+// Checksum identifies migration content, applied migrations must not change
+func (m Migration) Checksum() string {
+	h := sha256.New()
+	h.Write([]byte(strings.Join(m.Up, ";\n")))
+	h.Write([]byte{0})
+	h.Write([]byte(strings.Join(m.Down, ";\n")))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Status is migration state in database
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator applies migrations to database
+type Migrator struct {
+	DB         *sql.DB
+	Migrations []Migration
+}
+
+// NewMigrator creates migrator for all known migrations
+func NewMigrator(db *sql.DB) *Migrator {
+	return &Migrator{DB: db, Migrations: All}
+}
+
+type appliedMigration struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+// Validate checks that migrations are ordered by unique versions
+func Validate(list []Migration) error {
+	for i, m := range list {
+		if m.Version <= 0 {
+			return errors.Errorf("Migration %v has invalid version", m.Name)
+		}
+		if i > 0 && m.Version <= list[i-1].Version {
+			return errors.Errorf("Migration %v is out of order", m.Version)
+		}
+		if len(m.Up) == 0 || len(m.Down) == 0 {
+			return errors.Errorf("Migration %v needs up and down statements", m.Version)
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) init() error {
+	if err := Validate(m.Migrations); err != nil {
+		return err
+	}
+	_, err := m.DB.Exec(`create table if not exists schema_migrations (
+		version int not null primary key,
+		name varchar(255) not null,
+		checksum char(64) not null,
+		applied_at datetime not null default current_timestamp)`)
+	return err
+}
+
+func (m *Migrator) applied() (map[int]appliedMigration, error) {
+	rows, err := m.DB.Query("select version, checksum, date_format(applied_at, '%Y-%m-%d %H:%i:%s') from schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	r := make(map[int]appliedMigration)
+	for rows.Next() {
+		var version int
+		var a appliedMigration
+		var appliedAt string
+		if err := rows.Scan(&version, &a.checksum, &appliedAt); err != nil {
+			return nil, err
+		}
+		a.appliedAt, _ = time.Parse("2006-01-02 15:04:05", appliedAt)
+		r[version] = a
+	}
+	return r, rows.Err()
+}
+
+// Status returns state of every known migration and fails
+// if an applied migration was changed or is unknown
+func (m *Migrator) Status() ([]Status, error) {
+	if err := m.init(); err != nil {
+		return nil, err
+	}
+
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	r := make([]Status, 0, len(m.Migrations))
+	for _, mig := range m.Migrations {
+		s := Status{Migration: mig}
+		if a, ok := applied[mig.Version]; ok {
+			if a.checksum != mig.Checksum() {
+				return nil, errors.Errorf("Migration %v was changed after it was applied", mig.Version)
+			}
+			s.Applied = true
+			s.AppliedAt = a.appliedAt
+			delete(applied, mig.Version)
+		}
+		r = append(r, s)
+	}
+
+	for version := range applied {
+		return nil, errors.Errorf("Database has unknown migration %v", version)
+	}
+	return r, nil
+}
+
+// Up applies pending migrations up to version, 0 means all
+func (m *Migrator) Up(to int) ([]Migration, error) {
+	status, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	for _, s := range status {
+		if s.Applied {
+			continue
+		}
+		if to > 0 && s.Version > to {
+			break
+		}
+		if err := m.run(s.Migration, s.Up, true); err != nil {
+			return done, errors.Wrapf(err, "Can not apply migration %v", s.Version)
+		}
+		done = append(done, s.Migration)
+	}
+	return done, nil
+}
+
+// Down rolls back last applied migrations
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	status, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	done := []Migration{}
+	for i := len(status) - 1; i >= 0 && len(done) < steps; i-- {
+		s := status[i]
+		if !s.Applied {
+			continue
+		}
+		if err := m.run(s.Migration, s.Down, false); err != nil {
+			return done, errors.Wrapf(err, "Can not roll back migration %v", s.Version)
+		}
+		done = append(done, s.Migration)
+	}
+	return done, nil
+}
+
+// run executes statements in transaction, mysql commits ddl implicitly,
+// so a failed migration may need manual cleanup before retry
+func (m *Migrator) run(mig Migration, statements []string, up bool) error {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if up {
+		_, err = tx.Exec("insert into schema_migrations (version, name, checksum) values (?, ?, ?)",
+			mig.Version, mig.Name, mig.Checksum())
+	} else {
+		_, err = tx.Exec("delete from schema_migrations where version = ?", mig.Version)
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,228 @@
+package migrations
+
+// All is the ordered list of mysql schema migrations.
+// Never edit applied migrations, add a new one instead.
+var All = []Migration{
+	{
+		Version: 1,
+		Name:    "create posts",
+		Up: []string{
+			`create table if not exists posts (
+				id char(24) not null,
+				title text not null,
+				postdate varchar(64) not null,
+				link text not null,
+				content text not null,
+				primary key (id))`,
+		},
+		Down: []string{
+			"drop table posts",
+		},
+	},
+	{
+		Version: 2,
+		Name:    "add posts trash",
+		Up: []string{
+			"alter table posts add column deleted_at datetime null",
+			"create index posts_deleted_at on posts (deleted_at)",
+		},
+		Down: []string{
+			"drop index posts_deleted_at on posts",
+			"alter table posts drop column deleted_at",
+		},
+	},
+	{
+		Version: 3,
+		Name:    "create post revisions",
+		Up: []string{
+			`create table post_revisions (
+				post_id char(24) not null,
+				number int not null,
+				title text not null,
+				postdate varchar(64) not null,
+				link text not null,
+				content text not null,
+				editor varchar(255) not null,
+				restored_from int null,
+				created_at datetime not null,
+				primary key (post_id, number))`,
+		},
+		Down: []string{
+			"drop table post_revisions",
+		},
+	},
+	{
+		Version: 4,
+		Name:    "add posts version",
+		Up: []string{
+			"alter table posts add column version int not null default 0",
+		},
+		Down: []string{
+			"alter table posts drop column version",
+		},
+	},
+	{
+		Version: 5,
+		Name:    "add posts tags and category",
+		Up: []string{
+			"alter table posts add column category varchar(64) not null default ''",
+			"create index posts_category on posts (category, id)",
+			`create table if not exists post_tags (
+				post_id char(24) not null,
+				tag varchar(64) not null,
+				primary key (post_id, tag),
+				index post_tags_tag (tag, post_id))`,
+		},
+		Down: []string{
+			"drop table post_tags",
+			"drop index posts_category on posts",
+			"alter table posts drop column category",
+		},
+	},
+	{
+		Version: 6,
+		Name:    "create post comments",
+		Up: []string{
+			`create table if not exists post_comments (
+				id char(24) not null,
+				post_id char(24) not null,
+				parent_id char(24) null,
+				author varchar(64) not null,
+				text text not null,
+				created_at datetime not null,
+				primary key (id),
+				index post_comments_post (post_id, id))`,
+		},
+		Down: []string{
+			"drop table post_comments",
+		},
+	},
+	{
+		Version: 7,
+		Name:    "create users and sessions",
+		Up: []string{
+			`create table if not exists users (
+				id char(24) not null,
+				name varchar(32) not null,
+				password_hash varbinary(60) not null,
+				created_at datetime not null,
+				primary key (id),
+				unique index users_name (name))`,
+			`create table if not exists sessions (
+				token_hash char(64) not null,
+				user_id char(24) not null,
+				expires_at datetime not null,
+				primary key (token_hash),
+				index sessions_expires_at (expires_at))`,
+		},
+		Down: []string{
+			"drop table sessions",
+			"drop table users",
+		},
+	},
+	{
+		Version: 8,
+		Name:    "add user roles and post authors",
+		Up: []string{
+			"alter table users add column role varchar(16) not null default 'author'",
+			"alter table posts add column author_id char(24) null",
+		},
+		Down: []string{
+			"alter table posts drop column author_id",
+			"alter table users drop column role",
+		},
+	},
+	{
+		Version: 9,
+		Name:    "add post status",
+		Up: []string{
+			// existing posts stay visible
+			"alter table posts add column status varchar(16) not null default 'published'",
+			"alter table posts add column publish_at datetime null",
+			"create index posts_status on posts (status, publish_at)",
+			"create index posts_author on posts (author_id, id)",
+		},
+		Down: []string{
+			"drop index posts_author on posts",
+			"drop index posts_status on posts",
+			"alter table posts drop column publish_at",
+			"alter table posts drop column status",
+		},
+	},
+	{
+		Version: 10,
+		Name:    "use timestamps for post dates",
+		Up: []string{
+			"alter table posts add column post_date datetime null, add column created_at datetime null, add column updated_at datetime null",
+			// only well formed dates are copied, the rest take creation time below
+			`update posts set post_date = cast(postdate as datetime)
+				where postdate regexp '^[0-9]{4}-(0[1-9]|1[0-2])-(0[1-9]|[12][0-9]|3[01])'`,
+			// object ids start with creation unix time in hex
+			"update posts set created_at = from_unixtime(conv(left(id, 8), 16, 10)), updated_at = from_unixtime(conv(left(id, 8), 16, 10))",
+			"update posts set post_date = created_at where post_date is null",
+			"alter table posts modify post_date datetime not null, modify created_at datetime not null, modify updated_at datetime not null",
+			"alter table posts drop column postdate",
+			"create index posts_post_date on posts (post_date, id)",
+			"alter table post_revisions add column post_date datetime null",
+			`update post_revisions set post_date = cast(postdate as datetime)
+				where postdate regexp '^[0-9]{4}-(0[1-9]|1[0-2])-(0[1-9]|[12][0-9]|3[01])'`,
+			"update post_revisions set post_date = created_at where post_date is null",
+			"alter table post_revisions modify post_date datetime not null",
+			"alter table post_revisions drop column postdate",
+		},
+		Down: []string{
+			"alter table post_revisions add column postdate varchar(64) not null default ''",
+			"update post_revisions set postdate = date_format(post_date, '%Y-%m-%d')",
+			"alter table post_revisions drop column post_date",
+			"drop index posts_post_date on posts",
+			"alter table posts add column postdate varchar(64) not null default ''",
+			"update posts set postdate = date_format(post_date, '%Y-%m-%d')",
+			"alter table posts drop column updated_at, drop column created_at, drop column post_date",
+		},
+	},
+	{
+		Version: 11,
+		Name:    "add post slugs",
+		Up: []string{
+			// posts created before slugs have null, which the unique index allows many times
+			"alter table posts add column slug varchar(191) null",
+			"create unique index posts_slug on posts (slug)",
+		},
+		Down: []string{
+			"drop index posts_slug on posts",
+			"alter table posts drop column slug",
+		},
+	},
+	{
+		Version: 12,
+		Name:    "create webhooks and deliveries",
+		Up: []string{
+			`create table if not exists webhooks (
+				id char(24) not null,
+				url varchar(2048) not null,
+				secret varchar(64) not null,
+				events varchar(255) not null,
+				created_at datetime not null,
+				primary key (id))`,
+			`create table if not exists webhook_deliveries (
+				id char(24) not null,
+				webhook_id char(24) not null,
+				event varchar(64) not null,
+				payload mediumtext not null,
+				status varchar(16) not null,
+				attempts int not null,
+				response_status int not null,
+				last_error text not null,
+				next_attempt_at datetime not null,
+				created_at datetime not null,
+				delivered_at datetime null,
+				primary key (id),
+				index webhook_deliveries_due (status, next_attempt_at),
+				index webhook_deliveries_webhook (webhook_id, id))`,
+		},
+		Down: []string{
+			"drop table webhook_deliveries",
+			"drop table webhooks",
+		},
+	},
+}
@@ -1,14 +1,65 @@
 package models
 
 import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// BlogPost model
+// BlogPost model, Date is the date shown to readers,
+// CreatedAt and UpdatedAt are set by the store.
+// Slug is unique permalink part made from title by the store.
 type BlogPost struct {
-	ID      primitive.ObjectID `bson:"_id,omitempty"`
-	Title   string
-	Date    string
-	Link    string
-	Content string
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Title     string
+	Slug      string `bson:",omitempty"`
+	Date      time.Time
+	Link      string
+	Content   string
+	Tags      []string           `bson:",omitempty"`
+	Category  string             `bson:",omitempty"`
+	AuthorID  primitive.ObjectID `bson:",omitempty"`
+	Status    string             `bson:",omitempty"`
+	PublishAt *time.Time         `bson:",omitempty"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Version   int
+	DeletedAt *time.Time `bson:",omitempty"`
+}
+
+// IsDeleted tells if post is in trash
+func (p *BlogPost) IsDeleted() bool {
+	return p.DeletedAt != nil
+}
+
+// bsonPost has BlogPost fields without its bson hooks
+type bsonPost BlogPost
+
+// UnmarshalBSON reads post, posts stored before timestamps existed
+// have string dates and no created time, those are filled in here
+func (p *BlogPost) UnmarshalBSON(data []byte) error {
+	data, err := upgradeDate(data)
+	if err != nil {
+		return err
+	}
+	if err := bson.Unmarshal(data, (*bsonPost)(p)); err != nil {
+		return err
+	}
+	if p.CreatedAt.IsZero() && !p.ID.IsZero() {
+		p.CreatedAt = p.ID.Timestamp()
+	}
+	if p.UpdatedAt.IsZero() {
+		p.UpdatedAt = p.CreatedAt
+	}
+	p.defaultDate(p.CreatedAt)
+	return nil
+}
+
+// defaultDate dates post without date by its creation time, stores call it
+// when saving so they keep the date UnmarshalBSON reads back
+func (p *BlogPost) defaultDate(created time.Time) {
+	if p.Date.IsZero() {
+		p.Date = created
+	}
 }
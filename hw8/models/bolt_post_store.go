@@ -0,0 +1,1052 @@
+package models
+
+import (
+	"bytes"
+	"common/boltstore"
+	"encoding/binary"
+	"math"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	postsBucket     = []byte("posts")
+	revisionsBucket = []byte("revisions")
+	commentsBucket  = []byte("comments")
+	usersBucket     = []byte("users")
+	userNamesBucket = []byte("usernames")
+	sessionsBucket  = []byte("sessions")
+	slugsBucket     = []byte("slugs")
+	webhooksBucket  = []byte("webhooks")
+	// deliveries are keyed by id, so the queue is read in creation order
+	deliveriesBucket = []byte("deliveries")
+	// post dates are keyed by post date and id with empty values,
+	// so pages are read in date order without loading every post
+	postDatesBucket = []byte("postdates")
+)
+
+// BoltPostStore keeps posts in a single embedded bolt data file.
+// Posts are stored as bson documents keyed by object id bytes,
+// so iteration order is creation order like in mongo.
+type BoltPostStore struct {
+	DB *bbolt.DB
+}
+
+// NewBoltPostStore opens or creates data file at path,
+// it fails after boltstore.OpenTimeout if another process holds the file
+func NewBoltPostStore(path string) (*BoltPostStore, error) {
+	db, err := boltstore.Open(path, postsBucket, postDatesBucket, revisionsBucket, commentsBucket,
+		usersBucket, userNamesBucket, sessionsBucket, slugsBucket, webhooksBucket, deliveriesBucket)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Update(indexPostDates); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &BoltPostStore{DB: db}, nil
+}
+
+// indexPostDates fills post dates of data files written before the
+// bucket existed, an index that has any key is kept as it is
+func indexPostDates(tx *bbolt.Tx) error {
+	dates := tx.Bucket(postDatesBucket)
+	if k, _ := dates.Cursor().First(); k != nil {
+		return nil
+	}
+	return tx.Bucket(postsBucket).ForEach(func(k, v []byte) error {
+		post := BlogPost{}
+		if err := bson.Unmarshal(v, &post); err != nil {
+			return err
+		}
+		copy(post.ID[:], k)
+		return dates.Put(postDateKey(post.Date, post.ID), []byte{})
+	})
+}
+
+// postDateKey is post dates bucket key, dates are cut to milliseconds
+// like bson stores them so keys of stored and new posts agree
+func postDateKey(date time.Time, id primitive.ObjectID) []byte {
+	return dateIDKey(date.Truncate(time.Millisecond), id)
+}
+
+// Close closes data file
+func (s *BoltPostStore) Close() error {
+	return s.DB.Close()
+}
+
+func (s *BoltPostStore) filter(deleted bool) ([]BlogPost, error) {
+	posts := []BlogPost{}
+	err := s.DB.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(postsBucket).ForEach(func(k, v []byte) error {
+			post := BlogPost{}
+			if err := bson.Unmarshal(v, &post); err != nil {
+				return err
+			}
+			if post.IsDeleted() == deleted {
+				posts = append(posts, post)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// ListPosts gets all posts
+func (s *BoltPostStore) ListPosts() ([]BlogPost, error) {
+	return s.filter(false)
+}
+
+// ListPostsByTag gets posts having tag
+func (s *BoltPostStore) ListPostsByTag(tag string) ([]BlogPost, error) {
+	posts, err := s.filter(false)
+	if err != nil {
+		return nil, err
+	}
+	return sortByDate(selectPosts(posts, func(p *BlogPost) bool {
+		return p.IsPublished() && p.HasTag(tag)
+	})), nil
+}
+
+// ListPostsByCategory gets posts in category
+func (s *BoltPostStore) ListPostsByCategory(category string) ([]BlogPost, error) {
+	posts, err := s.filter(false)
+	if err != nil {
+		return nil, err
+	}
+	return sortByDate(selectPosts(posts, func(p *BlogPost) bool {
+		return p.IsPublished() && p.Category == category
+	})), nil
+}
+
+// ListPostsBetween gets posts dated in [from, to)
+func (s *BoltPostStore) ListPostsBetween(from, to time.Time) ([]BlogPost, error) {
+	posts, err := s.filter(false)
+	if err != nil {
+		return nil, err
+	}
+	return datedBetween(selectPosts(posts, (*BlogPost).IsPublished), from, to), nil
+}
+
+// ListTags gets tags with post counts
+func (s *BoltPostStore) ListTags() ([]TagCount, error) {
+	posts, err := s.filter(false)
+	if err != nil {
+		return nil, err
+	}
+	return countTags(selectPosts(posts, (*BlogPost).IsPublished)), nil
+}
+
+// ListDrafts gets unpublished posts of author
+func (s *BoltPostStore) ListDrafts(authorID primitive.ObjectID) ([]BlogPost, error) {
+	posts, err := s.filter(false)
+	if err != nil {
+		return nil, err
+	}
+	return draftsOf(posts, authorID), nil
+}
+
+// PublishDue publishes scheduled posts whose time has come
+func (s *BoltPostStore) PublishDue(now time.Time) ([]BlogPost, error) {
+	published := []BlogPost{}
+	err := s.DB.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(postsBucket)
+		err := b.ForEach(func(k, v []byte) error {
+			post := BlogPost{}
+			if err := bson.Unmarshal(v, &post); err != nil {
+				return err
+			}
+			if !post.IsDeleted() && post.isDue(now) {
+				published = append(published, post)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// changing bucket while iterating is not allowed, so write afterwards
+		for i := range published {
+			published[i].Status = StatusPublished
+			published[i].Version++
+			published[i].UpdatedAt = now
+			if err := putPost(b, &published[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return published, nil
+}
+
+// ListPostsPage gets page of posts matching filter newest first,
+// post dates keys are ordered like pages so the cursor seeks straight to the page
+// and the walk stops once keys leave filter dates
+func (s *BoltPostStore) ListPostsPage(filter PostFilter, cursor Cursor, limit int) ([]BlogPost, error) {
+	page := []BlogPost{}
+	key := postDateKey(cursor.Date, cursor.ID)
+	if cursor.IsZero() && !filter.To.IsZero() {
+		// one millisecond more keeps posts truncated down to filter end
+		key = postDateKey(filter.To.Add(time.Millisecond), primitive.ObjectID{})
+	}
+	err := s.DB.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(postDatesBucket).Cursor()
+		posts := tx.Bucket(postsBucket)
+		var k []byte
+		var next func() ([]byte, []byte)
+		switch {
+		case cursor.IsZero() && filter.To.IsZero():
+			k, _ = c.Last()
+			next = c.Prev
+		case cursor.Before:
+			k, _ = c.Seek(key)
+			if bytes.Equal(k, key) {
+				k, _ = c.Next()
+			}
+			next = c.Next
+		default:
+			k, _ = c.Seek(key)
+			if k == nil {
+				k, _ = c.Last()
+			} else {
+				k, _ = c.Prev()
+			}
+			next = c.Prev
+		}
+
+		// keys keep dates in milliseconds, so they are compared with
+		// truncated bounds
+		from := filter.From.Truncate(time.Millisecond)
+		for ; k != nil && len(page) < limit; k, _ = next() {
+			date, id := parseDateIDKey(k)
+			if cursor.Before && !filter.To.IsZero() && !date.Before(filter.To) ||
+				!cursor.Before && !filter.From.IsZero() && date.Before(from) {
+				break
+			}
+			post := BlogPost{}
+			if err := getPost(posts, id, &post); err != nil {
+				return err
+			}
+			if post.IsDeleted() || !filter.Match(&post) {
+				continue
+			}
+			if cursor.Before {
+				page = append([]BlogPost{post}, page...)
+			} else {
+				page = append(page, post)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// GetPost gets post by id
+func (s *BoltPostStore) GetPost(id primitive.ObjectID) (*BlogPost, error) {
+	post := &BlogPost{}
+	err := s.DB.View(func(tx *bbolt.Tx) error {
+		return getPost(tx.Bucket(postsBucket), id, post)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if post.IsDeleted() {
+		return nil, ErrPostNotFound
+	}
+	return post, nil
+}
+
+// GetPostBySlug gets post by slug
+func (s *BoltPostStore) GetPostBySlug(slug string) (*BlogPost, error) {
+	post := &BlogPost{}
+	err := s.DB.View(func(tx *bbolt.Tx) error {
+		id := tx.Bucket(slugsBucket).Get([]byte(slug))
+		if id == nil {
+			return ErrPostNotFound
+		}
+		var postID primitive.ObjectID
+		copy(postID[:], id)
+		return getPost(tx.Bucket(postsBucket), postID, post)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if post.IsDeleted() {
+		return nil, ErrPostNotFound
+	}
+	return post, nil
+}
+
+// CreatePost adds new post
+func (s *BoltPostStore) CreatePost(post *BlogPost) error {
+	p := *post
+	p.ID = primitive.NewObjectID()
+	p.CreatedAt = time.Now()
+	p.UpdatedAt = p.CreatedAt
+	p.defaultDate(p.CreatedAt)
+	if p.Slug == "" {
+		p.Slug = Slugify(p.Title)
+	}
+	err := saveWithUniqueSlug(&p, func() error {
+		return s.DB.Update(func(tx *bbolt.Tx) error {
+			if err := putSlug(tx, &p, ""); err != nil {
+				return err
+			}
+			if err := tx.Bucket(postDatesBucket).Put(postDateKey(p.Date, p.ID), []byte{}); err != nil {
+				return err
+			}
+			return putPost(tx.Bucket(postsBucket), &p)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	post.ID = p.ID
+	post.Slug = p.Slug
+	post.Date = p.Date
+	post.CreatedAt = p.CreatedAt
+	post.UpdatedAt = p.UpdatedAt
+	return nil
+}
+
+// UpdatePost updates post
+func (s *BoltPostStore) UpdatePost(post *BlogPost) error {
+	p := *post
+	err := saveWithUniqueSlug(&p, func() error {
+		return s.DB.Update(func(tx *bbolt.Tx) error {
+			b := tx.Bucket(postsBucket)
+			stored := &BlogPost{}
+			if err := getPost(b, p.ID, stored); err != nil {
+				return err
+			}
+			if stored.IsDeleted() {
+				return ErrPostNotFound
+			}
+			if stored.Version != p.Version {
+				return ErrVersionConflict
+			}
+			if p.Slug == "" {
+				p.Slug = stored.Slug
+			}
+			if err := putSlug(tx, &p, stored.Slug); err != nil {
+				return err
+			}
+			p.AuthorID = stored.AuthorID
+			p.CreatedAt = stored.CreatedAt
+			p.defaultDate(p.CreatedAt)
+			if err := movePostDate(tx, stored, &p); err != nil {
+				return err
+			}
+			p.UpdatedAt = time.Now()
+			p.DeletedAt = nil
+			p.Version++
+			return putPost(b, &p)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	*post = p
+	return nil
+}
+
+// DeletePost moves post to trash
+func (s *BoltPostStore) DeletePost(id primitive.ObjectID) error {
+	return s.modify(id, false, func(post *BlogPost) error {
+		now := time.Now()
+		post.DeletedAt = &now
+		return nil
+	})
+}
+
+// ListTrash gets deleted posts
+func (s *BoltPostStore) ListTrash() ([]BlogPost, error) {
+	return s.filter(true)
+}
+
+// RestorePost moves post from trash back
+func (s *BoltPostStore) RestorePost(id primitive.ObjectID) error {
+	return s.modify(id, true, func(post *BlogPost) error {
+		post.DeletedAt = nil
+		return nil
+	})
+}
+
+// PurgePost removes post from trash
+func (s *BoltPostStore) PurgePost(id primitive.ObjectID) error {
+	return s.DB.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(postsBucket)
+		post := &BlogPost{}
+		if err := getPost(b, id, post); err != nil {
+			return err
+		}
+		if !post.IsDeleted() {
+			return ErrPostNotFound
+		}
+		if err := deletePostData(tx, id); err != nil {
+			return err
+		}
+		if err := deleteSlug(tx, post.Slug); err != nil {
+			return err
+		}
+		if err := tx.Bucket(postDatesBucket).Delete(postDateKey(post.Date, id)); err != nil {
+			return err
+		}
+		return b.Delete(id[:])
+	})
+}
+
+// PurgeTrash removes posts deleted before time
+func (s *BoltPostStore) PurgeTrash(before time.Time) (int, error) {
+	expired := []BlogPost{}
+	err := s.DB.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(postsBucket)
+		err := b.ForEach(func(k, v []byte) error {
+			post := BlogPost{}
+			if err := bson.Unmarshal(v, &post); err != nil {
+				return err
+			}
+			if post.IsDeleted() && post.DeletedAt.Before(before) {
+				copy(post.ID[:], k)
+				expired = append(expired, post)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// deleting while iterating skips keys, so delete afterwards
+		for _, post := range expired {
+			if err := deletePostData(tx, post.ID); err != nil {
+				return err
+			}
+			if err := deleteSlug(tx, post.Slug); err != nil {
+				return err
+			}
+			if err := tx.Bucket(postDatesBucket).Delete(postDateKey(post.Date, post.ID)); err != nil {
+				return err
+			}
+			if err := b.Delete(post.ID[:]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(expired), nil
+}
+
+// modify changes stored post in place, deleted selects trash or live posts
+func (s *BoltPostStore) modify(id primitive.ObjectID, deleted bool, change func(post *BlogPost) error) error {
+	return s.DB.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(postsBucket)
+		post := &BlogPost{}
+		if err := getPost(b, id, post); err != nil {
+			return err
+		}
+		if post.IsDeleted() != deleted {
+			return ErrPostNotFound
+		}
+		if err := change(post); err != nil {
+			return err
+		}
+		post.ID = id
+		return putPost(b, post)
+	})
+}
+
+func getPost(b *bbolt.Bucket, id primitive.ObjectID, post *BlogPost) error {
+	v := b.Get(id[:])
+	if v == nil {
+		return ErrPostNotFound
+	}
+	return bson.Unmarshal(v, post)
+}
+
+func putPost(b *bbolt.Bucket, post *BlogPost) error {
+	data, err := bson.Marshal(post)
+	if err != nil {
+		return err
+	}
+	return b.Put(post.ID[:], data)
+}
+
+// movePostDate replaces post dates key of stored post with key of its new date
+func movePostDate(tx *bbolt.Tx, stored, post *BlogPost) error {
+	b := tx.Bucket(postDatesBucket)
+	if err := b.Delete(postDateKey(stored.Date, stored.ID)); err != nil {
+		return err
+	}
+	return b.Put(postDateKey(post.Date, post.ID), []byte{})
+}
+
+// putSlug points post slug at post id and frees its old slug,
+// slugs of posts in trash stay taken until purged
+func putSlug(tx *bbolt.Tx, post *BlogPost, old string) error {
+	b := tx.Bucket(slugsBucket)
+	if post.Slug == "" || post.Slug == old {
+		return nil
+	}
+	if id := b.Get([]byte(post.Slug)); id != nil && !bytes.Equal(id, post.ID[:]) {
+		return ErrSlugTaken
+	}
+	if err := b.Put([]byte(post.Slug), post.ID[:]); err != nil {
+		return err
+	}
+	return deleteSlug(tx, old)
+}
+
+func deleteSlug(tx *bbolt.Tx, slug string) error {
+	if slug == "" {
+		return nil
+	}
+	return tx.Bucket(slugsBucket).Delete([]byte(slug))
+}
+
+// revision keys are post id followed by big endian revision number,
+// so revisions of a post are stored together in order
+func revisionKey(postID primitive.ObjectID, number int) []byte {
+	k := make([]byte, len(postID)+4)
+	copy(k, postID[:])
+	binary.BigEndian.PutUint32(k[len(postID):], uint32(number))
+	return k
+}
+
+// AddRevision stores revision
+func (s *BoltPostStore) AddRevision(rev *Revision) error {
+	return s.DB.Update(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(revisionsBucket).Cursor()
+		number := 1
+		k, _ := c.Seek(revisionKey(rev.PostID, math.MaxUint32))
+		if k == nil {
+			k, _ = c.Last()
+		} else {
+			k, _ = c.Prev()
+		}
+		if k != nil && bytes.HasPrefix(k, rev.PostID[:]) {
+			number = int(binary.BigEndian.Uint32(k[len(rev.PostID):])) + 1
+		}
+
+		r := *rev
+		r.Number = number
+		data, err := bson.Marshal(&r)
+		if err != nil {
+			return err
+		}
+		if err := tx.Bucket(revisionsBucket).Put(revisionKey(rev.PostID, number), data); err != nil {
+			return err
+		}
+		rev.Number = number
+		return nil
+	})
+}
+
+// ListRevisions gets post revisions, newest first
+func (s *BoltPostStore) ListRevisions(postID primitive.ObjectID) ([]Revision, error) {
+	revs := []Revision{}
+	err := s.DB.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(revisionsBucket).Cursor()
+		for k, v := c.Seek(postID[:]); k != nil && bytes.HasPrefix(k, postID[:]); k, v = c.Next() {
+			rev := Revision{}
+			if err := bson.Unmarshal(v, &rev); err != nil {
+				return err
+			}
+			revs = append([]Revision{rev}, revs...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return revs, nil
+}
+
+// GetRevision gets post revision by number
+func (s *BoltPostStore) GetRevision(postID primitive.ObjectID, number int) (*Revision, error) {
+	rev := &Revision{}
+	err := s.DB.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket(revisionsBucket).Get(revisionKey(postID, number))
+		if v == nil {
+			return ErrRevisionNotFound
+		}
+		return bson.Unmarshal(v, rev)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
+
+// deletePostData deletes revisions and comments of post
+func deletePostData(tx *bbolt.Tx, postID primitive.ObjectID) error {
+	for _, name := range [][]byte{revisionsBucket, commentsBucket} {
+		if err := deleteByPost(tx.Bucket(name), postID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteByPost deletes bucket keys prefixed with post id
+func deleteByPost(b *bbolt.Bucket, postID primitive.ObjectID) error {
+	keys := [][]byte{}
+	c := b.Cursor()
+	for k, _ := c.Seek(postID[:]); k != nil && bytes.HasPrefix(k, postID[:]); k, _ = c.Next() {
+		keys = append(keys, append([]byte{}, k...))
+	}
+	for _, k := range keys {
+		if err := b.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// comment keys are post id followed by comment id,
+// so post comments are stored together in creation order
+func commentKey(comment *Comment) []byte {
+	return append(append([]byte{}, comment.PostID[:]...), comment.ID[:]...)
+}
+
+// AddComment stores comment
+func (s *BoltPostStore) AddComment(comment *Comment) error {
+	c := *comment
+	c.ID = primitive.NewObjectID()
+	data, err := bson.Marshal(&c)
+	if err != nil {
+		return err
+	}
+	err = s.DB.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(commentsBucket).Put(commentKey(&c), data)
+	})
+	if err != nil {
+		return err
+	}
+	comment.ID = c.ID
+	return nil
+}
+
+// ListComments gets post comments, oldest first
+func (s *BoltPostStore) ListComments(postID primitive.ObjectID) ([]Comment, error) {
+	comments := []Comment{}
+	err := s.DB.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(commentsBucket).Cursor()
+		for k, v := c.Seek(postID[:]); k != nil && bytes.HasPrefix(k, postID[:]); k, v = c.Next() {
+			comment := Comment{}
+			if err := bson.Unmarshal(v, &comment); err != nil {
+				return err
+			}
+			comments = append(comments, comment)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// ListCommentsOf gets comments of posts in one transaction, oldest first
+func (s *BoltPostStore) ListCommentsOf(postIDs []primitive.ObjectID) (map[primitive.ObjectID][]Comment, error) {
+	comments := make(map[primitive.ObjectID][]Comment, len(postIDs))
+	err := s.DB.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(commentsBucket).Cursor()
+		for _, id := range postIDs {
+			list := []Comment{}
+			for k, v := c.Seek(id[:]); k != nil && bytes.HasPrefix(k, id[:]); k, v = c.Next() {
+				comment := Comment{}
+				if err := bson.Unmarshal(v, &comment); err != nil {
+					return err
+				}
+				list = append(list, comment)
+			}
+			comments[id] = list
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// CountComments gets number of comments of posts
+func (s *BoltPostStore) CountComments(postIDs []primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+	counts := make(map[primitive.ObjectID]int, len(postIDs))
+	err := s.DB.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(commentsBucket).Cursor()
+		for _, id := range postIDs {
+			n := 0
+			for k, _ := c.Seek(id[:]); k != nil && bytes.HasPrefix(k, id[:]); k, _ = c.Next() {
+				n++
+			}
+			counts[id] = n
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// CreateUser stores user, usernames bucket maps names to ids
+// so taken names are found without scanning users
+func (s *BoltPostStore) CreateUser(user *User) error {
+	u := *user
+	u.ID = primitive.NewObjectID()
+	err := s.DB.Update(func(tx *bbolt.Tx) error {
+		names := tx.Bucket(userNamesBucket)
+		if names.Get([]byte(u.Name)) != nil {
+			return ErrUserExists
+		}
+		data, err := bson.Marshal(&u)
+		if err != nil {
+			return err
+		}
+		if err := tx.Bucket(usersBucket).Put(u.ID[:], data); err != nil {
+			return err
+		}
+		return names.Put([]byte(u.Name), u.ID[:])
+	})
+	if err != nil {
+		return err
+	}
+	user.ID = u.ID
+	return nil
+}
+
+// GetUser gets user by id
+func (s *BoltPostStore) GetUser(id primitive.ObjectID) (*User, error) {
+	user := &User{}
+	err := s.DB.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket(usersBucket).Get(id[:])
+		if v == nil {
+			return ErrUserNotFound
+		}
+		return bson.Unmarshal(v, user)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetUserByName gets user by name
+func (s *BoltPostStore) GetUserByName(name string) (*User, error) {
+	var id primitive.ObjectID
+	err := s.DB.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket(userNamesBucket).Get([]byte(name))
+		if v == nil {
+			return ErrUserNotFound
+		}
+		copy(id[:], v)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetUser(id)
+}
+
+// ListUsers gets users ordered by name
+func (s *BoltPostStore) ListUsers() ([]User, error) {
+	users := []User{}
+	err := s.DB.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(usersBucket)
+		// usernames bucket keys are sorted names
+		return tx.Bucket(userNamesBucket).ForEach(func(k, id []byte) error {
+			user := User{}
+			if err := bson.Unmarshal(b.Get(id), &user); err != nil {
+				return err
+			}
+			users = append(users, user)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// SetUserRole changes user role
+func (s *BoltPostStore) SetUserRole(id primitive.ObjectID, role string) error {
+	return s.DB.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(usersBucket)
+		v := b.Get(id[:])
+		if v == nil {
+			return ErrUserNotFound
+		}
+		user := User{}
+		if err := bson.Unmarshal(v, &user); err != nil {
+			return err
+		}
+		user.Role = role
+		data, err := bson.Marshal(&user)
+		if err != nil {
+			return err
+		}
+		return b.Put(id[:], data)
+	})
+}
+
+// CreateSession stores session
+func (s *BoltPostStore) CreateSession(session *Session) error {
+	data, err := bson.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return s.DB.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Put([]byte(session.TokenHash), data)
+	})
+}
+
+// GetSession gets session by token hash
+func (s *BoltPostStore) GetSession(tokenHash string) (*Session, error) {
+	session := &Session{}
+	err := s.DB.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket(sessionsBucket).Get([]byte(tokenHash))
+		if v == nil {
+			return ErrSessionNotFound
+		}
+		return bson.Unmarshal(v, session)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// DeleteSession removes session
+func (s *BoltPostStore) DeleteSession(tokenHash string) error {
+	return s.DB.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(sessionsBucket)
+		if b.Get([]byte(tokenHash)) == nil {
+			return ErrSessionNotFound
+		}
+		return b.Delete([]byte(tokenHash))
+	})
+}
+
+// PurgeSessions removes expired sessions
+func (s *BoltPostStore) PurgeSessions(before time.Time) error {
+	return s.DB.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(sessionsBucket)
+		expired := [][]byte{}
+		err := b.ForEach(func(k, v []byte) error {
+			session := Session{}
+			if err := bson.Unmarshal(v, &session); err != nil {
+				return err
+			}
+			if session.ExpiresAt.Before(before) {
+				expired = append(expired, append([]byte{}, k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range expired {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CreateWebhook stores webhook
+func (s *BoltPostStore) CreateWebhook(hook *Webhook) error {
+	h := *hook
+	h.ID = primitive.NewObjectID()
+	data, err := bson.Marshal(&h)
+	if err != nil {
+		return err
+	}
+	err = s.DB.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(webhooksBucket).Put(h.ID[:], data)
+	})
+	if err != nil {
+		return err
+	}
+	hook.ID = h.ID
+	return nil
+}
+
+// GetWebhook gets webhook by id
+func (s *BoltPostStore) GetWebhook(id primitive.ObjectID) (*Webhook, error) {
+	hook := &Webhook{}
+	err := s.DB.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket(webhooksBucket).Get(id[:])
+		if v == nil {
+			return ErrWebhookNotFound
+		}
+		return bson.Unmarshal(v, hook)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+// ListWebhooks gets webhooks, oldest first
+func (s *BoltPostStore) ListWebhooks() ([]Webhook, error) {
+	hooks := []Webhook{}
+	err := s.DB.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(webhooksBucket).ForEach(func(k, v []byte) error {
+			hook := Webhook{}
+			if err := bson.Unmarshal(v, &hook); err != nil {
+				return err
+			}
+			hooks = append(hooks, hook)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// DeleteWebhook removes webhook and its deliveries
+func (s *BoltPostStore) DeleteWebhook(id primitive.ObjectID) error {
+	return s.DB.Update(func(tx *bbolt.Tx) error {
+		hooks := tx.Bucket(webhooksBucket)
+		if hooks.Get(id[:]) == nil {
+			return ErrWebhookNotFound
+		}
+		if err := hooks.Delete(id[:]); err != nil {
+			return err
+		}
+		b := tx.Bucket(deliveriesBucket)
+		keys := [][]byte{}
+		err := b.ForEach(func(k, v []byte) error {
+			d := Delivery{}
+			if err := bson.Unmarshal(v, &d); err != nil {
+				return err
+			}
+			if d.WebhookID == id {
+				keys = append(keys, append([]byte{}, k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AddDelivery stores delivery
+func (s *BoltPostStore) AddDelivery(d *Delivery) error {
+	c := *d
+	c.ID = primitive.NewObjectID()
+	data, err := bson.Marshal(&c)
+	if err != nil {
+		return err
+	}
+	err = s.DB.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(deliveriesBucket).Put(c.ID[:], data)
+	})
+	if err != nil {
+		return err
+	}
+	d.ID = c.ID
+	return nil
+}
+
+// GetDelivery gets delivery by id
+func (s *BoltPostStore) GetDelivery(id primitive.ObjectID) (*Delivery, error) {
+	d := &Delivery{}
+	err := s.DB.View(func(tx *bbolt.Tx) error {
+		v := tx.Bucket(deliveriesBucket).Get(id[:])
+		if v == nil {
+			return ErrDeliveryNotFound
+		}
+		return bson.Unmarshal(v, d)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// UpdateDelivery saves delivery
+func (s *BoltPostStore) UpdateDelivery(d *Delivery) error {
+	data, err := bson.Marshal(d)
+	if err != nil {
+		return err
+	}
+	return s.DB.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(deliveriesBucket)
+		if b.Get(d.ID[:]) == nil {
+			return ErrDeliveryNotFound
+		}
+		return b.Put(d.ID[:], data)
+	})
+}
+
+// ListDeliveries gets deliveries of webhook, newest first
+func (s *BoltPostStore) ListDeliveries(webhookID primitive.ObjectID, limit int) ([]Delivery, error) {
+	list := []Delivery{}
+	err := s.DB.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(deliveriesBucket).Cursor()
+		for k, v := c.Last(); k != nil && len(list) < limit; k, v = c.Prev() {
+			d := Delivery{}
+			if err := bson.Unmarshal(v, &d); err != nil {
+				return err
+			}
+			if d.WebhookID == webhookID {
+				list = append(list, d)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ListDueDeliveries gets pending deliveries due at now, oldest first
+func (s *BoltPostStore) ListDueDeliveries(now time.Time, limit int) ([]Delivery, error) {
+	list := []Delivery{}
+	err := s.DB.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(deliveriesBucket).Cursor()
+		for k, v := c.First(); k != nil && len(list) < limit; k, v = c.Next() {
+			d := Delivery{}
+			if err := bson.Unmarshal(v, &d); err != nil {
+				return err
+			}
+			if d.Status == DeliveryPending && !d.NextAttemptAt.After(now) {
+				list = append(list, d)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
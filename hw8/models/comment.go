@@ -0,0 +1,145 @@
+package models
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrCommentNotFound is returned when replied comment does not exist
+var ErrCommentNotFound = errors.New("Comment not found")
+
+// ErrBadComment is returned when comment text or author is empty or too long
+var ErrBadComment = errors.New("Comment must have author and text")
+
+// ErrCommentAuthorTaken is returned when anonymous comment is signed
+// with name of a registered user
+var ErrCommentAuthorTaken = errors.New("Comment author is a registered user name, log in to use it")
+
+// comment limits in runes
+const (
+	maxAuthorLength  = 64
+	maxCommentLength = 4000
+)
+
+// Comment is a comment on a post, ParentID is set for replies
+type Comment struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	PostID    primitive.ObjectID
+	ParentID  primitive.ObjectID `bson:",omitempty"`
+	Author    string
+	Text      string
+	CreatedAt time.Time
+}
+
+// IsReply tells if comment replies to another comment
+func (c *Comment) IsReply() bool {
+	return !c.ParentID.IsZero()
+}
+
+// CommentStore keeps post comments
+type CommentStore interface {
+	// AddComment stores comment and sets its id
+	AddComment(comment *Comment) error
+	// ListComments returns post comments, oldest first
+	ListComments(postID primitive.ObjectID) ([]Comment, error)
+	// ListCommentsOf returns comments of each post, oldest first
+	ListCommentsOf(postIDs []primitive.ObjectID) (map[primitive.ObjectID][]Comment, error)
+	// CountComments returns number of comments of each post
+	CountComments(postIDs []primitive.ObjectID) (map[primitive.ObjectID]int, error)
+}
+
+// PostComment checks comment of user and stores it, replies must
+// answer a comment of the same post. Logged in users sign comments
+// with their name, anonymous ones may not take names of users.
+// Posts user can not see are not found, like on post pages.
+func PostComment(store Store, comment *Comment, user *User) error {
+	if user != nil {
+		comment.Author = user.Name
+	}
+	comment.Author = strings.TrimSpace(comment.Author)
+	comment.Text = strings.TrimSpace(comment.Text)
+	if comment.Author == "" || comment.Text == "" ||
+		utf8.RuneCountInString(comment.Author) > maxAuthorLength ||
+		utf8.RuneCountInString(comment.Text) > maxCommentLength {
+		return ErrBadComment
+	}
+
+	post, err := store.GetPost(comment.PostID)
+	if err != nil {
+		return err
+	}
+	if !post.IsPublished() && !user.CanEditPost(post) {
+		return ErrPostNotFound
+	}
+
+	if user == nil {
+		_, err := store.GetUserByName(comment.Author)
+		if err == nil {
+			return ErrCommentAuthorTaken
+		}
+		if err != ErrUserNotFound {
+			return err
+		}
+	}
+
+	if comment.IsReply() {
+		comments, err := store.ListComments(comment.PostID)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, c := range comments {
+			found = found || c.ID == comment.ParentID
+		}
+		if !found {
+			return ErrCommentNotFound
+		}
+	}
+
+	comment.CreatedAt = time.Now()
+	return store.AddComment(comment)
+}
+
+// ThreadComment is a comment placed in thread at some depth
+type ThreadComment struct {
+	Comment
+	Depth int
+}
+
+// Thread orders comments so replies follow their parents,
+// replies deeper than maxDepth are shown at maxDepth
+func Thread(comments []Comment, maxDepth int) []ThreadComment {
+	replies := map[primitive.ObjectID][]Comment{}
+	known := map[primitive.ObjectID]bool{}
+	for _, c := range comments {
+		known[c.ID] = true
+	}
+	roots := []Comment{}
+	for _, c := range comments {
+		if c.IsReply() && known[c.ParentID] {
+			replies[c.ParentID] = append(replies[c.ParentID], c)
+		} else {
+			roots = append(roots, c)
+		}
+	}
+
+	thread := make([]ThreadComment, 0, len(comments))
+	var walk func(c Comment, depth int)
+	walk = func(c Comment, depth int) {
+		if depth > maxDepth {
+			depth = maxDepth
+		}
+		thread = append(thread, ThreadComment{Comment: c, Depth: depth})
+		for _, r := range replies[c.ID] {
+			walk(r, depth+1)
+		}
+	}
+	for _, c := range roots {
+		walk(c, 0)
+	}
+	return thread
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// CSRFToken is form token of a session, it is derived from session token
+// so it stays the same for the whole session and needs no storage
+func CSRFToken(sessionToken string) string {
+	if sessionToken == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(sessionToken))
+	mac.Write([]byte("csrf"))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewPreSessionToken is random token of anonymous visitor,
+// CSRF tokens of their forms are derived from it like from session token
+func NewPreSessionToken() (string, error) {
+	return randomToken()
+}
+
+// CheckCSRF tells if token was issued for the session
+func CheckCSRF(sessionToken, token string) bool {
+	if sessionToken == "" || token == "" {
+		return false
+	}
+	return hmac.Equal([]byte(CSRFToken(sessionToken)), []byte(token))
+}
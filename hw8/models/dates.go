@@ -0,0 +1,71 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// DateLayout is the format of post dates in forms
+const DateLayout = "2006-01-02"
+
+// ErrBadDate is returned when post date can not be parsed
+var ErrBadDate = errors.New("Date must look like 2006-01-02")
+
+// dateLayouts are accepted date formats, the first is the form one
+var dateLayouts = []string{DateLayout, "2006-01-02T15:04", time.RFC3339}
+
+// ParseDate reads post date, dates without zone are in loc
+func ParseDate(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, ErrBadDate
+}
+
+// upgradeDate replaces string date field of stored document with timestamp,
+// dates that can not be parsed are dropped and zero time is used
+func upgradeDate(data []byte) ([]byte, error) {
+	v, err := bson.Raw(data).LookupErr("date")
+	if err != nil || v.Type != bsontype.String {
+		return data, nil
+	}
+
+	doc := bson.D{}
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	upgraded := make(bson.D, 0, len(doc))
+	for _, e := range doc {
+		if e.Key == "date" {
+			t, err := ParseDate(v.StringValue(), time.UTC)
+			if err != nil {
+				continue
+			}
+			e.Value = t
+		}
+		upgraded = append(upgraded, e)
+	}
+	return bson.Marshal(upgraded)
+}
+
+// sortByDate orders posts newest first, posts of the same date
+// are ordered by id like pages are, see Cursor
+func sortByDate(posts []BlogPost) []BlogPost {
+	sort.Slice(posts, func(i, j int) bool {
+		return compareDateID(posts[i].Date, posts[i].ID, posts[j].Date, posts[j].ID) > 0
+	})
+	return posts
+}
+
+// datedBetween keeps posts with date in [from, to), newest first
+func datedBetween(posts []BlogPost, from, to time.Time) []BlogPost {
+	return sortByDate(selectPosts(posts, func(p *BlogPost) bool {
+		return !p.Date.Before(from) && p.Date.Before(to)
+	}))
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PostFilter selects posts, zero fields match any post.
+// Dates select posts dated in [From, To).
+type PostFilter struct {
+	Tag      string
+	Category string
+	Status   string
+	AuthorID primitive.ObjectID
+	From     time.Time
+	To       time.Time
+}
+
+// Match tells if post passes filter
+func (f *PostFilter) Match(p *BlogPost) bool {
+	status := p.Status
+	if p.IsPublished() {
+		status = StatusPublished
+	}
+	switch {
+	case f.Tag != "" && !p.HasTag(f.Tag),
+		f.Category != "" && p.Category != f.Category,
+		f.Status != "" && status != f.Status,
+		!f.AuthorID.IsZero() && p.AuthorID != f.AuthorID,
+		!f.From.IsZero() && p.Date.Before(f.From),
+		!f.To.IsZero() && !p.Date.Before(f.To):
+		return false
+	}
+	return true
+}
+
+// FilterPosts keeps posts matching filter, latest date first
+func FilterPosts(posts []BlogPost, f PostFilter) []BlogPost {
+	return sortByDate(selectPosts(posts, f.Match))
+}
@@ -0,0 +1,621 @@
+package models
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MemoryPostStore keeps posts in memory, data is lost on restart
+type MemoryPostStore struct {
+	mu         sync.Mutex
+	posts      map[primitive.ObjectID]BlogPost
+	revisions  map[primitive.ObjectID][]Revision
+	comments   map[primitive.ObjectID][]Comment
+	users      map[primitive.ObjectID]User
+	sessions   map[string]Session
+	webhooks   map[primitive.ObjectID]Webhook
+	deliveries map[primitive.ObjectID]Delivery
+}
+
+// NewMemoryPostStore creates empty memory post store
+func NewMemoryPostStore() *MemoryPostStore {
+	return &MemoryPostStore{
+		posts:      make(map[primitive.ObjectID]BlogPost),
+		revisions:  make(map[primitive.ObjectID][]Revision),
+		comments:   make(map[primitive.ObjectID][]Comment),
+		users:      make(map[primitive.ObjectID]User),
+		sessions:   make(map[string]Session),
+		webhooks:   make(map[primitive.ObjectID]Webhook),
+		deliveries: make(map[primitive.ObjectID]Delivery),
+	}
+}
+
+func (s *MemoryPostStore) filter(deleted bool) []BlogPost {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	posts := make([]BlogPost, 0, len(s.posts))
+	for _, p := range s.posts {
+		if p.IsDeleted() == deleted {
+			posts = append(posts, p)
+		}
+	}
+	sort.Slice(posts, func(i, j int) bool {
+		return posts[i].ID.Hex() < posts[j].ID.Hex()
+	})
+	return posts
+}
+
+// ListPosts gets all posts ordered by creation
+func (s *MemoryPostStore) ListPosts() ([]BlogPost, error) {
+	return s.filter(false), nil
+}
+
+// published gets live posts readers can see ordered by creation
+func (s *MemoryPostStore) published() []BlogPost {
+	posts := s.filter(false)
+	r := posts[:0]
+	for _, p := range posts {
+		if p.IsPublished() {
+			r = append(r, p)
+		}
+	}
+	return r
+}
+
+// ListPostsPage gets page of posts matching filter newest first
+func (s *MemoryPostStore) ListPostsPage(filter PostFilter, cursor Cursor, limit int) ([]BlogPost, error) {
+	posts := FilterPosts(s.filter(false), filter)
+	page := []BlogPost{}
+	if cursor.Before {
+		for i := len(posts) - 1; i >= 0 && len(page) < limit; i-- {
+			if cursor.Precedes(&posts[i]) {
+				page = append([]BlogPost{posts[i]}, page...)
+			}
+		}
+		return page, nil
+	}
+	for i := 0; i < len(posts) && len(page) < limit; i++ {
+		if cursor.IsZero() || cursor.Follows(&posts[i]) {
+			page = append(page, posts[i])
+		}
+	}
+	return page, nil
+}
+
+// ListPostsByTag gets posts having tag
+func (s *MemoryPostStore) ListPostsByTag(tag string) ([]BlogPost, error) {
+	return sortByDate(selectPosts(s.published(), func(p *BlogPost) bool {
+		return p.HasTag(tag)
+	})), nil
+}
+
+// ListPostsByCategory gets posts in category
+func (s *MemoryPostStore) ListPostsByCategory(category string) ([]BlogPost, error) {
+	return sortByDate(selectPosts(s.published(), func(p *BlogPost) bool {
+		return p.Category == category
+	})), nil
+}
+
+// ListPostsBetween gets posts dated in [from, to)
+func (s *MemoryPostStore) ListPostsBetween(from, to time.Time) ([]BlogPost, error) {
+	return datedBetween(s.published(), from, to), nil
+}
+
+// ListTags gets tags with post counts
+func (s *MemoryPostStore) ListTags() ([]TagCount, error) {
+	return countTags(s.published()), nil
+}
+
+// ListDrafts gets unpublished posts of author
+func (s *MemoryPostStore) ListDrafts(authorID primitive.ObjectID) ([]BlogPost, error) {
+	return draftsOf(s.filter(false), authorID), nil
+}
+
+// PublishDue publishes scheduled posts whose time has come
+func (s *MemoryPostStore) PublishDue(now time.Time) ([]BlogPost, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	published := []BlogPost{}
+	for id, post := range s.posts {
+		if post.IsDeleted() || !post.isDue(now) {
+			continue
+		}
+		post.Status = StatusPublished
+		post.Version++
+		post.UpdatedAt = now
+		s.posts[id] = post
+		published = append(published, post)
+	}
+	return published, nil
+}
+
+// GetPost gets post by id
+func (s *MemoryPostStore) GetPost(id primitive.ObjectID) (*BlogPost, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	post, ok := s.posts[id]
+	if !ok || post.IsDeleted() {
+		return nil, ErrPostNotFound
+	}
+	return &post, nil
+}
+
+// GetPostBySlug gets post by slug
+func (s *MemoryPostStore) GetPostBySlug(slug string) (*BlogPost, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, post := range s.posts {
+		if post.Slug == slug && !post.IsDeleted() {
+			return &post, nil
+		}
+	}
+	return nil, ErrPostNotFound
+}
+
+// slugTaken tells if post other than id has slug, posts in trash keep theirs
+func (s *MemoryPostStore) slugTaken(slug string, id primitive.ObjectID) bool {
+	if slug == "" {
+		return false
+	}
+	for _, post := range s.posts {
+		if post.Slug == slug && post.ID != id {
+			return true
+		}
+	}
+	return false
+}
+
+// CreatePost adds new post
+func (s *MemoryPostStore) CreatePost(post *BlogPost) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if post.Slug == "" {
+		post.Slug = Slugify(post.Title)
+	}
+	id := primitive.NewObjectID()
+	err := saveWithUniqueSlug(post, func() error {
+		if s.slugTaken(post.Slug, id) {
+			return ErrSlugTaken
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	post.ID = id
+	post.CreatedAt = time.Now()
+	post.UpdatedAt = post.CreatedAt
+	post.defaultDate(post.CreatedAt)
+	s.posts[post.ID] = *post
+	return nil
+}
+
+// UpdatePost updates post
+func (s *MemoryPostStore) UpdatePost(post *BlogPost) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.posts[post.ID]
+	if !ok || stored.IsDeleted() {
+		return ErrPostNotFound
+	}
+	if stored.Version != post.Version {
+		return ErrVersionConflict
+	}
+	if post.Slug == "" {
+		post.Slug = stored.Slug
+	}
+	err := saveWithUniqueSlug(post, func() error {
+		if s.slugTaken(post.Slug, post.ID) {
+			return ErrSlugTaken
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	post.Version++
+	post.AuthorID = stored.AuthorID
+	post.CreatedAt = stored.CreatedAt
+	post.UpdatedAt = time.Now()
+	p := *post
+	p.DeletedAt = nil
+	s.posts[post.ID] = p
+	return nil
+}
+
+// DeletePost moves post to trash
+func (s *MemoryPostStore) DeletePost(id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	post, ok := s.posts[id]
+	if !ok || post.IsDeleted() {
+		return ErrPostNotFound
+	}
+	now := time.Now()
+	post.DeletedAt = &now
+	s.posts[id] = post
+	return nil
+}
+
+// ListTrash gets deleted posts
+func (s *MemoryPostStore) ListTrash() ([]BlogPost, error) {
+	return s.filter(true), nil
+}
+
+// RestorePost moves post from trash back
+func (s *MemoryPostStore) RestorePost(id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	post, ok := s.posts[id]
+	if !ok || !post.IsDeleted() {
+		return ErrPostNotFound
+	}
+	post.DeletedAt = nil
+	s.posts[id] = post
+	return nil
+}
+
+// PurgePost removes post from trash
+func (s *MemoryPostStore) PurgePost(id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	post, ok := s.posts[id]
+	if !ok || !post.IsDeleted() {
+		return ErrPostNotFound
+	}
+	delete(s.posts, id)
+	delete(s.revisions, id)
+	delete(s.comments, id)
+	return nil
+}
+
+// PurgeTrash removes posts deleted before time
+func (s *MemoryPostStore) PurgeTrash(before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for id, post := range s.posts {
+		if post.IsDeleted() && post.DeletedAt.Before(before) {
+			delete(s.posts, id)
+			delete(s.revisions, id)
+			delete(s.comments, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// AddRevision stores revision
+func (s *MemoryPostStore) AddRevision(rev *Revision) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revs := s.revisions[rev.PostID]
+	rev.Number = len(revs) + 1
+	s.revisions[rev.PostID] = append(revs, *rev)
+	return nil
+}
+
+// ListRevisions gets post revisions, newest first
+func (s *MemoryPostStore) ListRevisions(postID primitive.ObjectID) ([]Revision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revs := s.revisions[postID]
+	r := make([]Revision, 0, len(revs))
+	for i := len(revs) - 1; i >= 0; i-- {
+		r = append(r, revs[i])
+	}
+	return r, nil
+}
+
+// GetRevision gets post revision by number
+func (s *MemoryPostStore) GetRevision(postID primitive.ObjectID, number int) (*Revision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revs := s.revisions[postID]
+	if number < 1 || number > len(revs) {
+		return nil, ErrRevisionNotFound
+	}
+	rev := revs[number-1]
+	return &rev, nil
+}
+
+// AddComment stores comment
+func (s *MemoryPostStore) AddComment(comment *Comment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comment.ID = primitive.NewObjectID()
+	s.comments[comment.PostID] = append(s.comments[comment.PostID], *comment)
+	return nil
+}
+
+// ListComments gets post comments, oldest first
+func (s *MemoryPostStore) ListComments(postID primitive.ObjectID) ([]Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Comment{}, s.comments[postID]...), nil
+}
+
+// ListCommentsOf gets comments of posts, oldest first
+func (s *MemoryPostStore) ListCommentsOf(postIDs []primitive.ObjectID) (map[primitive.ObjectID][]Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comments := make(map[primitive.ObjectID][]Comment, len(postIDs))
+	for _, id := range postIDs {
+		comments[id] = append([]Comment{}, s.comments[id]...)
+	}
+	return comments, nil
+}
+
+// CountComments gets number of comments of posts
+func (s *MemoryPostStore) CountComments(postIDs []primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[primitive.ObjectID]int, len(postIDs))
+	for _, id := range postIDs {
+		counts[id] = len(s.comments[id])
+	}
+	return counts, nil
+}
+
+// CreateUser stores user
+func (s *MemoryPostStore) CreateUser(user *User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if u.Name == user.Name {
+			return ErrUserExists
+		}
+	}
+	user.ID = primitive.NewObjectID()
+	s.users[user.ID] = *user
+	return nil
+}
+
+// GetUser gets user by id
+func (s *MemoryPostStore) GetUser(id primitive.ObjectID) (*User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return &user, nil
+}
+
+// GetUserByName gets user by name
+func (s *MemoryPostStore) GetUserByName(name string) (*User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if u.Name == name {
+			return &u, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+// ListUsers gets users ordered by name
+func (s *MemoryPostStore) ListUsers() ([]User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users := make([]User, 0, len(s.users))
+	for _, u := range s.users {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Name < users[j].Name
+	})
+	return users, nil
+}
+
+// SetUserRole changes user role
+func (s *MemoryPostStore) SetUserRole(id primitive.ObjectID, role string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return ErrUserNotFound
+	}
+	user.Role = role
+	s.users[id] = user
+	return nil
+}
+
+// CreateSession stores session
+func (s *MemoryPostStore) CreateSession(session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions[session.TokenHash] = *session
+	return nil
+}
+
+// GetSession gets session by token hash
+func (s *MemoryPostStore) GetSession(tokenHash string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[tokenHash]
+	if !ok {
+		return nil, ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+// DeleteSession removes session
+func (s *MemoryPostStore) DeleteSession(tokenHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sessions[tokenHash]; !ok {
+		return ErrSessionNotFound
+	}
+	delete(s.sessions, tokenHash)
+	return nil
+}
+
+// PurgeSessions removes expired sessions
+func (s *MemoryPostStore) PurgeSessions(before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for hash, session := range s.sessions {
+		if session.ExpiresAt.Before(before) {
+			delete(s.sessions, hash)
+		}
+	}
+	return nil
+}
+
+// CreateWebhook stores webhook
+func (s *MemoryPostStore) CreateWebhook(hook *Webhook) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hook.ID = primitive.NewObjectID()
+	s.webhooks[hook.ID] = *hook
+	return nil
+}
+
+// GetWebhook gets webhook by id
+func (s *MemoryPostStore) GetWebhook(id primitive.ObjectID) (*Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hook, ok := s.webhooks[id]
+	if !ok {
+		return nil, ErrWebhookNotFound
+	}
+	return &hook, nil
+}
+
+// ListWebhooks gets webhooks, oldest first
+func (s *MemoryPostStore) ListWebhooks() ([]Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hooks := make([]Webhook, 0, len(s.webhooks))
+	for _, h := range s.webhooks {
+		hooks = append(hooks, h)
+	}
+	sort.Slice(hooks, func(i, j int) bool {
+		return hooks[i].ID.Hex() < hooks[j].ID.Hex()
+	})
+	return hooks, nil
+}
+
+// DeleteWebhook removes webhook and its deliveries
+func (s *MemoryPostStore) DeleteWebhook(id primitive.ObjectID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.webhooks[id]; !ok {
+		return ErrWebhookNotFound
+	}
+	delete(s.webhooks, id)
+	for did, d := range s.deliveries {
+		if d.WebhookID == id {
+			delete(s.deliveries, did)
+		}
+	}
+	return nil
+}
+
+// AddDelivery stores delivery
+func (s *MemoryPostStore) AddDelivery(d *Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d.ID = primitive.NewObjectID()
+	s.deliveries[d.ID] = *d
+	return nil
+}
+
+// GetDelivery gets delivery by id
+func (s *MemoryPostStore) GetDelivery(id primitive.ObjectID) (*Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, ok := s.deliveries[id]
+	if !ok {
+		return nil, ErrDeliveryNotFound
+	}
+	return &d, nil
+}
+
+// UpdateDelivery saves delivery
+func (s *MemoryPostStore) UpdateDelivery(d *Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.deliveries[d.ID]; !ok {
+		return ErrDeliveryNotFound
+	}
+	s.deliveries[d.ID] = *d
+	return nil
+}
+
+// ListDeliveries gets deliveries of webhook, newest first
+func (s *MemoryPostStore) ListDeliveries(webhookID primitive.ObjectID, limit int) ([]Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := []Delivery{}
+	for _, d := range s.deliveries {
+		if d.WebhookID == webhookID {
+			list = append(list, d)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID.Hex() > list[j].ID.Hex()
+	})
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
+
+// ListDueDeliveries gets pending deliveries due at now, oldest first
+func (s *MemoryPostStore) ListDueDeliveries(now time.Time, limit int) ([]Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := []Delivery{}
+	for _, d := range s.deliveries {
+		if d.Status == DeliveryPending && !d.NextAttemptAt.After(now) {
+			list = append(list, d)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ID.Hex() < list[j].ID.Hex()
+	})
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list, nil
+}
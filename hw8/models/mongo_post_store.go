@@ -0,0 +1,789 @@
+package models
+
+import (
+	ctx "context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoPostStore keeps posts in mongo collection
+type MongoPostStore struct {
+	DB     *mongo.Client
+	DBName string
+}
+
+// NewMongoPostStore creates mongo post store
+func NewMongoPostStore(db *mongo.Client, dbName string) *MongoPostStore {
+	return &MongoPostStore{DB: db, DBName: dbName}
+}
+
+func (s *MongoPostStore) posts() *mongo.Collection {
+	return s.DB.Database(s.DBName).Collection("posts")
+}
+
+func (s *MongoPostStore) revisions() *mongo.Collection {
+	return s.DB.Database(s.DBName).Collection("revisions")
+}
+
+func (s *MongoPostStore) comments() *mongo.Collection {
+	return s.DB.Database(s.DBName).Collection("comments")
+}
+
+func (s *MongoPostStore) users() *mongo.Collection {
+	return s.DB.Database(s.DBName).Collection("users")
+}
+
+func (s *MongoPostStore) sessions() *mongo.Collection {
+	return s.DB.Database(s.DBName).Collection("sessions")
+}
+
+func (s *MongoPostStore) webhooks() *mongo.Collection {
+	return s.DB.Database(s.DBName).Collection("webhooks")
+}
+
+func (s *MongoPostStore) deliveries() *mongo.Collection {
+	return s.DB.Database(s.DBName).Collection("deliveries")
+}
+
+// live matches posts that are not in trash
+func live(filter bson.M) bson.M {
+	filter["deletedat"] = nil
+	return filter
+}
+
+// public matches live posts readers can see,
+// posts stored before statuses existed have no status field
+func public(filter bson.M) bson.M {
+	filter["status"] = bson.M{"$in": bson.A{StatusPublished, nil}}
+	return live(filter)
+}
+
+// trashed matches posts in trash
+func trashed(filter bson.M) bson.M {
+	filter["deletedat"] = bson.M{"$ne": nil}
+	return filter
+}
+
+func (s *MongoPostStore) find(filter bson.M, opts ...*options.FindOptions) ([]BlogPost, error) {
+	cur, err := s.posts().Find(ctx.TODO(), filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := []BlogPost{}
+	err = cur.All(ctx.TODO(), &posts)
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// ListPosts gets all posts
+func (s *MongoPostStore) ListPosts() ([]BlogPost, error) {
+	return s.find(live(bson.M{}))
+}
+
+// byDate sorts latest post date first, the same dates by creation
+var byDate = bson.D{{Key: "date", Value: -1}, {Key: "_id", Value: -1}}
+
+// ListPostsByTag gets posts having tag, served by tags multikey index
+func (s *MongoPostStore) ListPostsByTag(tag string) ([]BlogPost, error) {
+	return s.find(public(bson.M{"tags": tag}), options.Find().SetSort(byDate))
+}
+
+// ListPostsByCategory gets posts in category
+func (s *MongoPostStore) ListPostsByCategory(category string) ([]BlogPost, error) {
+	return s.find(public(bson.M{"category": category}), options.Find().SetSort(byDate))
+}
+
+// ListPostsBetween gets posts dated in [from, to), served by date index
+func (s *MongoPostStore) ListPostsBetween(from, to time.Time) ([]BlogPost, error) {
+	filter := public(bson.M{"date": bson.M{"$gte": from, "$lt": to}})
+	return s.find(filter, options.Find().SetSort(byDate))
+}
+
+// ListDrafts gets unpublished posts of author
+func (s *MongoPostStore) ListDrafts(authorID primitive.ObjectID) ([]BlogPost, error) {
+	filter := live(bson.M{"authorid": authorID, "status": bson.M{"$in": bson.A{StatusDraft, StatusScheduled}}})
+	return s.find(filter, options.Find().SetSort(bson.M{"_id": -1}))
+}
+
+// PublishDue publishes scheduled posts whose time has come
+func (s *MongoPostStore) PublishDue(now time.Time) ([]BlogPost, error) {
+	due := live(bson.M{"status": StatusScheduled, "publishat": bson.M{"$lte": now}})
+	posts, err := s.find(due)
+	if err != nil || len(posts) == 0 {
+		return posts, err
+	}
+
+	published := []BlogPost{}
+	for _, p := range posts {
+		// status is checked again in case post was changed since it was read
+		filter := bson.M{"_id": p.ID, "status": StatusScheduled, "version": p.Version}
+		update := bson.M{"$set": bson.M{"status": StatusPublished, "updatedat": now}, "$inc": bson.M{"version": 1}}
+		err := s.updateOne(filter, update)
+		if err == ErrPostNotFound {
+			continue
+		}
+		if err != nil {
+			return published, err
+		}
+		p.Status = StatusPublished
+		p.Version++
+		p.UpdatedAt = now
+		published = append(published, p)
+	}
+	return published, nil
+}
+
+// ListTags gets tags with post counts
+func (s *MongoPostStore) ListTags() ([]TagCount, error) {
+	pipeline := bson.A{
+		bson.M{"$match": public(bson.M{"tags": bson.M{"$exists": true}})},
+		bson.M{"$unwind": "$tags"},
+		bson.M{"$group": bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}},
+		bson.M{"$sort": bson.M{"_id": 1}},
+	}
+	cur, err := s.posts().Aggregate(ctx.TODO(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []struct {
+		Name  string `bson:"_id"`
+		Count int
+	}{}
+	if err := cur.All(ctx.TODO(), &rows); err != nil {
+		return nil, err
+	}
+	tags := make([]TagCount, 0, len(rows))
+	for _, r := range rows {
+		tags = append(tags, TagCount{Name: r.Name, Count: r.Count})
+	}
+	return tags, nil
+}
+
+// matching converts post filter to query of live posts
+func matching(f PostFilter) bson.M {
+	filter := bson.M{}
+	if f.Tag != "" {
+		filter["tags"] = f.Tag
+	}
+	if f.Category != "" {
+		filter["category"] = f.Category
+	}
+	if !f.AuthorID.IsZero() {
+		filter["authorid"] = f.AuthorID
+	}
+	dates := bson.M{}
+	if !f.From.IsZero() {
+		dates["$gte"] = f.From
+	}
+	if !f.To.IsZero() {
+		dates["$lt"] = f.To
+	}
+	if len(dates) > 0 {
+		filter["date"] = dates
+	}
+	switch f.Status {
+	case "":
+		return live(filter)
+	case StatusPublished:
+		return public(filter)
+	}
+	filter["status"] = f.Status
+	return live(filter)
+}
+
+// ListPostsPage gets page of posts matching filter newest first,
+// the date and _id index serves both the filter and the sort
+func (s *MongoPostStore) ListPostsPage(f PostFilter, cursor Cursor, limit int) ([]BlogPost, error) {
+	filter := matching(f)
+	sort := -1
+	if !cursor.IsZero() {
+		op := "$lt"
+		if cursor.Before {
+			op, sort = "$gt", 1
+		}
+		filter["$or"] = bson.A{
+			bson.M{"date": bson.M{op: cursor.Date}},
+			bson.M{"date": cursor.Date, "_id": bson.M{op: cursor.ID}},
+		}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "date", Value: sort}, {Key: "_id", Value: sort}}).SetLimit(int64(limit))
+	cur, err := s.posts().Find(ctx.TODO(), filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	posts := []BlogPost{}
+	if err := cur.All(ctx.TODO(), &posts); err != nil {
+		return nil, err
+	}
+
+	if cursor.Before {
+		reversePosts(posts)
+	}
+	return posts, nil
+}
+
+// GetPost gets post by id
+func (s *MongoPostStore) GetPost(id primitive.ObjectID) (*BlogPost, error) {
+	return s.findOne(live(bson.M{"_id": bson.M{"$eq": id}}))
+}
+
+// GetPostBySlug gets post by slug
+func (s *MongoPostStore) GetPostBySlug(slug string) (*BlogPost, error) {
+	return s.findOne(live(bson.M{"slug": bson.M{"$eq": slug}}))
+}
+
+func (s *MongoPostStore) findOne(filter bson.M) (*BlogPost, error) {
+	res := s.posts().FindOne(ctx.TODO(), filter)
+	post := &BlogPost{}
+	err := res.Decode(post)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrPostNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// CreatePost adds new post, slugs are unique by index
+// created by EnsureIndexes
+func (s *MongoPostStore) CreatePost(post *BlogPost) error {
+	post.CreatedAt = time.Now()
+	post.UpdatedAt = post.CreatedAt
+	post.defaultDate(post.CreatedAt)
+	if post.Slug == "" {
+		post.Slug = Slugify(post.Title)
+	}
+	var result *mongo.InsertOneResult
+	err := saveWithUniqueSlug(post, func() error {
+		var err error
+		result, err = s.posts().InsertOne(ctx.TODO(), post)
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrSlugTaken
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	post.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// UpdatePost updates post, version is checked in the same query
+// so concurrent updates can not overwrite each other
+func (s *MongoPostStore) UpdatePost(post *BlogPost) error {
+	filter := live(bson.M{"_id": bson.M{"$eq": post.ID}, "version": post.Version})
+	if post.Version == 0 {
+		// posts created before versioning have no version field
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+	}
+	now := time.Now()
+	set := bson.M{"title": post.Title, "link": post.Link, "date": post.Date, "content": post.Content,
+		"tags": post.Tags, "category": post.Category, "status": post.Status, "publishat": post.PublishAt,
+		"updatedat": now, "version": post.Version + 1}
+
+	// stored author, created time and slug are read back in the same query
+	stored := &BlogPost{}
+	opts := options.FindOneAndUpdate().SetProjection(bson.M{"authorid": 1, "createdat": 1, "slug": 1}).
+		SetReturnDocument(options.After)
+	err := saveWithUniqueSlug(post, func() error {
+		if post.Slug != "" {
+			set["slug"] = post.Slug
+		}
+		err := s.posts().FindOneAndUpdate(ctx.TODO(), filter, bson.M{"$set": set}, opts).Decode(stored)
+		if mongo.IsDuplicateKeyError(err) {
+			return ErrSlugTaken
+		}
+		return err
+	})
+	if err == mongo.ErrNoDocuments {
+		if _, getErr := s.GetPost(post.ID); getErr == nil {
+			return ErrVersionConflict
+		}
+		return ErrPostNotFound
+	}
+	if err != nil {
+		return err
+	}
+	post.Version++
+	post.Slug = stored.Slug
+	post.AuthorID = stored.AuthorID
+	post.CreatedAt = stored.CreatedAt
+	post.UpdatedAt = now
+	return nil
+}
+
+// DeletePost moves post to trash
+func (s *MongoPostStore) DeletePost(id primitive.ObjectID) error {
+	filter := live(bson.M{"_id": bson.M{"$eq": id}})
+	update := bson.M{"$set": bson.M{"deletedat": time.Now()}}
+	return s.updateOne(filter, update)
+}
+
+// ListTrash gets deleted posts
+func (s *MongoPostStore) ListTrash() ([]BlogPost, error) {
+	return s.find(trashed(bson.M{}))
+}
+
+// RestorePost moves post from trash back
+func (s *MongoPostStore) RestorePost(id primitive.ObjectID) error {
+	filter := trashed(bson.M{"_id": bson.M{"$eq": id}})
+	update := bson.M{"$unset": bson.M{"deletedat": ""}}
+	return s.updateOne(filter, update)
+}
+
+// PurgePost removes post from trash
+func (s *MongoPostStore) PurgePost(id primitive.ObjectID) error {
+	filter := trashed(bson.M{"_id": bson.M{"$eq": id}})
+	res, err := s.posts().DeleteOne(ctx.TODO(), filter)
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrPostNotFound
+	}
+	if _, err = s.revisions().DeleteMany(ctx.TODO(), bson.M{"postid": id}); err != nil {
+		return err
+	}
+	_, err = s.comments().DeleteMany(ctx.TODO(), bson.M{"postid": id})
+	return err
+}
+
+// PurgeTrash removes posts deleted before time
+func (s *MongoPostStore) PurgeTrash(before time.Time) (int, error) {
+	expired, err := s.find(bson.M{"deletedat": bson.M{"$ne": nil, "$lt": before}})
+	if err != nil {
+		return 0, err
+	}
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(expired))
+	for _, p := range expired {
+		ids = append(ids, p.ID)
+	}
+	res, err := s.posts().DeleteMany(ctx.TODO(), bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+	_, err = s.revisions().DeleteMany(ctx.TODO(), bson.M{"postid": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+	_, err = s.comments().DeleteMany(ctx.TODO(), bson.M{"postid": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+	return int(res.DeletedCount), nil
+}
+
+func (s *MongoPostStore) updateOne(filter, update bson.M) error {
+	res, err := s.posts().UpdateOne(ctx.TODO(), filter, update)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrPostNotFound
+	}
+	return nil
+}
+
+// AddRevision stores revision, numbers are unique per post
+// with the revisions index created by EnsureIndexes
+func (s *MongoPostStore) AddRevision(rev *Revision) error {
+	opts := options.FindOne().SetSort(bson.M{"number": -1})
+	last := &Revision{}
+	err := s.revisions().FindOne(ctx.TODO(), bson.M{"postid": rev.PostID}, opts).Decode(last)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return err
+	}
+
+	r := *rev
+	r.Number = last.Number + 1
+	if _, err := s.revisions().InsertOne(ctx.TODO(), &r); err != nil {
+		return err
+	}
+	rev.Number = r.Number
+	return nil
+}
+
+// ListRevisions gets post revisions, newest first
+func (s *MongoPostStore) ListRevisions(postID primitive.ObjectID) ([]Revision, error) {
+	opts := options.Find().SetSort(bson.M{"number": -1})
+	cur, err := s.revisions().Find(ctx.TODO(), bson.M{"postid": postID}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	revs := []Revision{}
+	if err := cur.All(ctx.TODO(), &revs); err != nil {
+		return nil, err
+	}
+	return revs, nil
+}
+
+// GetRevision gets post revision by number
+func (s *MongoPostStore) GetRevision(postID primitive.ObjectID, number int) (*Revision, error) {
+	rev := &Revision{}
+	err := s.revisions().FindOne(ctx.TODO(), bson.M{"postid": postID, "number": number}).Decode(rev)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
+
+// EnsureIndexes creates indexes the store relies on
+func (s *MongoPostStore) EnsureIndexes() error {
+	_, err := s.revisions().Indexes().CreateOne(ctx.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "postid", Value: 1}, {Key: "number", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	// tags is an array, so this is a multikey index with an entry per tag
+	_, err = s.posts().Indexes().CreateMany(ctx.TODO(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "tags", Value: 1}, {Key: "date", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "category", Value: 1}, {Key: "date", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "date", Value: -1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "authorid", Value: 1}, {Key: "_id", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "publishat", Value: 1}}},
+		// posts created before slugs existed have none
+		{Keys: bson.D{{Key: "slug", Value: 1}}, Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"slug": bson.M{"$exists": true}})},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.comments().Indexes().CreateOne(ctx.TODO(), mongo.IndexModel{
+		Keys: bson.D{{Key: "postid", Value: 1}, {Key: "_id", Value: 1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.users().Indexes().CreateOne(ctx.TODO(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	// mongo removes expired sessions itself, PurgeSessions only speeds it up
+	_, err = s.sessions().Indexes().CreateMany(ctx.TODO(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "tokenhash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "expiresat", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.deliveries().Indexes().CreateMany(ctx.TODO(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "nextattemptat", Value: 1}}},
+		{Keys: bson.D{{Key: "webhookid", Value: 1}, {Key: "_id", Value: -1}}},
+	})
+	return err
+}
+
+// AddComment stores comment
+func (s *MongoPostStore) AddComment(comment *Comment) error {
+	result, err := s.comments().InsertOne(ctx.TODO(), comment)
+	if err != nil {
+		return err
+	}
+	comment.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// ListComments gets post comments, oldest first
+func (s *MongoPostStore) ListComments(postID primitive.ObjectID) ([]Comment, error) {
+	opts := options.Find().SetSort(bson.M{"_id": 1})
+	cur, err := s.comments().Find(ctx.TODO(), bson.M{"postid": postID}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	comments := []Comment{}
+	if err := cur.All(ctx.TODO(), &comments); err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// ListCommentsOf gets comments of posts with one query, oldest first
+func (s *MongoPostStore) ListCommentsOf(postIDs []primitive.ObjectID) (map[primitive.ObjectID][]Comment, error) {
+	opts := options.Find().SetSort(bson.M{"_id": 1})
+	cur, err := s.comments().Find(ctx.TODO(), bson.M{"postid": bson.M{"$in": postIDs}}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	list := []Comment{}
+	if err := cur.All(ctx.TODO(), &list); err != nil {
+		return nil, err
+	}
+	comments := make(map[primitive.ObjectID][]Comment, len(postIDs))
+	for _, id := range postIDs {
+		comments[id] = []Comment{}
+	}
+	for _, c := range list {
+		comments[c.PostID] = append(comments[c.PostID], c)
+	}
+	return comments, nil
+}
+
+// CountComments gets number of comments of posts
+func (s *MongoPostStore) CountComments(postIDs []primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"postid": bson.M{"$in": postIDs}}},
+		bson.M{"$group": bson.M{"_id": "$postid", "count": bson.M{"$sum": 1}}},
+	}
+	cur, err := s.comments().Aggregate(ctx.TODO(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []struct {
+		PostID primitive.ObjectID `bson:"_id"`
+		Count  int
+	}{}
+	if err := cur.All(ctx.TODO(), &rows); err != nil {
+		return nil, err
+	}
+	counts := make(map[primitive.ObjectID]int, len(postIDs))
+	for _, id := range postIDs {
+		counts[id] = 0
+	}
+	for _, r := range rows {
+		counts[r.PostID] = r.Count
+	}
+	return counts, nil
+}
+
+// CreateUser stores user, names are unique with the users index
+// created by EnsureIndexes
+func (s *MongoPostStore) CreateUser(user *User) error {
+	result, err := s.users().InsertOne(ctx.TODO(), user)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrUserExists
+	}
+	if err != nil {
+		return err
+	}
+	user.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (s *MongoPostStore) findUser(filter bson.M) (*User, error) {
+	user := &User{}
+	err := s.users().FindOne(ctx.TODO(), filter).Decode(user)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetUser gets user by id
+func (s *MongoPostStore) GetUser(id primitive.ObjectID) (*User, error) {
+	return s.findUser(bson.M{"_id": id})
+}
+
+// GetUserByName gets user by name
+func (s *MongoPostStore) GetUserByName(name string) (*User, error) {
+	return s.findUser(bson.M{"name": name})
+}
+
+// ListUsers gets users ordered by name
+func (s *MongoPostStore) ListUsers() ([]User, error) {
+	opts := options.Find().SetSort(bson.M{"name": 1})
+	cur, err := s.users().Find(ctx.TODO(), bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	users := []User{}
+	if err := cur.All(ctx.TODO(), &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// SetUserRole changes user role
+func (s *MongoPostStore) SetUserRole(id primitive.ObjectID, role string) error {
+	res, err := s.users().UpdateOne(ctx.TODO(), bson.M{"_id": id}, bson.M{"$set": bson.M{"role": role}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// CreateSession stores session
+func (s *MongoPostStore) CreateSession(session *Session) error {
+	_, err := s.sessions().InsertOne(ctx.TODO(), session)
+	return err
+}
+
+// GetSession gets session by token hash
+func (s *MongoPostStore) GetSession(tokenHash string) (*Session, error) {
+	session := &Session{}
+	err := s.sessions().FindOne(ctx.TODO(), bson.M{"tokenhash": tokenHash}).Decode(session)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// DeleteSession removes session
+func (s *MongoPostStore) DeleteSession(tokenHash string) error {
+	res, err := s.sessions().DeleteOne(ctx.TODO(), bson.M{"tokenhash": tokenHash})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// PurgeSessions removes expired sessions
+func (s *MongoPostStore) PurgeSessions(before time.Time) error {
+	_, err := s.sessions().DeleteMany(ctx.TODO(), bson.M{"expiresat": bson.M{"$lt": before}})
+	return err
+}
+
+// CreateWebhook stores webhook
+func (s *MongoPostStore) CreateWebhook(hook *Webhook) error {
+	h := *hook
+	h.ID = primitive.NewObjectID()
+	if _, err := s.webhooks().InsertOne(ctx.TODO(), &h); err != nil {
+		return err
+	}
+	hook.ID = h.ID
+	return nil
+}
+
+// GetWebhook gets webhook by id
+func (s *MongoPostStore) GetWebhook(id primitive.ObjectID) (*Webhook, error) {
+	hook := &Webhook{}
+	err := s.webhooks().FindOne(ctx.TODO(), bson.M{"_id": id}).Decode(hook)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+// ListWebhooks gets webhooks, oldest first
+func (s *MongoPostStore) ListWebhooks() ([]Webhook, error) {
+	opts := options.Find().SetSort(bson.M{"_id": 1})
+	cur, err := s.webhooks().Find(ctx.TODO(), bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	hooks := []Webhook{}
+	if err := cur.All(ctx.TODO(), &hooks); err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
+// DeleteWebhook removes webhook and its deliveries
+func (s *MongoPostStore) DeleteWebhook(id primitive.ObjectID) error {
+	res, err := s.webhooks().DeleteOne(ctx.TODO(), bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return ErrWebhookNotFound
+	}
+	_, err = s.deliveries().DeleteMany(ctx.TODO(), bson.M{"webhookid": id})
+	return err
+}
+
+// AddDelivery stores delivery
+func (s *MongoPostStore) AddDelivery(d *Delivery) error {
+	c := *d
+	c.ID = primitive.NewObjectID()
+	if _, err := s.deliveries().InsertOne(ctx.TODO(), &c); err != nil {
+		return err
+	}
+	d.ID = c.ID
+	return nil
+}
+
+// GetDelivery gets delivery by id
+func (s *MongoPostStore) GetDelivery(id primitive.ObjectID) (*Delivery, error) {
+	d := &Delivery{}
+	err := s.deliveries().FindOne(ctx.TODO(), bson.M{"_id": id}).Decode(d)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// UpdateDelivery saves delivery
+func (s *MongoPostStore) UpdateDelivery(d *Delivery) error {
+	res, err := s.deliveries().ReplaceOne(ctx.TODO(), bson.M{"_id": d.ID}, d)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return ErrDeliveryNotFound
+	}
+	return nil
+}
+
+func (s *MongoPostStore) findDeliveries(filter bson.M, opts *options.FindOptions) ([]Delivery, error) {
+	cur, err := s.deliveries().Find(ctx.TODO(), filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	list := []Delivery{}
+	if err := cur.All(ctx.TODO(), &list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ListDeliveries gets deliveries of webhook, newest first
+func (s *MongoPostStore) ListDeliveries(webhookID primitive.ObjectID, limit int) ([]Delivery, error) {
+	opts := options.Find().SetSort(bson.M{"_id": -1}).SetLimit(int64(limit))
+	return s.findDeliveries(bson.M{"webhookid": webhookID}, opts)
+}
+
+// ListDueDeliveries gets pending deliveries due at now, oldest first
+func (s *MongoPostStore) ListDueDeliveries(now time.Time, limit int) ([]Delivery, error) {
+	opts := options.Find().SetSort(bson.M{"_id": 1}).SetLimit(int64(limit))
+	return s.findDeliveries(bson.M{"status": DeliveryPending, "nextattemptat": bson.M{"$lte": now}}, opts)
+}
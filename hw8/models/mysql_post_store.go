@@ -0,0 +1,957 @@
+package models
+
+import (
+	"common/mysqlutil"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ConstraintError is returned when a write violates a table constraint
+type ConstraintError = mysqlutil.ConstraintError
+
+func wrapMySQLError(err error) error {
+	return mysqlutil.Wrap(err, ErrPostNotFound)
+}
+
+const (
+	postColumns = "id, title, post_date, link, content, category, author_id, status, publish_at, created_at, updated_at, slug"
+	// tags are normalized and never contain commas
+	tagsColumn      = "(select group_concat(tag order by tag separator ',') from post_tags where post_id = posts.id)"
+	selectColumns   = postColumns + ", version, deleted_at, " + tagsColumn
+	revisionColumns = "title, post_date, link, content, editor, restored_from, created_at"
+	commentColumns  = "id, post_id, parent_id, author, text, created_at"
+	userColumns     = "id, name, role, password_hash, created_at"
+	// events are names without commas
+	webhookColumns  = "id, url, secret, events, created_at"
+	deliveryColumns = "id, webhook_id, event, payload, status, attempts, response_status, last_error, next_attempt_at, created_at, delivered_at"
+)
+
+// MySQLPostStore keeps posts in mysql posts table, see migrations package.
+// All queries are prepared once and take parameters, never formatted values.
+// Dsn must have parseTime=true to read timestamps, they are kept in UTC
+// unless dsn loc says otherwise.
+type MySQLPostStore struct {
+	DB         *sql.DB
+	list       *sql.Stmt
+	firstPage  *sql.Stmt
+	pageAfter  *sql.Stmt
+	pageBefore *sql.Stmt
+	get        *sql.Stmt
+	getBySlug  *sql.Stmt
+	storedRow  *sql.Stmt
+	insert     *sql.Stmt
+	update     *sql.Stmt
+	delete     *sql.Stmt
+	listTrash  *sql.Stmt
+	restore    *sql.Stmt
+	purge      *sql.Stmt
+	purgeTrash *sql.Stmt
+
+	listDrafts *sql.Stmt
+	listDue    *sql.Stmt
+	publish    *sql.Stmt
+
+	byTag      *sql.Stmt
+	byCategory *sql.Stmt
+	between    *sql.Stmt
+	tagCounts  *sql.Stmt
+	insertTag  *sql.Stmt
+	deleteTags *sql.Stmt
+	expireTags *sql.Stmt
+
+	lastRevision    *sql.Stmt
+	insertRevision  *sql.Stmt
+	listRevisions   *sql.Stmt
+	getRevision     *sql.Stmt
+	purgeRevisions  *sql.Stmt
+	expireRevisions *sql.Stmt
+
+	insertComment  *sql.Stmt
+	listComments   *sql.Stmt
+	countComments  *sql.Stmt
+	purgeComments  *sql.Stmt
+	expireComments *sql.Stmt
+
+	insertUser    *sql.Stmt
+	getUser       *sql.Stmt
+	getUserByName *sql.Stmt
+	listUsers     *sql.Stmt
+	setUserRole   *sql.Stmt
+	insertSession *sql.Stmt
+	getSession    *sql.Stmt
+	deleteSession *sql.Stmt
+	purgeSessions *sql.Stmt
+
+	insertWebhook     *sql.Stmt
+	getWebhook        *sql.Stmt
+	listWebhooks      *sql.Stmt
+	deleteWebhook     *sql.Stmt
+	deleteDeliveries  *sql.Stmt
+	insertDelivery    *sql.Stmt
+	getDelivery       *sql.Stmt
+	updateDelivery    *sql.Stmt
+	listDeliveries    *sql.Stmt
+	listDueDeliveries *sql.Stmt
+}
+
+// NewMySQLPostStore creates mysql post store and prepares its statements
+func NewMySQLPostStore(db *sql.DB) (*MySQLPostStore, error) {
+	s := &MySQLPostStore{DB: db}
+	p := &mysqlutil.Preparer{DB: db}
+	prepare := p.Prepare
+
+	s.list = prepare("select " + selectColumns + " from posts where deleted_at is null order by id")
+	// unset filter fields are passed as empty strings and nulls, see filterArgs
+	filtered := "select " + selectColumns + " from posts where deleted_at is null and (? = '' or status = ?)" +
+		" and (? = '' or id in (select post_id from post_tags where tag = ?)) and (? = '' or category = ?)" +
+		" and (? = '' or author_id = ?) and (? is null or post_date >= ?) and (? is null or post_date < ?)"
+	s.firstPage = prepare(filtered + " order by post_date desc, id desc limit ?")
+	s.pageAfter = prepare(filtered + " and (post_date < ? or (post_date = ? and id < ?)) order by post_date desc, id desc limit ?")
+	s.pageBefore = prepare(filtered + " and (post_date > ? or (post_date = ? and id > ?)) order by post_date, id limit ?")
+	s.get = prepare("select " + selectColumns + " from posts where id = ? and deleted_at is null")
+	s.getBySlug = prepare("select " + selectColumns + " from posts where slug = ? and deleted_at is null")
+	s.storedRow = prepare("select author_id, created_at, slug from posts where id = ?")
+	s.insert = prepare("insert into posts (" + postColumns + ") values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	// null slug keeps stored one
+	s.update = prepare("update posts set title = ?, post_date = ?, link = ?, content = ?, category = ?, status = ?, publish_at = ?, updated_at = ?, slug = coalesce(?, slug), version = version + 1 where id = ? and version = ? and deleted_at is null")
+	s.delete = prepare("update posts set deleted_at = ? where id = ? and deleted_at is null")
+	s.listTrash = prepare("select " + selectColumns + " from posts where deleted_at is not null order by deleted_at desc")
+	s.restore = prepare("update posts set deleted_at = null where id = ? and deleted_at is not null")
+	s.purge = prepare("delete from posts where id = ? and deleted_at is not null")
+	s.purgeTrash = prepare("delete from posts where deleted_at < ?")
+
+	s.listDrafts = prepare("select " + selectColumns + " from posts where author_id = ? and status <> 'published' and deleted_at is null order by id desc")
+	s.listDue = prepare("select " + selectColumns + " from posts where status = 'scheduled' and publish_at <= ? and deleted_at is null")
+	s.publish = prepare("update posts set status = 'published', updated_at = ?, version = version + 1 where id = ? and status = 'scheduled' and deleted_at is null")
+
+	s.byTag = prepare("select " + selectColumns + " from posts where id in (select post_id from post_tags where tag = ?) and status = 'published' and deleted_at is null order by post_date desc, id desc")
+	s.byCategory = prepare("select " + selectColumns + " from posts where category = ? and status = 'published' and deleted_at is null order by post_date desc, id desc")
+	s.between = prepare("select " + selectColumns + " from posts where post_date >= ? and post_date < ? and status = 'published' and deleted_at is null order by post_date desc, id desc")
+	s.tagCounts = prepare("select t.tag, count(*) from post_tags t join posts p on p.id = t.post_id where p.status = 'published' and p.deleted_at is null group by t.tag order by t.tag")
+	s.insertTag = prepare("insert into post_tags (post_id, tag) values (?, ?)")
+	s.deleteTags = prepare("delete from post_tags where post_id = ?")
+	s.expireTags = prepare("delete t from post_tags t join posts p on p.id = t.post_id where p.deleted_at < ?")
+
+	s.lastRevision = prepare("select coalesce(max(number), 0) from post_revisions where post_id = ? for update")
+	s.insertRevision = prepare("insert into post_revisions (post_id, number, " + revisionColumns + ") values (?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	s.listRevisions = prepare("select post_id, number, " + revisionColumns + " from post_revisions where post_id = ? order by number desc")
+	s.getRevision = prepare("select post_id, number, " + revisionColumns + " from post_revisions where post_id = ? and number = ?")
+	s.purgeRevisions = prepare("delete from post_revisions where post_id = ?")
+	s.expireRevisions = prepare("delete r from post_revisions r join posts p on p.id = r.post_id where p.deleted_at < ?")
+
+	s.insertComment = prepare("insert into post_comments (" + commentColumns + ") values (?, ?, ?, ?, ?, ?)")
+	s.listComments = prepare("select " + commentColumns + " from post_comments where post_id = ? order by id")
+	s.countComments = prepare("select count(*) from post_comments where post_id = ?")
+	s.purgeComments = prepare("delete from post_comments where post_id = ?")
+	s.expireComments = prepare("delete c from post_comments c join posts p on p.id = c.post_id where p.deleted_at < ?")
+
+	s.insertUser = prepare("insert into users (" + userColumns + ") values (?, ?, ?, ?, ?)")
+	s.getUser = prepare("select " + userColumns + " from users where id = ?")
+	s.getUserByName = prepare("select " + userColumns + " from users where name = ?")
+	s.listUsers = prepare("select " + userColumns + " from users order by name")
+	s.setUserRole = prepare("update users set role = ? where id = ?")
+	s.insertSession = prepare("insert into sessions (token_hash, user_id, expires_at) values (?, ?, ?)")
+	s.getSession = prepare("select token_hash, user_id, expires_at from sessions where token_hash = ?")
+	s.deleteSession = prepare("delete from sessions where token_hash = ?")
+	s.purgeSessions = prepare("delete from sessions where expires_at < ?")
+
+	s.insertWebhook = prepare("insert into webhooks (" + webhookColumns + ") values (?, ?, ?, ?, ?)")
+	s.getWebhook = prepare("select " + webhookColumns + " from webhooks where id = ?")
+	s.listWebhooks = prepare("select " + webhookColumns + " from webhooks order by id")
+	s.deleteWebhook = prepare("delete from webhooks where id = ?")
+	s.deleteDeliveries = prepare("delete from webhook_deliveries where webhook_id = ?")
+	s.insertDelivery = prepare("insert into webhook_deliveries (" + deliveryColumns + ") values (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	s.getDelivery = prepare("select " + deliveryColumns + " from webhook_deliveries where id = ?")
+	s.updateDelivery = prepare("update webhook_deliveries set status = ?, attempts = ?, response_status = ?, last_error = ?, next_attempt_at = ?, delivered_at = ? where id = ?")
+	s.listDeliveries = prepare("select " + deliveryColumns + " from webhook_deliveries where webhook_id = ? order by id desc limit ?")
+	s.listDueDeliveries = prepare("select " + deliveryColumns + " from webhook_deliveries where status = 'pending' and next_attempt_at <= ? order by id limit ?")
+	if p.Err != nil {
+		s.Close()
+		return nil, errors.Wrap(p.Err, "Can not prepare post statements")
+	}
+	return s, nil
+}
+
+// Close releases prepared statements
+func (s *MySQLPostStore) Close() error {
+	mysqlutil.CloseStmts(s.list, s.firstPage, s.pageAfter, s.pageBefore, s.get, s.getBySlug, s.storedRow, s.insert, s.update, s.delete,
+		s.listTrash, s.restore, s.purge, s.purgeTrash, s.listDrafts, s.listDue, s.publish,
+		s.byTag, s.byCategory, s.between, s.tagCounts, s.insertTag, s.deleteTags, s.expireTags,
+		s.lastRevision, s.insertRevision, s.listRevisions, s.getRevision,
+		s.purgeRevisions, s.expireRevisions,
+		s.insertComment, s.listComments, s.countComments, s.purgeComments, s.expireComments,
+		s.insertUser, s.getUser, s.getUserByName, s.listUsers, s.setUserRole,
+		s.insertSession, s.getSession, s.deleteSession, s.purgeSessions,
+		s.insertWebhook, s.getWebhook, s.listWebhooks, s.deleteWebhook, s.deleteDeliveries,
+		s.insertDelivery, s.getDelivery, s.updateDelivery, s.listDeliveries, s.listDueDeliveries)
+	return nil
+}
+
+func scanPost(row interface{ Scan(...interface{}) error }) (*BlogPost, error) {
+	post := &BlogPost{}
+	var id string
+	var publishAt, deletedAt sql.NullTime
+	var authorID, slug, tags sql.NullString
+	err := row.Scan(&id, &post.Title, &post.Date, &post.Link, &post.Content, &post.Category, &authorID,
+		&post.Status, &publishAt, &post.CreatedAt, &post.UpdatedAt, &slug, &post.Version, &deletedAt, &tags)
+	if err != nil {
+		return nil, wrapMySQLError(err)
+	}
+	post.ID, err = primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	if authorID.Valid {
+		if post.AuthorID, err = primitive.ObjectIDFromHex(authorID.String); err != nil {
+			return nil, err
+		}
+	}
+	if publishAt.Valid {
+		post.PublishAt = &publishAt.Time
+	}
+	if deletedAt.Valid {
+		post.DeletedAt = &deletedAt.Time
+	}
+	if tags.Valid {
+		post.Tags = strings.Split(tags.String, ",")
+	}
+	post.Slug = slug.String
+	return post, nil
+}
+
+func queryPosts(stmt *sql.Stmt, args ...interface{}) ([]BlogPost, error) {
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		return nil, wrapMySQLError(err)
+	}
+	defer rows.Close()
+
+	posts := []BlogPost{}
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, *post)
+	}
+	return posts, rows.Err()
+}
+
+// ListPosts gets all posts
+func (s *MySQLPostStore) ListPosts() ([]BlogPost, error) {
+	return queryPosts(s.list)
+}
+
+// filterArgs are filter parameters of page statements, each field is passed
+// twice to test if it is set and to match it
+func filterArgs(f PostFilter) []interface{} {
+	authorID := ""
+	if !f.AuthorID.IsZero() {
+		authorID = f.AuthorID.Hex()
+	}
+	from := sql.NullTime{Time: f.From, Valid: !f.From.IsZero()}
+	to := sql.NullTime{Time: f.To, Valid: !f.To.IsZero()}
+	return []interface{}{f.Status, f.Status, f.Tag, f.Tag, f.Category, f.Category, authorID, authorID, from, from, to, to}
+}
+
+// ListPostsPage gets page of posts matching filter newest first,
+// hex ids sort like object ids so posts_post_date index serves the sort
+func (s *MySQLPostStore) ListPostsPage(filter PostFilter, cursor Cursor, limit int) ([]BlogPost, error) {
+	args := filterArgs(filter)
+	if cursor.IsZero() {
+		return queryPosts(s.firstPage, append(args, limit)...)
+	}
+	args = append(args, cursor.Date, cursor.Date, cursor.ID.Hex(), limit)
+	if !cursor.Before {
+		return queryPosts(s.pageAfter, args...)
+	}
+	posts, err := queryPosts(s.pageBefore, args...)
+	if err != nil {
+		return nil, err
+	}
+	reversePosts(posts)
+	return posts, nil
+}
+
+// GetPost gets post by id
+func (s *MySQLPostStore) GetPost(id primitive.ObjectID) (*BlogPost, error) {
+	return scanPost(s.get.QueryRow(id.Hex()))
+}
+
+// GetPostBySlug gets post by slug
+func (s *MySQLPostStore) GetPostBySlug(slug string) (*BlogPost, error) {
+	return scanPost(s.getBySlug.QueryRow(slug))
+}
+
+// CreatePost adds new post with its tags
+func (s *MySQLPostStore) CreatePost(post *BlogPost) error {
+	id := primitive.NewObjectID()
+	now := time.Now()
+	post.defaultDate(now)
+	if post.Slug == "" {
+		post.Slug = Slugify(post.Title)
+	}
+	err := saveWithUniqueSlug(post, func() error {
+		return s.inTx(func(tx *sql.Tx) error {
+			authorID := sql.NullString{String: post.AuthorID.Hex(), Valid: !post.AuthorID.IsZero()}
+			_, err := tx.Stmt(s.insert).Exec(id.Hex(), post.Title, post.Date, post.Link, post.Content, post.Category, authorID,
+				statusColumn(post), post.PublishAt, now, now, slugColumn(post))
+			if err != nil {
+				return wrapSlugError(err)
+			}
+			return s.writeTags(tx, id, post.Tags)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	post.ID = id
+	post.CreatedAt = now
+	post.UpdatedAt = now
+	return nil
+}
+
+// UpdatePost updates post and its tags, version is checked in the same statement
+// so concurrent updates can not overwrite each other
+func (s *MySQLPostStore) UpdatePost(post *BlogPost) error {
+	now := time.Now()
+	err := saveWithUniqueSlug(post, func() error {
+		return s.inTx(func(tx *sql.Tx) error {
+			err := execAffected(tx.Stmt(s.update), post.Title, post.Date, post.Link, post.Content, post.Category,
+				statusColumn(post), post.PublishAt, now, slugColumn(post), post.ID.Hex(), post.Version)
+			if err != nil {
+				return wrapSlugError(err)
+			}
+			// author and created time are not updated, they are read back from the stored row
+			var authorID, slug sql.NullString
+			if err := tx.Stmt(s.storedRow).QueryRow(post.ID.Hex()).Scan(&authorID, &post.CreatedAt, &slug); err != nil {
+				return wrapMySQLError(err)
+			}
+			post.AuthorID = primitive.NilObjectID
+			if authorID.Valid {
+				if post.AuthorID, err = primitive.ObjectIDFromHex(authorID.String); err != nil {
+					return err
+				}
+			}
+			post.Slug = slug.String
+			return s.writeTags(tx, post.ID, post.Tags)
+		})
+	})
+	if err == ErrPostNotFound {
+		if _, getErr := s.GetPost(post.ID); getErr == nil {
+			return ErrVersionConflict
+		}
+	}
+	if err != nil {
+		return err
+	}
+	post.Version++
+	post.UpdatedAt = now
+	return nil
+}
+
+// statusColumn is stored post status, posts without one are published
+func statusColumn(post *BlogPost) string {
+	if post.Status == "" {
+		return StatusPublished
+	}
+	return post.Status
+}
+
+// slugColumn is stored post slug, posts without one have null
+// so the unique index does not cover them
+func slugColumn(post *BlogPost) sql.NullString {
+	return sql.NullString{String: post.Slug, Valid: post.Slug != ""}
+}
+
+// wrapSlugError tells taken slug from other constraint violations
+func wrapSlugError(err error) error {
+	if myErr, ok := errors.Cause(err).(*mysql.MySQLError); ok && myErr.Number == 1062 &&
+		strings.Contains(myErr.Message, "posts_slug") {
+		return ErrSlugTaken
+	}
+	return wrapMySQLError(err)
+}
+
+// inTx runs fn in transaction, committing only if it succeeds
+func (s *MySQLPostStore) inTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// writeTags replaces post tags
+func (s *MySQLPostStore) writeTags(tx *sql.Tx, id primitive.ObjectID, tags []string) error {
+	if _, err := tx.Stmt(s.deleteTags).Exec(id.Hex()); err != nil {
+		return wrapMySQLError(err)
+	}
+	insert := tx.Stmt(s.insertTag)
+	for _, tag := range tags {
+		if _, err := insert.Exec(id.Hex(), tag); err != nil {
+			return wrapMySQLError(err)
+		}
+	}
+	return nil
+}
+
+// ListPostsByTag gets posts having tag, served by post_tags tag index
+func (s *MySQLPostStore) ListPostsByTag(tag string) ([]BlogPost, error) {
+	return queryPosts(s.byTag, tag)
+}
+
+// ListPostsByCategory gets posts in category
+func (s *MySQLPostStore) ListPostsByCategory(category string) ([]BlogPost, error) {
+	return queryPosts(s.byCategory, category)
+}
+
+// ListPostsBetween gets posts dated in [from, to), served by posts_post_date index
+func (s *MySQLPostStore) ListPostsBetween(from, to time.Time) ([]BlogPost, error) {
+	return queryPosts(s.between, from, to)
+}
+
+// ListDrafts gets unpublished posts of author, served by posts_author index
+func (s *MySQLPostStore) ListDrafts(authorID primitive.ObjectID) ([]BlogPost, error) {
+	return queryPosts(s.listDrafts, authorID.Hex())
+}
+
+// PublishDue publishes scheduled posts whose time has come,
+// posts changed since they were read are left to the next run
+func (s *MySQLPostStore) PublishDue(now time.Time) ([]BlogPost, error) {
+	posts, err := queryPosts(s.listDue, now)
+	if err != nil {
+		return nil, err
+	}
+
+	published := []BlogPost{}
+	for _, p := range posts {
+		err := execAffected(s.publish, now, p.ID.Hex())
+		if err == ErrPostNotFound {
+			continue
+		}
+		if err != nil {
+			return published, err
+		}
+		p.Status = StatusPublished
+		p.Version++
+		p.UpdatedAt = now
+		published = append(published, p)
+	}
+	return published, nil
+}
+
+// ListTags gets tags with post counts
+func (s *MySQLPostStore) ListTags() ([]TagCount, error) {
+	rows, err := s.tagCounts.Query()
+	if err != nil {
+		return nil, wrapMySQLError(err)
+	}
+	defer rows.Close()
+
+	tags := []TagCount{}
+	for rows.Next() {
+		t := TagCount{}
+		if err := rows.Scan(&t.Name, &t.Count); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+	return tags, rows.Err()
+}
+
+// DeletePost moves post to trash
+func (s *MySQLPostStore) DeletePost(id primitive.ObjectID) error {
+	return execAffected(s.delete, time.Now(), id.Hex())
+}
+
+// ListTrash gets deleted posts
+func (s *MySQLPostStore) ListTrash() ([]BlogPost, error) {
+	return queryPosts(s.listTrash)
+}
+
+// RestorePost moves post from trash back
+func (s *MySQLPostStore) RestorePost(id primitive.ObjectID) error {
+	return execAffected(s.restore, id.Hex())
+}
+
+// PurgePost removes post from trash
+func (s *MySQLPostStore) PurgePost(id primitive.ObjectID) error {
+	if err := execAffected(s.purge, id.Hex()); err != nil {
+		return err
+	}
+	if _, err := s.deleteTags.Exec(id.Hex()); err != nil {
+		return wrapMySQLError(err)
+	}
+	if _, err := s.purgeComments.Exec(id.Hex()); err != nil {
+		return wrapMySQLError(err)
+	}
+	_, err := s.purgeRevisions.Exec(id.Hex())
+	return wrapMySQLError(err)
+}
+
+// PurgeTrash removes posts deleted before time
+func (s *MySQLPostStore) PurgeTrash(before time.Time) (int, error) {
+	if _, err := s.expireRevisions.Exec(before); err != nil {
+		return 0, wrapMySQLError(err)
+	}
+	if _, err := s.expireTags.Exec(before); err != nil {
+		return 0, wrapMySQLError(err)
+	}
+	if _, err := s.expireComments.Exec(before); err != nil {
+		return 0, wrapMySQLError(err)
+	}
+	res, err := s.purgeTrash.Exec(before)
+	if err != nil {
+		return 0, wrapMySQLError(err)
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// execAffected runs statement that must change exactly one post
+func execAffected(stmt *sql.Stmt, args ...interface{}) error {
+	res, err := stmt.Exec(args...)
+	if err != nil {
+		return wrapMySQLError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrPostNotFound
+	}
+	return nil
+}
+
+// AddRevision stores revision, the post revisions are locked
+// while the next number is chosen
+func (s *MySQLPostStore) AddRevision(rev *Revision) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+
+	var last int
+	err = tx.Stmt(s.lastRevision).QueryRow(rev.PostID.Hex()).Scan(&last)
+	if err != nil {
+		tx.Rollback()
+		return wrapMySQLError(err)
+	}
+
+	restoredFrom := sql.NullInt64{Int64: int64(rev.RestoredFrom), Valid: rev.RestoredFrom > 0}
+	_, err = tx.Stmt(s.insertRevision).Exec(rev.PostID.Hex(), last+1,
+		rev.Title, rev.Date, rev.Link, rev.Content, rev.Editor, restoredFrom, rev.CreatedAt)
+	if err != nil {
+		tx.Rollback()
+		return wrapMySQLError(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	rev.Number = last + 1
+	return nil
+}
+
+func scanRevision(row interface{ Scan(...interface{}) error }) (*Revision, error) {
+	rev := &Revision{}
+	var postID string
+	var restoredFrom sql.NullInt64
+	err := row.Scan(&postID, &rev.Number, &rev.Title, &rev.Date, &rev.Link, &rev.Content,
+		&rev.Editor, &restoredFrom, &rev.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrRevisionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	rev.PostID, err = primitive.ObjectIDFromHex(postID)
+	if err != nil {
+		return nil, err
+	}
+	rev.RestoredFrom = int(restoredFrom.Int64)
+	return rev, nil
+}
+
+// ListRevisions gets post revisions, newest first
+func (s *MySQLPostStore) ListRevisions(postID primitive.ObjectID) ([]Revision, error) {
+	rows, err := s.listRevisions.Query(postID.Hex())
+	if err != nil {
+		return nil, wrapMySQLError(err)
+	}
+	defer rows.Close()
+
+	revs := []Revision{}
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revs = append(revs, *rev)
+	}
+	return revs, rows.Err()
+}
+
+// GetRevision gets post revision by number
+func (s *MySQLPostStore) GetRevision(postID primitive.ObjectID, number int) (*Revision, error) {
+	return scanRevision(s.getRevision.QueryRow(postID.Hex(), number))
+}
+
+// AddComment stores comment
+func (s *MySQLPostStore) AddComment(comment *Comment) error {
+	id := primitive.NewObjectID()
+	parentID := sql.NullString{String: comment.ParentID.Hex(), Valid: comment.IsReply()}
+	_, err := s.insertComment.Exec(id.Hex(), comment.PostID.Hex(), parentID,
+		comment.Author, comment.Text, comment.CreatedAt)
+	if err != nil {
+		return wrapMySQLError(err)
+	}
+	comment.ID = id
+	return nil
+}
+
+// ListComments gets post comments, oldest first
+func (s *MySQLPostStore) ListComments(postID primitive.ObjectID) ([]Comment, error) {
+	rows, err := s.listComments.Query(postID.Hex())
+	if err != nil {
+		return nil, wrapMySQLError(err)
+	}
+	return scanComments(rows)
+}
+
+// ListCommentsOf gets comments of posts with one query, oldest first
+func (s *MySQLPostStore) ListCommentsOf(postIDs []primitive.ObjectID) (map[primitive.ObjectID][]Comment, error) {
+	comments := make(map[primitive.ObjectID][]Comment, len(postIDs))
+	if len(postIDs) == 0 {
+		return comments, nil
+	}
+	args := make([]interface{}, len(postIDs))
+	for i, id := range postIDs {
+		args[i] = id.Hex()
+		comments[id] = []Comment{}
+	}
+	marks := strings.TrimSuffix(strings.Repeat("?, ", len(postIDs)), ", ")
+	rows, err := s.DB.Query("select "+commentColumns+" from post_comments where post_id in ("+marks+") order by id", args...)
+	if err != nil {
+		return nil, wrapMySQLError(err)
+	}
+	list, err := scanComments(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range list {
+		comments[c.PostID] = append(comments[c.PostID], c)
+	}
+	return comments, nil
+}
+
+// scanComments reads and closes rows of commentColumns
+func scanComments(rows *sql.Rows) ([]Comment, error) {
+	defer rows.Close()
+
+	comments := []Comment{}
+	for rows.Next() {
+		c := Comment{}
+		var id, post string
+		var parent sql.NullString
+		if err := rows.Scan(&id, &post, &parent, &c.Author, &c.Text, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		var err error
+		if c.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+			return nil, err
+		}
+		if c.PostID, err = primitive.ObjectIDFromHex(post); err != nil {
+			return nil, err
+		}
+		if parent.Valid {
+			if c.ParentID, err = primitive.ObjectIDFromHex(parent.String); err != nil {
+				return nil, err
+			}
+		}
+		comments = append(comments, c)
+	}
+	return comments, rows.Err()
+}
+
+// CountComments gets number of comments of posts, one indexed count per post
+func (s *MySQLPostStore) CountComments(postIDs []primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+	counts := make(map[primitive.ObjectID]int, len(postIDs))
+	for _, id := range postIDs {
+		var n int
+		if err := s.countComments.QueryRow(id.Hex()).Scan(&n); err != nil {
+			return nil, wrapMySQLError(err)
+		}
+		counts[id] = n
+	}
+	return counts, nil
+}
+
+// CreateUser stores user, names are unique by users table key
+func (s *MySQLPostStore) CreateUser(user *User) error {
+	id := primitive.NewObjectID()
+	_, err := s.insertUser.Exec(id.Hex(), user.Name, user.Role, user.PasswordHash, user.CreatedAt)
+	if myErr, ok := err.(*mysql.MySQLError); ok && myErr.Number == 1062 {
+		return ErrUserExists
+	}
+	if err != nil {
+		return wrapMySQLError(err)
+	}
+	user.ID = id
+	return nil
+}
+
+func scanUser(row interface{ Scan(...interface{}) error }) (*User, error) {
+	user := &User{}
+	var id string
+	err := row.Scan(&id, &user.Name, &user.Role, &user.PasswordHash, &user.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	user.ID, err = primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetUser gets user by id
+func (s *MySQLPostStore) GetUser(id primitive.ObjectID) (*User, error) {
+	return scanUser(s.getUser.QueryRow(id.Hex()))
+}
+
+// GetUserByName gets user by name
+func (s *MySQLPostStore) GetUserByName(name string) (*User, error) {
+	return scanUser(s.getUserByName.QueryRow(name))
+}
+
+// ListUsers gets users ordered by name
+func (s *MySQLPostStore) ListUsers() ([]User, error) {
+	rows, err := s.listUsers.Query()
+	if err != nil {
+		return nil, wrapMySQLError(err)
+	}
+	defer rows.Close()
+
+	users := []User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, *user)
+	}
+	return users, rows.Err()
+}
+
+// SetUserRole changes user role
+func (s *MySQLPostStore) SetUserRole(id primitive.ObjectID, role string) error {
+	err := execAffected(s.setUserRole, role, id.Hex())
+	if err == ErrPostNotFound {
+		return ErrUserNotFound
+	}
+	return err
+}
+
+// CreateSession stores session
+func (s *MySQLPostStore) CreateSession(session *Session) error {
+	_, err := s.insertSession.Exec(session.TokenHash, session.UserID.Hex(), session.ExpiresAt)
+	return wrapMySQLError(err)
+}
+
+// GetSession gets session by token hash
+func (s *MySQLPostStore) GetSession(tokenHash string) (*Session, error) {
+	session := &Session{}
+	var userID string
+	err := s.getSession.QueryRow(tokenHash).Scan(&session.TokenHash, &userID, &session.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	session.UserID, err = primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// DeleteSession removes session
+func (s *MySQLPostStore) DeleteSession(tokenHash string) error {
+	err := execAffected(s.deleteSession, tokenHash)
+	if err == ErrPostNotFound {
+		return ErrSessionNotFound
+	}
+	return err
+}
+
+// PurgeSessions removes expired sessions
+func (s *MySQLPostStore) PurgeSessions(before time.Time) error {
+	_, err := s.purgeSessions.Exec(before)
+	return wrapMySQLError(err)
+}
+
+// CreateWebhook stores webhook
+func (s *MySQLPostStore) CreateWebhook(hook *Webhook) error {
+	id := primitive.NewObjectID()
+	_, err := s.insertWebhook.Exec(id.Hex(), hook.URL, hook.Secret, strings.Join(hook.Events, ","), hook.CreatedAt)
+	if err != nil {
+		return wrapMySQLError(err)
+	}
+	hook.ID = id
+	return nil
+}
+
+func scanWebhook(row interface{ Scan(...interface{}) error }) (*Webhook, error) {
+	hook := &Webhook{}
+	var id, events string
+	err := row.Scan(&id, &hook.URL, &hook.Secret, &events, &hook.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	hook.Events = strings.Split(events, ",")
+	hook.ID, err = primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+// GetWebhook gets webhook by id
+func (s *MySQLPostStore) GetWebhook(id primitive.ObjectID) (*Webhook, error) {
+	return scanWebhook(s.getWebhook.QueryRow(id.Hex()))
+}
+
+// ListWebhooks gets webhooks, oldest first
+func (s *MySQLPostStore) ListWebhooks() ([]Webhook, error) {
+	rows, err := s.listWebhooks.Query()
+	if err != nil {
+		return nil, wrapMySQLError(err)
+	}
+	defer rows.Close()
+
+	hooks := []Webhook{}
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, *hook)
+	}
+	return hooks, rows.Err()
+}
+
+// DeleteWebhook removes webhook and its deliveries in one transaction
+func (s *MySQLPostStore) DeleteWebhook(id primitive.ObjectID) error {
+	return s.inTx(func(tx *sql.Tx) error {
+		err := execAffected(tx.Stmt(s.deleteWebhook), id.Hex())
+		if err == ErrPostNotFound {
+			return ErrWebhookNotFound
+		}
+		if err != nil {
+			return err
+		}
+		_, err = tx.Stmt(s.deleteDeliveries).Exec(id.Hex())
+		return wrapMySQLError(err)
+	})
+}
+
+// AddDelivery stores delivery
+func (s *MySQLPostStore) AddDelivery(d *Delivery) error {
+	id := primitive.NewObjectID()
+	_, err := s.insertDelivery.Exec(id.Hex(), d.WebhookID.Hex(), d.Event, d.Payload, d.Status, d.Attempts,
+		d.ResponseStatus, d.LastError, d.NextAttemptAt, d.CreatedAt, d.DeliveredAt)
+	if err != nil {
+		return wrapMySQLError(err)
+	}
+	d.ID = id
+	return nil
+}
+
+func scanDelivery(row interface{ Scan(...interface{}) error }) (*Delivery, error) {
+	d := &Delivery{}
+	var id, webhookID string
+	var deliveredAt sql.NullTime
+	err := row.Scan(&id, &webhookID, &d.Event, &d.Payload, &d.Status, &d.Attempts,
+		&d.ResponseStatus, &d.LastError, &d.NextAttemptAt, &d.CreatedAt, &deliveredAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+	if d.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+		return nil, err
+	}
+	if d.WebhookID, err = primitive.ObjectIDFromHex(webhookID); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// GetDelivery gets delivery by id
+func (s *MySQLPostStore) GetDelivery(id primitive.ObjectID) (*Delivery, error) {
+	return scanDelivery(s.getDelivery.QueryRow(id.Hex()))
+}
+
+// UpdateDelivery saves delivery
+func (s *MySQLPostStore) UpdateDelivery(d *Delivery) error {
+	// clientFoundRows dsn option makes unchanged rows count as affected
+	err := execAffected(s.updateDelivery, d.Status, d.Attempts, d.ResponseStatus, d.LastError,
+		d.NextAttemptAt, d.DeliveredAt, d.ID.Hex())
+	if err == ErrPostNotFound {
+		return ErrDeliveryNotFound
+	}
+	return err
+}
+
+// queryDeliveries reads deliveries selected by statement
+func queryDeliveries(stmt *sql.Stmt, args ...interface{}) ([]Delivery, error) {
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		return nil, wrapMySQLError(err)
+	}
+	defer rows.Close()
+
+	list := []Delivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *d)
+	}
+	return list, rows.Err()
+}
+
+// ListDeliveries gets deliveries of webhook, newest first
+func (s *MySQLPostStore) ListDeliveries(webhookID primitive.ObjectID, limit int) ([]Delivery, error) {
+	return queryDeliveries(s.listDeliveries, webhookID.Hex(), limit)
+}
+
+// ListDueDeliveries gets pending deliveries due at now, served by
+// webhook_deliveries_due index, oldest first
+func (s *MySQLPostStore) ListDueDeliveries(now time.Time, limit int) ([]Delivery, error) {
+	return queryDeliveries(s.listDueDeliveries, now, limit)
+}
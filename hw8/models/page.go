@@ -0,0 +1,159 @@
+package models
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrBadCursor is returned when page cursor can not be decoded
+var ErrBadCursor = errors.New("Bad page cursor")
+
+// Cursor points between two posts in the newest first order,
+// posts are ordered by date and posts of the same date by id.
+// Zero cursor points to the first page.
+type Cursor struct {
+	// Date and ID are of the last post seen by the client
+	Date time.Time
+	ID   primitive.ObjectID
+	// Before selects newer posts than the last one, otherwise older ones
+	Before bool
+}
+
+// cursorKeySize is length of encoded date and id
+const cursorKeySize = 8 + 4 + len(primitive.ObjectID{})
+
+// CursorAt points next to post, before selects posts newer than it
+func CursorAt(post *BlogPost, before bool) Cursor {
+	return Cursor{Date: post.Date, ID: post.ID, Before: before}
+}
+
+// IsZero tells if cursor points to the first page
+func (c Cursor) IsZero() bool {
+	return c.ID.IsZero()
+}
+
+// Follows tells if post comes after cursor position in the newest first order
+func (c Cursor) Follows(post *BlogPost) bool {
+	return compareDateID(post.Date, post.ID, c.Date, c.ID) < 0
+}
+
+// Precedes tells if post comes before cursor position in the newest first order
+func (c Cursor) Precedes(post *BlogPost) bool {
+	return compareDateID(post.Date, post.ID, c.Date, c.ID) > 0
+}
+
+// compareDateID orders posts by date, then by id
+func compareDateID(date1 time.Time, id1 primitive.ObjectID, date2 time.Time, id2 primitive.ObjectID) int {
+	switch {
+	case date1.Before(date2):
+		return -1
+	case date1.After(date2):
+		return 1
+	}
+	return bytes.Compare(id1[:], id2[:])
+}
+
+// String encodes cursor as opaque url safe token
+func (c Cursor) String() string {
+	if c.IsZero() {
+		return ""
+	}
+	b := make([]byte, 0, cursorKeySize+1)
+	if c.Before {
+		b = append(b, 'b')
+	} else {
+		b = append(b, 'a')
+	}
+	b = append(b, dateIDKey(c.Date, c.ID)...)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// ParseCursor decodes token made by Cursor.String, empty token is the first page
+func ParseCursor(s string) (Cursor, error) {
+	c := Cursor{}
+	if s == "" {
+		return c, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) != cursorKeySize+1 || (b[0] != 'a' && b[0] != 'b') {
+		return c, ErrBadCursor
+	}
+	c.Before = b[0] == 'b'
+	c.Date, c.ID = parseDateIDKey(b[1:])
+	return c, nil
+}
+
+// dateIDKey encodes date and id so keys sort bytewise like posts
+// sort by date and id, oldest first
+func dateIDKey(date time.Time, id primitive.ObjectID) []byte {
+	b := make([]byte, cursorKeySize)
+	// flipping sign bit sorts dates before 1970 first
+	binary.BigEndian.PutUint64(b, uint64(date.Unix())^(1<<63))
+	binary.BigEndian.PutUint32(b[8:], uint32(date.Nanosecond()))
+	copy(b[12:], id[:])
+	return b
+}
+
+func parseDateIDKey(b []byte) (time.Time, primitive.ObjectID) {
+	sec := int64(binary.BigEndian.Uint64(b) ^ (1 << 63))
+	nsec := int64(binary.BigEndian.Uint32(b[8:]))
+	var id primitive.ObjectID
+	copy(id[:], b[12:])
+	return time.Unix(sec, nsec).UTC(), id
+}
+
+// Page is a slice of posts with cursors of neighbour pages,
+// empty cursor means there is no such page
+type Page struct {
+	Posts []BlogPost
+	Next  string
+	Prev  string
+}
+
+// ListPage loads page of published posts at cursor token
+func ListPage(store PostStore, cursor string, size int) (*Page, error) {
+	return ListFilteredPage(store, PostFilter{Status: StatusPublished}, cursor, size)
+}
+
+// ListFilteredPage loads page of posts matching filter at cursor token
+func ListFilteredPage(store PostStore, filter PostFilter, cursor string, size int) (*Page, error) {
+	c, err := ParseCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	// one extra post tells if there is a page further in the same direction
+	posts, err := store.ListPostsPage(filter, c, size+1)
+	if err != nil {
+		return nil, err
+	}
+	more := len(posts) > size
+	if more && c.Before {
+		posts = posts[1:]
+	} else if more {
+		posts = posts[:size]
+	}
+
+	page := &Page{Posts: posts}
+	if len(posts) == 0 {
+		return page, nil
+	}
+	if more || (c.Before && !c.IsZero()) {
+		page.Next = CursorAt(&posts[len(posts)-1], false).String()
+	}
+	if (more && c.Before) || (!c.Before && !c.IsZero()) {
+		page.Prev = CursorAt(&posts[0], true).String()
+	}
+	return page, nil
+}
+
+func reversePosts(posts []BlogPost) {
+	for i, j := 0, len(posts)-1; i < j; i, j = i+1, j-1 {
+		posts[i], posts[j] = posts[j], posts[i]
+	}
+}
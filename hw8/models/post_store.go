@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrPostNotFound is returned when no post matches the requested id
+var ErrPostNotFound = errors.New("Post not found")
+
+// ErrVersionConflict is returned when post was changed since it was read
+var ErrVersionConflict = errors.New("Post was changed by someone else")
+
+// PostStore is a storage backend for blog posts.
+// Deleted posts are moved to trash and are not visible
+// through ListPosts, GetPost and UpdatePost until restored.
+// Tag and category lists return only published posts.
+type PostStore interface {
+	// ListPosts returns all posts of any status
+	ListPosts() ([]BlogPost, error)
+	// ListPostsPage returns up to limit posts matching filter next to cursor, newest first
+	ListPostsPage(filter PostFilter, cursor Cursor, limit int) ([]BlogPost, error)
+	// ListPostsByTag returns published posts having tag, latest date first
+	ListPostsByTag(tag string) ([]BlogPost, error)
+	// ListPostsByCategory returns published posts in category, latest date first
+	ListPostsByCategory(category string) ([]BlogPost, error)
+	// ListPostsBetween returns published posts dated in [from, to), latest date first
+	ListPostsBetween(from, to time.Time) ([]BlogPost, error)
+	// ListTags returns tags of published posts with post counts, ordered by name
+	ListTags() ([]TagCount, error)
+	// ListDrafts returns draft and scheduled posts of author, newest first
+	ListDrafts(authorID primitive.ObjectID) ([]BlogPost, error)
+	// PublishDue publishes scheduled posts with publish time not after now
+	// and returns them
+	PublishDue(now time.Time) ([]BlogPost, error)
+	// GetPost returns post by id
+	GetPost(id primitive.ObjectID) (*BlogPost, error)
+	// GetPostBySlug returns post by slug
+	GetPostBySlug(slug string) (*BlogPost, error)
+	// CreatePost stores new post and sets its id and created and updated times,
+	// post without slug gets one from title, taken slugs get numbered suffix
+	CreatePost(post *BlogPost) error
+	// UpdatePost updates stored post if its version equals post version,
+	// then increments post version, sets updated time and reads created time.
+	// Empty slug keeps stored one, taken slugs get numbered suffix.
+	UpdatePost(post *BlogPost) error
+	// DeletePost moves post to trash
+	DeletePost(id primitive.ObjectID) error
+
+	// ListTrash returns deleted posts
+	ListTrash() ([]BlogPost, error)
+	// RestorePost moves post from trash back
+	RestorePost(id primitive.ObjectID) error
+	// PurgePost removes post from trash permanently
+	PurgePost(id primitive.ObjectID) error
+	// PurgeTrash removes posts deleted before time and returns their count
+	PurgeTrash(before time.Time) (int, error)
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrRevisionNotFound is returned when post has no requested revision
+var ErrRevisionNotFound = errors.New("Revision not found")
+
+// Revision is an immutable copy of post content saved on every change
+type Revision struct {
+	PostID       primitive.ObjectID
+	Number       int
+	Title        string
+	Date         time.Time
+	Link         string
+	Content      string
+	Editor       string
+	RestoredFrom int `bson:",omitempty"`
+	CreatedAt    time.Time
+}
+
+// bsonRevision has Revision fields without its bson hooks
+type bsonRevision Revision
+
+// UnmarshalBSON reads revision, string dates of old revisions are parsed
+func (r *Revision) UnmarshalBSON(data []byte) error {
+	data, err := upgradeDate(data)
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(data, (*bsonRevision)(r))
+}
+
+// RevisionStore keeps post revisions
+type RevisionStore interface {
+	// AddRevision stores revision and sets its next number for the post
+	AddRevision(rev *Revision) error
+	// ListRevisions returns post revisions, newest first
+	ListRevisions(postID primitive.ObjectID) ([]Revision, error)
+	// GetRevision returns post revision by number
+	GetRevision(postID primitive.ObjectID, number int) (*Revision, error)
+}
+
+// Store is everything blog needs from a storage backend
+type Store interface {
+	PostStore
+	RevisionStore
+	CommentStore
+	UserStore
+	WebhookStore
+}
+
+// NewRevision makes revision of current post content
+func NewRevision(post *BlogPost, editor string) *Revision {
+	return &Revision{
+		PostID:    post.ID,
+		Title:     post.Title,
+		Date:      post.Date,
+		Link:      post.Link,
+		Content:   post.Content,
+		Editor:    editor,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Apply copies revision content to post
+func (r *Revision) Apply(post *BlogPost) {
+	post.Title = r.Title
+	post.Date = r.Date
+	post.Link = r.Link
+	post.Content = r.Content
+}
+
+// UpdatePostWithRevision updates post and records the new content as revision.
+// Posts created before revisions existed get their stored content saved first,
+// so no text is ever lost. Post without slug gets one, see nextSlug.
+func UpdatePostWithRevision(store Store, post *BlogPost, editor string) (*Revision, error) {
+	stored, err := store.GetPost(post.ID)
+	if err != nil {
+		return nil, err
+	}
+	if post.Slug == "" {
+		post.Slug = nextSlug(stored, post)
+	}
+
+	revs, err := store.ListRevisions(post.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(revs) == 0 {
+		if err := store.AddRevision(NewRevision(stored, "")); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := store.UpdatePost(post); err != nil {
+		return nil, err
+	}
+
+	rev := NewRevision(post, editor)
+	if err := store.AddRevision(rev); err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
+
+// CreatePostWithRevision creates post and records its first revision
+func CreatePostWithRevision(store Store, post *BlogPost, editor string) error {
+	if err := store.CreatePost(post); err != nil {
+		return err
+	}
+	return store.AddRevision(NewRevision(post, editor))
+}
+
+// RestoreRevision makes old revision content current as a new revision
+func RestoreRevision(store Store, postID primitive.ObjectID, number int, editor string) (*Revision, error) {
+	old, err := store.GetRevision(postID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := store.GetPost(postID)
+	if err != nil {
+		return nil, err
+	}
+	old.Apply(post)
+
+	if err := store.UpdatePost(post); err != nil {
+		return nil, err
+	}
+
+	rev := NewRevision(post, editor)
+	rev.RestoredFrom = number
+	if err := store.AddRevision(rev); err != nil {
+		return nil, err
+	}
+	return rev, nil
+}
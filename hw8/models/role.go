@@ -0,0 +1,65 @@
+package models
+
+import (
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// user roles, each role can do everything the previous one can
+const (
+	// RoleAuthor writes posts and edits only own posts
+	RoleAuthor = "author"
+	// RoleEditor edits any post and manages trash
+	RoleEditor = "editor"
+	// RoleAdmin also manages users
+	RoleAdmin = "admin"
+)
+
+// Roles lists roles from least to most powerful
+var Roles = []string{RoleAuthor, RoleEditor, RoleAdmin}
+
+// ErrForbidden is returned when user role does not allow an action
+var ErrForbidden = errors.New("Action is not allowed")
+
+// ErrBadRole is returned when role is not one of Roles
+var ErrBadRole = errors.New("Unknown role")
+
+func roleRank(role string) int {
+	for i, r := range Roles {
+		if r == role {
+			return i
+		}
+	}
+	// users stored before roles existed are authors
+	return 0
+}
+
+// HasRole tells if user has role or a more powerful one
+func (u *User) HasRole(role string) bool {
+	return u != nil && roleRank(u.Role) >= roleRank(role)
+}
+
+// CanEditPost tells if user can change post,
+// authors can only change their own posts
+func (u *User) CanEditPost(post *BlogPost) bool {
+	if u == nil {
+		return false
+	}
+	return u.HasRole(RoleEditor) || (!post.AuthorID.IsZero() && post.AuthorID == u.ID)
+}
+
+// ChangeRole sets user role, admins can not change their own role
+// so there is always an admin left
+func ChangeRole(store UserStore, admin *User, userID primitive.ObjectID, role string) error {
+	if !admin.HasRole(RoleAdmin) || admin.ID == userID {
+		return ErrForbidden
+	}
+	valid := false
+	for _, r := range Roles {
+		valid = valid || r == role
+	}
+	if !valid {
+		return ErrBadRole
+	}
+	return store.SetUserRole(userID, role)
+}
@@ -0,0 +1,111 @@
+package models
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// ErrSlugTaken is returned by stores when another post has the slug
+var ErrSlugTaken = errors.New("Slug is taken by another post")
+
+// maxSlugLength keeps permalinks readable, suffixes may add a few more
+const maxSlugLength = 60
+
+// maxSlugTries bounds numbered suffixes tried for a taken slug,
+// then random suffixes are tried as many times
+const maxSlugTries = 10
+
+// translit spells cyrillic letters in latin, letters missing here
+// and not in ascii are treated as separators
+var translit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya", 'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g",
+}
+
+// Slugify makes url path segment of title: lower case latin letters,
+// digits and single dashes, titles without any give "post"
+func Slugify(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if s, ok := translit[r]; ok {
+			if s != "" {
+				b.WriteString(s)
+				dash = false
+			}
+			continue
+		}
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+			dash = false
+			continue
+		}
+		if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	slug := b.String()
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+		// cut at word end when there is one
+		if i := strings.LastIndexByte(slug, '-'); i > maxSlugLength/2 {
+			slug = slug[:i]
+		}
+	}
+	slug = strings.Trim(slug, "-")
+	if slug == "" {
+		return "post"
+	}
+	return slug
+}
+
+// Permalink is post path with year and month of its date in loc,
+// posts created before slugs existed are linked by id
+func (p *BlogPost) Permalink(loc *time.Location) string {
+	if p.Slug == "" {
+		return "/post?id=" + p.ID.Hex()
+	}
+	t := p.Date.In(loc)
+	return fmt.Sprintf("/posts/%04d/%02d/%s", t.Year(), int(t.Month()), p.Slug)
+}
+
+// nextSlug picks slug for updated post: published posts keep their
+// permalink, drafts follow their title until published
+func nextSlug(stored, post *BlogPost) string {
+	if stored.Slug != "" && stored.IsPublished() {
+		return stored.Slug
+	}
+	return Slugify(post.Title)
+}
+
+// saveWithUniqueSlug calls save, adding -2, -3... to post slug
+// while save reports the slug is taken. Slugs shared by many posts,
+// like untitled drafts, get a random suffix after maxSlugTries
+// so saving does not probe every number taken before.
+func saveWithUniqueSlug(post *BlogPost, save func() error) error {
+	base := post.Slug
+	err := save()
+	for n := 2; errors.Cause(err) == ErrSlugTaken && n <= maxSlugTries; n++ {
+		post.Slug = fmt.Sprintf("%s-%d", base, n)
+		err = save()
+	}
+	for n := 0; errors.Cause(err) == ErrSlugTaken && n < maxSlugTries; n++ {
+		suffix := make([]byte, 4)
+		if _, err := rand.Read(suffix); err != nil {
+			return errors.Wrap(err, "Can not make slug suffix")
+		}
+		post.Slug = fmt.Sprintf("%s-%x", base, suffix)
+		err = save()
+	}
+	return err
+}
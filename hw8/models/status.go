@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"github.com/astaxie/beego"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// post statuses, only published posts are shown to readers
+const (
+	// StatusDraft is seen only by its author and editors
+	StatusDraft = "draft"
+	// StatusScheduled is published by scheduler at PublishAt
+	StatusScheduled = "scheduled"
+	// StatusPublished is shown in lists, search and tags
+	StatusPublished = "published"
+)
+
+// Statuses lists post statuses in lifecycle order
+var Statuses = []string{StatusDraft, StatusScheduled, StatusPublished}
+
+var (
+	// ErrBadStatus is returned when status is not one of Statuses
+	ErrBadStatus = errors.New("Unknown post status")
+	// ErrNoPublishTime is returned when scheduled post has no publish time
+	ErrNoPublishTime = errors.New("Scheduled post needs publish time")
+)
+
+// IsPublished tells if readers can see post,
+// posts stored before statuses existed have none and are published
+func (p *BlogPost) IsPublished() bool {
+	return p.Status == "" || p.Status == StatusPublished
+}
+
+// SetStatus moves post to status, publishAt is required for scheduled posts,
+// published posts keep their publish time or get current one
+func (p *BlogPost) SetStatus(status string, publishAt *time.Time) error {
+	switch status {
+	case StatusDraft:
+		p.PublishAt = nil
+	case StatusScheduled:
+		if publishAt == nil {
+			return ErrNoPublishTime
+		}
+		p.PublishAt = publishAt
+	case StatusPublished:
+		if publishAt == nil {
+			now := time.Now()
+			publishAt = &now
+		}
+		p.PublishAt = publishAt
+	default:
+		return ErrBadStatus
+	}
+	p.Status = status
+	return nil
+}
+
+// isDue tells if scheduled post should be published at now
+func (p *BlogPost) isDue(now time.Time) bool {
+	return p.Status == StatusScheduled && p.PublishAt != nil && !p.PublishAt.After(now)
+}
+
+// draftsOf keeps unpublished posts of author, newest first
+func draftsOf(posts []BlogPost, authorID primitive.ObjectID) []BlogPost {
+	return selectPosts(posts, func(p *BlogPost) bool {
+		return !p.IsPublished() && p.AuthorID == authorID
+	})
+}
+
+// PublishScheduledLoop publishes scheduled posts when their time comes,
+// checking every interval, and calls published for each of them.
+// It never returns, run it in goroutine.
+func PublishScheduledLoop(store PostStore, interval time.Duration, published func(post BlogPost)) {
+	for {
+		posts, err := store.PublishDue(time.Now())
+		if err != nil {
+			beego.Error("Can not publish scheduled posts:", err)
+		}
+		for _, p := range posts {
+			beego.Info("Published scheduled post:", p.Title)
+			if published != nil {
+				published(p)
+			}
+		}
+		time.Sleep(interval)
+	}
+}
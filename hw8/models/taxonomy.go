@@ -0,0 +1,114 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// maxTagLength is the longest tag or category name in runes
+const maxTagLength = 64
+
+// TagCount is a tag with number of posts having it
+type TagCount struct {
+	Name  string
+	Count int
+	// Weight is tag cloud size from 1 to 5, see TagCloud
+	Weight int
+}
+
+// NormalizeTag makes tag or category name lower case
+// with single dashes instead of spaces
+func NormalizeTag(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return unicode.IsSpace(r) || r == '-' || r == '/' || r == ','
+	})
+	tag := []rune(strings.Join(words, "-"))
+	if len(tag) > maxTagLength {
+		tag = tag[:maxTagLength]
+	}
+	return string(tag)
+}
+
+// ParseTags splits comma separated tags, normalizes them
+// and returns sorted tags without duplicates
+func ParseTags(s string) []string {
+	seen := map[string]bool{}
+	tags := []string{}
+	for _, t := range strings.Split(s, ",") {
+		tag := NormalizeTag(t)
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// HasTag tells if post has tag
+func (p *BlogPost) HasTag(tag string) bool {
+	for _, t := range p.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// TagList is comma separated post tags for tag editor
+func (p *BlogPost) TagList() string {
+	return strings.Join(p.Tags, ", ")
+}
+
+// TagCloud sets tag weights from 1 to 5 by post count
+// on a log scale, so a few popular tags do not flatten the rest
+func TagCloud(tags []TagCount) []TagCount {
+	max := 1
+	for _, t := range tags {
+		if t.Count > max {
+			max = t.Count
+		}
+	}
+	for i := range tags {
+		tags[i].Weight = 1 + int(4*logRatio(tags[i].Count, max))
+	}
+	return tags
+}
+
+func logRatio(n, max int) float64 {
+	if max <= 1 || n <= 1 {
+		return 0
+	}
+	return math.Log(float64(n)) / math.Log(float64(max))
+}
+
+// countTags counts tags of posts
+func countTags(posts []BlogPost) []TagCount {
+	counts := map[string]int{}
+	for _, p := range posts {
+		for _, t := range p.Tags {
+			counts[t]++
+		}
+	}
+	tags := make([]TagCount, 0, len(counts))
+	for name, n := range counts {
+		tags = append(tags, TagCount{Name: name, Count: n})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+	return tags
+}
+
+// selectPosts returns posts matching filter, newest first
+func selectPosts(posts []BlogPost, match func(p *BlogPost) bool) []BlogPost {
+	selected := []BlogPost{}
+	for i := len(posts) - 1; i >= 0; i-- {
+		if match(&posts[i]) {
+			selected = append(selected, posts[i])
+		}
+	}
+	return selected
+}
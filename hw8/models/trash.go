@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/astaxie/beego"
+)
+
+// PurgeTrashLoop removes posts that stayed in trash longer than retention,
+// checking every interval. It never returns, run it in goroutine.
+func PurgeTrashLoop(store PostStore, retention, interval time.Duration) {
+	for {
+		n, err := store.PurgeTrash(time.Now().Add(-retention))
+		if err != nil {
+			beego.Error("Can not purge trash:", err)
+		} else if n > 0 {
+			beego.Info("Purged posts from trash:", n)
+		}
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,205 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"regexp"
+	"time"
+
+	"github.com/astaxie/beego"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrUserNotFound is returned when no user matches the requested id or name
+	ErrUserNotFound = errors.New("User not found")
+	// ErrUserExists is returned when registering a taken user name
+	ErrUserExists = errors.New("User name is already taken")
+	// ErrBadCredentials is returned when login name or password is wrong
+	ErrBadCredentials = errors.New("Wrong user name or password")
+	// ErrBadUserName is returned when user name has wrong length or letters
+	ErrBadUserName = errors.New("User name must be 3 to 32 letters, digits, _ or -")
+	// ErrWeakPassword is returned when password is too short or too long
+	ErrWeakPassword = errors.New("Password must be 8 to 72 characters")
+	// ErrSessionNotFound is returned when session token is unknown or expired
+	ErrSessionNotFound = errors.New("Session not found")
+)
+
+var userNamePattern = regexp.MustCompile(`^[\p{L}\p{N}_-]{3,32}$`)
+
+// User is a registered blog user
+type User struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	Name         string
+	Role         string
+	PasswordHash []byte
+	CreatedAt    time.Time
+}
+
+// Session is a logged in user session, the token itself
+// is only known to the client, store keeps its sha256 hash
+type Session struct {
+	TokenHash string
+	UserID    primitive.ObjectID
+	ExpiresAt time.Time
+}
+
+// UserStore keeps users and their sessions
+type UserStore interface {
+	// CreateUser stores new user and sets its id, user names are unique
+	CreateUser(user *User) error
+	// GetUser returns user by id
+	GetUser(id primitive.ObjectID) (*User, error)
+	// GetUserByName returns user by name
+	GetUserByName(name string) (*User, error)
+	// ListUsers returns all users ordered by name
+	ListUsers() ([]User, error)
+	// SetUserRole changes user role
+	SetUserRole(id primitive.ObjectID, role string) error
+
+	// CreateSession stores session
+	CreateSession(session *Session) error
+	// GetSession returns session by token hash
+	GetSession(tokenHash string) (*Session, error)
+	// DeleteSession removes session by token hash
+	DeleteSession(tokenHash string) error
+	// PurgeSessions removes sessions expired before time
+	PurgeSessions(before time.Time) error
+}
+
+// dummyHash is compared when user is not found,
+// so login takes the same time for unknown names
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+
+// Register creates user with bcrypt hashed password,
+// signed up users are always authors
+func Register(store UserStore, name, password string) (*User, error) {
+	return createUser(store, name, password, RoleAuthor)
+}
+
+// EnsureAdmin creates admin user with name unless it exists. It is called
+// at start with configured admin, so admins never come from public signup.
+// A taken name of a user who is not admin is an error.
+func EnsureAdmin(store UserStore, name, password string) (*User, error) {
+	user, err := store.GetUserByName(name)
+	if err == ErrUserNotFound {
+		return createUser(store, name, password, RoleAdmin)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !user.HasRole(RoleAdmin) {
+		return nil, errors.Errorf("User %v exists and is not admin", name)
+	}
+	return user, nil
+}
+
+// createUser stores user with role, the role is set when user is created
+func createUser(store UserStore, name, password, role string) (*User, error) {
+	if !userNamePattern.MatchString(name) {
+		return nil, ErrBadUserName
+	}
+	// bcrypt uses only first 72 bytes
+	if len(password) < 8 || len(password) > 72 {
+		return nil, ErrWeakPassword
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &User{Name: name, Role: role, PasswordHash: hash, CreatedAt: time.Now()}
+	if err := store.CreateUser(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Login checks user name and password
+func Login(store UserStore, name, password string) (*User, error) {
+	user, err := store.GetUserByName(name)
+	if err == ErrUserNotFound {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return nil, ErrBadCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	if bcrypt.CompareHashAndPassword(user.PasswordHash, []byte(password)) != nil {
+		return nil, ErrBadCredentials
+	}
+	return user, nil
+}
+
+// hashToken is the session key kept in store
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomToken is 32 random bytes encoded for cookies
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// StartSession creates session for user and returns its secret token
+func StartSession(store UserStore, userID primitive.ObjectID, ttl time.Duration) (string, error) {
+	token, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+
+	session := &Session{TokenHash: hashToken(token), UserID: userID, ExpiresAt: time.Now().Add(ttl)}
+	if err := store.CreateSession(session); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// SessionUser returns user of session token
+func SessionUser(store UserStore, token string) (*User, error) {
+	if token == "" {
+		return nil, ErrSessionNotFound
+	}
+	session, err := store.GetSession(hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return nil, ErrSessionNotFound
+	}
+	user, err := store.GetUser(session.UserID)
+	if err == ErrUserNotFound {
+		return nil, ErrSessionNotFound
+	}
+	return user, err
+}
+
+// EndSession removes session of token
+func EndSession(store UserStore, token string) error {
+	err := store.DeleteSession(hashToken(token))
+	if err == ErrSessionNotFound {
+		return nil
+	}
+	return err
+}
+
+// PurgeSessionsLoop removes expired sessions every interval.
+// It never returns, run it in goroutine.
+func PurgeSessionsLoop(store UserStore, interval time.Duration) {
+	for {
+		if err := store.PurgeSessions(time.Now()); err != nil {
+			beego.Error("Can not purge sessions:", err)
+		}
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,358 @@
+package models
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/astaxie/beego"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// webhook events, they are sent only about posts readers can see
+// or could see before the change, drafts and scheduled posts stay private
+const (
+	// EventPostCreated is sent when post is created published
+	EventPostCreated = "post.created"
+	// EventPostUpdated is sent when published post is changed,
+	// also by restoring its old revision
+	EventPostUpdated = "post.updated"
+	// EventPostPublished is sent when draft or scheduled post is published,
+	// by its editor or by the scheduler
+	EventPostPublished = "post.published"
+	// EventPostUnpublished is sent when published post becomes draft or scheduled
+	EventPostUnpublished = "post.unpublished"
+	// EventPostDeleted is sent when published post is moved to trash
+	EventPostDeleted = "post.deleted"
+	// EventPostRestored is sent when published post is restored from trash
+	EventPostRestored = "post.restored"
+)
+
+// WebhookEvents lists events webhooks can subscribe to
+var WebhookEvents = []string{EventPostCreated, EventPostUpdated, EventPostPublished,
+	EventPostUnpublished, EventPostDeleted, EventPostRestored}
+
+// PostChangeEvent is webhook event of saving post before as after,
+// before is nil for new posts. It is empty when readers could see
+// neither of them.
+func PostChangeEvent(before, after *BlogPost) string {
+	was := before != nil && before.IsPublished()
+	switch {
+	case was && after.IsPublished():
+		return EventPostUpdated
+	case was:
+		return EventPostUnpublished
+	case !after.IsPublished():
+		return ""
+	case before == nil:
+		return EventPostCreated
+	}
+	return EventPostPublished
+}
+
+// delivery statuses
+const (
+	// DeliveryPending waits for its next attempt
+	DeliveryPending = "pending"
+	// DeliveryDone was accepted by receiver
+	DeliveryDone = "delivered"
+	// DeliveryFailed ran out of attempts
+	DeliveryFailed = "failed"
+)
+
+// headers of webhook requests
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	// WebhookTimestampHeader is unix time of the attempt, it is signed
+	// with the body so receivers can reject replayed requests
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+)
+
+// delivery retries, attempt n waits webhookRetryBase * 2^(n-1)
+// but not longer than webhookRetryMax
+const (
+	webhookMaxAttempts = 8
+	webhookRetryBase   = time.Minute
+	webhookRetryMax    = 6 * time.Hour
+	// webhookBatch is deliveries sent in one run
+	webhookBatch = 100
+)
+
+var (
+	// ErrWebhookNotFound is returned when no webhook has requested id
+	ErrWebhookNotFound = errors.New("Webhook not found")
+	// ErrDeliveryNotFound is returned when no delivery has requested id
+	ErrDeliveryNotFound = errors.New("Delivery not found")
+	// ErrBadWebhook is returned when webhook url or events are wrong
+	ErrBadWebhook = errors.New("Webhook needs http or https url and at least one known event")
+)
+
+// Webhook is receiver url getting events it subscribed to,
+// requests are signed with its secret
+type Webhook struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	URL       string
+	Secret    string
+	Events    []string
+	CreatedAt time.Time
+}
+
+// Wants tells if webhook subscribed to event
+func (h *Webhook) Wants(event string) bool {
+	for _, e := range h.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery is one event sent to one webhook, it is kept after sending
+// so admins can see what receivers got
+type Delivery struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	WebhookID primitive.ObjectID
+	Event     string
+	// Payload is json body sent as is on every attempt
+	Payload        string
+	Status         string
+	Attempts       int
+	ResponseStatus int
+	LastError      string
+	NextAttemptAt  time.Time
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+// WebhookStore keeps webhooks and queue of their deliveries
+type WebhookStore interface {
+	// CreateWebhook stores webhook and sets its id
+	CreateWebhook(hook *Webhook) error
+	// GetWebhook returns webhook by id
+	GetWebhook(id primitive.ObjectID) (*Webhook, error)
+	// ListWebhooks returns webhooks, oldest first
+	ListWebhooks() ([]Webhook, error)
+	// DeleteWebhook removes webhook with its deliveries
+	DeleteWebhook(id primitive.ObjectID) error
+
+	// AddDelivery stores delivery and sets its id
+	AddDelivery(d *Delivery) error
+	// GetDelivery returns delivery by id
+	GetDelivery(id primitive.ObjectID) (*Delivery, error)
+	// UpdateDelivery saves delivery status after attempt
+	UpdateDelivery(d *Delivery) error
+	// ListDeliveries returns up to limit deliveries of webhook, newest first
+	ListDeliveries(webhookID primitive.ObjectID, limit int) ([]Delivery, error)
+	// ListDueDeliveries returns up to limit pending deliveries
+	// whose next attempt is not after now, oldest first
+	ListDueDeliveries(now time.Time, limit int) ([]Delivery, error)
+}
+
+// AddWebhook checks url and events and stores webhook with new secret
+func AddWebhook(store WebhookStore, rawURL string, events []string) (*Webhook, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(events) == 0 {
+		return nil, ErrBadWebhook
+	}
+	hook := &Webhook{URL: u.String(), CreatedAt: time.Now()}
+	for _, e := range events {
+		known := false
+		for _, k := range WebhookEvents {
+			known = known || k == e
+		}
+		if !known {
+			return nil, ErrBadWebhook
+		}
+		if !hook.Wants(e) {
+			hook.Events = append(hook.Events, e)
+		}
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	hook.Secret = base64.RawURLEncoding.EncodeToString(b)
+	if err := store.CreateWebhook(hook); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+// WebhookPayload is json body of webhook request
+type WebhookPayload struct {
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// QueueEvent queues delivery of event to every webhook subscribed to it,
+// data is marshaled to json once so all receivers get the same body
+func QueueEvent(store WebhookStore, event string, data interface{}) error {
+	hooks, err := store.ListWebhooks()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	var payload []byte
+	for i := range hooks {
+		if !hooks[i].Wants(event) {
+			continue
+		}
+		if payload == nil {
+			payload, err = json.Marshal(WebhookPayload{Event: event, CreatedAt: now, Data: data})
+			if err != nil {
+				return errors.Wrap(err, "Can not encode webhook payload")
+			}
+		}
+		d := &Delivery{
+			WebhookID:     hooks[i].ID,
+			Event:         event,
+			Payload:       string(payload),
+			Status:        DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		}
+		if err := store.AddDelivery(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Redeliver queues new delivery with payload of delivery,
+// the old one stays in the log as it is
+func Redeliver(store WebhookStore, id primitive.ObjectID) (*Delivery, error) {
+	old, err := store.GetDelivery(id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	d := &Delivery{
+		WebhookID:     old.WebhookID,
+		Event:         old.Event,
+		Payload:       old.Payload,
+		Status:        DeliveryPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+	}
+	if err := store.AddDelivery(d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// SignWebhook is signature header value of body sent at timestamp,
+// hex HMAC-SHA256 of timestamp, a dot and body with webhook secret
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookBackoff is wait after failed attempt number n
+func WebhookBackoff(n int) time.Duration {
+	wait := webhookRetryBase
+	for i := 1; i < n && wait < webhookRetryMax; i++ {
+		wait *= 2
+	}
+	if wait > webhookRetryMax {
+		wait = webhookRetryMax
+	}
+	return wait
+}
+
+// send makes one attempt of delivery and sets its new status,
+// receiver must answer with 2xx status
+func (d *Delivery) send(client *http.Client, hook *Webhook, now time.Time) {
+	d.Attempts++
+	req, err := http.NewRequest("POST", hook.URL, strings.NewReader(d.Payload))
+	if err == nil {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(WebhookEventHeader, d.Event)
+		req.Header.Set(WebhookDeliveryHeader, d.ID.Hex())
+		req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(now.Unix(), 10))
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(hook.Secret, now.Unix(), []byte(d.Payload)))
+		var resp *http.Response
+		resp, err = client.Do(req)
+		if err == nil {
+			d.ResponseStatus = resp.StatusCode
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+			if resp.StatusCode < 200 || resp.StatusCode > 299 {
+				err = errors.Errorf("Receiver answered %v", resp.Status)
+			}
+		}
+	}
+
+	if err == nil {
+		d.Status = DeliveryDone
+		d.LastError = ""
+		d.DeliveredAt = &now
+		return
+	}
+	d.LastError = err.Error()
+	if d.Attempts >= webhookMaxAttempts {
+		d.Status = DeliveryFailed
+		return
+	}
+	d.NextAttemptAt = now.Add(WebhookBackoff(d.Attempts))
+}
+
+// DeliverDue sends deliveries due at now and returns how many were accepted
+func DeliverDue(store WebhookStore, client *http.Client, now time.Time) (int, error) {
+	due, err := store.ListDueDeliveries(now, webhookBatch)
+	if err != nil {
+		return 0, err
+	}
+	hooks := map[primitive.ObjectID]*Webhook{}
+	done := 0
+	for i := range due {
+		d := &due[i]
+		hook, ok := hooks[d.WebhookID]
+		if !ok {
+			hook, err = store.GetWebhook(d.WebhookID)
+			if err != nil && err != ErrWebhookNotFound {
+				return done, err
+			}
+			hooks[d.WebhookID] = hook
+		}
+		if hook == nil {
+			d.Status = DeliveryFailed
+			d.LastError = ErrWebhookNotFound.Error()
+		} else {
+			d.send(client, hook, now)
+		}
+		if err := store.UpdateDelivery(d); err != nil {
+			return done, err
+		}
+		if d.Status == DeliveryDone {
+			done++
+		}
+	}
+	return done, nil
+}
+
+// DeliverWebhooksLoop sends due deliveries every interval.
+// It never returns, run it in goroutine.
+func DeliverWebhooksLoop(store WebhookStore, client *http.Client, interval time.Duration) {
+	for {
+		if _, err := DeliverDue(store, client, time.Now()); err != nil {
+			beego.Error("Can not deliver webhooks:", err)
+		}
+		time.Sleep(interval)
+	}
+}
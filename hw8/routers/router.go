@@ -1,35 +1,143 @@
 package routers
 
 import (
+	"common/boltstore"
 	ctx "context"
+	"database/sql"
 	"hw8/controllers"
+	"hw8/models"
+	"hw8/search"
 	"log"
+	"net"
+	"net/http"
+	"time"
 
 	"github.com/astaxie/beego"
+	// sql driver
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"google.golang.org/grpc"
 )
 
+// grpcServer serves the same store and index as web routes
+var grpcServer *grpc.Server
+
 func init() {
-	beego.Info("Starting db")
-	dbURI := beego.AppConfig.String("dbUri")
-	db, err := mongo.NewClient(options.Client().ApplyURI(dbURI))
+	store, err := newStore(beego.AppConfig.DefaultString("storeType", "bolt"))
 	if err != nil {
 		beego.Critical(err)
 		log.Fatal(err)
 	}
 
-	err = db.Connect(ctx.TODO())
+	if name := beego.AppConfig.String("adminName"); name != "" {
+		if _, err := models.EnsureAdmin(store, name, beego.AppConfig.String("adminPassword")); err != nil {
+			err = errors.Wrap(err, "Can not create admin user")
+			beego.Critical(err)
+			log.Fatal(err)
+		}
+	}
+
+	posts, err := store.ListPosts()
 	if err != nil {
 		beego.Critical(err)
 		log.Fatal(err)
 	}
+	index := search.NewIndex()
+	index.Rebuild(posts)
+	beego.Info("Indexed posts for search:", len(posts))
 
-	dbName := beego.AppConfig.String("dbName")
-	controller := &controllers.MainController{DB: db, DBName: dbName}
+	controller := &controllers.MainController{Store: store, Index: index}
+	beego.InsertFilter("/*", beego.BeforeRouter, controllers.AuthFilter(store))
 	beego.Router("/", controller, "get:ListPosts")
 	beego.Router("/post", controller, "get:ReadPost")
+	beego.Router("/posts/:year:int/:month:int/:slug", controller, "get:ReadPostBySlug")
 	beego.Router("/edit", controller, "get:EditPost")
 	beego.Router("/edit", controller, "post:UpdatePost")
 	beego.Router("/new", controller, "post:NewPost")
+	beego.Router("/drafts", controller, "get:ListDrafts")
+	beego.Router("/preview", controller, "post:PreviewPost")
+	beego.Router("/login", controller, "get:ShowLogin;post:Login")
+	beego.Router("/register", controller, "get:ShowRegister;post:Register")
+	beego.Router("/logout", controller, "post:Logout")
+	beego.Router("/search", controller, "get:SearchPosts")
+	beego.Router("/tag/:name", controller, "get:ListByTag")
+	beego.Router("/tag/:name/feed.xml", controller, "get:ShowTagRSS")
+	beego.Router("/tag/:name/atom.xml", controller, "get:ShowTagAtom")
+	beego.Router("/feed.xml", controller, "get:ShowRSS")
+	beego.Router("/atom.xml", controller, "get:ShowAtom")
+	beego.Router("/category/:name", controller, "get:ListByCategory")
+	beego.Router("/archive/:year:int/:month:int", controller, "get:ListArchive")
+	beego.Router("/tags", controller, "get:SuggestTags")
+	beego.Router("/post/comment", controller, "post:AddComment")
+	beego.Router("/post/history", controller, "get:ShowHistory")
+	beego.Router("/post/history/restore", controller, "post:RestoreRevision")
+	beego.Router("/delete", controller, "post:DeletePost")
+	beego.Router("/trash", controller, "get:ShowTrash")
+	beego.Router("/trash/restore", controller, "post:RestorePost")
+	beego.Router("/trash/purge", controller, "post:PurgePost")
+	beego.Router("/admin/users", controller, "get:ListUsers")
+	beego.Router("/admin/users/role", controller, "post:SetUserRole")
+	beego.Router("/admin/webhooks", controller, "get:ListWebhooks;post:AddWebhook")
+	beego.Router("/admin/webhooks/delete", controller, "post:DeleteWebhook")
+	beego.Router("/admin/webhooks/deliveries", controller, "get:ShowDeliveries")
+	beego.Router("/admin/webhooks/redeliver", controller, "post:RedeliverWebhook")
+	beego.Router("/api/v1/posts", controller, "get:APIListPosts;post:APICreatePost")
+	beego.Router("/api/v1/posts/:id", controller, "get:APIGetPost;put:APIUpdatePost;delete:APIDeletePost")
+	beego.Router("/api/v1/session", controller, "post:APILogin;delete:APILogout")
+	beego.Router("/api/openapi.json", controller, "get:ShowAPISpec")
+	beego.Router("/api/docs", controller, "get:ShowAPIDocs")
+	beego.Router("/graphql", controller, "get,post:GraphQL")
+	grpcServer = controllers.NewGRPCServer(store, index)
+
+	retention := beego.AppConfig.DefaultInt("trashRetentionDays", 30)
+	go models.PurgeTrashLoop(store, time.Duration(retention)*24*time.Hour, time.Hour)
+	go models.PurgeSessionsLoop(store, time.Hour)
+	publishInterval := time.Duration(beego.AppConfig.DefaultInt("publishIntervalSeconds", 60)) * time.Second
+	go models.PublishScheduledLoop(store, publishInterval, controller.Published)
+	webhookInterval := time.Duration(beego.AppConfig.DefaultInt("webhookIntervalSeconds", 10)) * time.Second
+	webhookClient := &http.Client{Timeout: time.Duration(beego.AppConfig.DefaultInt("webhookTimeoutSeconds", 10)) * time.Second}
+	go models.DeliverWebhooksLoop(store, webhookClient, webhookInterval)
+}
+
+// ServeGRPC serves gRPC api on listener until it fails
+func ServeGRPC(lis net.Listener) error {
+	beego.Info("Serving gRPC on", lis.Addr())
+	return errors.Wrap(grpcServer.Serve(lis), "gRPC server failed")
+}
+
+func newStore(storeType string) (models.Store, error) {
+	beego.Info("Starting db:", storeType)
+	switch storeType {
+	case "mongo":
+		dbURI := beego.AppConfig.String("dbUri")
+		db, err := mongo.NewClient(options.Client().ApplyURI(dbURI))
+		if err != nil {
+			return nil, err
+		}
+
+		err = db.Connect(ctx.TODO())
+		if err != nil {
+			return nil, err
+		}
+
+		dbName := beego.AppConfig.String("dbName")
+		store := models.NewMongoPostStore(db, dbName)
+		if err := store.EnsureIndexes(); err != nil {
+			beego.Error("Can not create indexes:", err)
+		}
+		return store, nil
+	case "mysql":
+		db, err := sql.Open("mysql", beego.AppConfig.String("mysqlDsn"))
+		if err != nil {
+			return nil, err
+		}
+		return models.NewMySQLPostStore(db)
+	case "bolt":
+		return models.NewBoltPostStore(boltstore.DataPath(beego.AppConfig.DefaultString("boltPath", "data/blog.db")))
+	case "memory":
+		return models.NewMemoryPostStore(), nil
+	}
+	return nil, errors.Errorf("Unknown store type: %v", storeType)
 }
@@ -0,0 +1,180 @@
+package search
+
+import (
+	"html/template"
+	"hw8/models"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// field weights, a match in title counts more than in content
+var fieldWeights = map[string]float64{
+	"title":    3,
+	"tags":     2,
+	"category": 2,
+	"link":     1,
+	"content":  1,
+}
+
+// bm25 ranking parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+type document struct {
+	post   models.BlogPost
+	terms  []string
+	length float64
+}
+
+// Index is in memory inverted index of posts.
+// It keeps weighted term frequencies per post and ranks hits with bm25.
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[primitive.ObjectID]*document
+	postings map[string]map[primitive.ObjectID]float64
+	total    float64
+}
+
+// Hit is a found post with its score and highlighted content snippet
+type Hit struct {
+	models.BlogPost
+	Score   float64
+	Snippet template.HTML
+}
+
+// NewIndex creates empty index
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[primitive.ObjectID]*document),
+		postings: make(map[string]map[primitive.ObjectID]float64),
+	}
+}
+
+// Add indexes post or reindexes it if already added,
+// unpublished posts are only removed so readers do not find them
+func (idx *Index) Add(post models.BlogPost) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(post.ID)
+	if !post.IsPublished() {
+		return
+	}
+
+	doc := &document{post: post}
+	fields := map[string]string{
+		"title":    post.Title,
+		"tags":     strings.Join(post.Tags, " "),
+		"category": post.Category,
+		"link":     post.Link,
+		"content":  post.Content,
+	}
+	for field, text := range fields {
+		for _, t := range Tokenize(text) {
+			p, ok := idx.postings[t.Term]
+			if !ok {
+				p = make(map[primitive.ObjectID]float64)
+				idx.postings[t.Term] = p
+				doc.terms = append(doc.terms, t.Term)
+			} else if _, ok := p[post.ID]; !ok {
+				doc.terms = append(doc.terms, t.Term)
+			}
+			p[post.ID] += fieldWeights[field]
+			doc.length += fieldWeights[field]
+		}
+	}
+	idx.docs[post.ID] = doc
+	idx.total += doc.length
+}
+
+// Remove drops post from index
+func (idx *Index) Remove(id primitive.ObjectID) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(id)
+}
+
+func (idx *Index) remove(id primitive.ObjectID) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range doc.terms {
+		p := idx.postings[term]
+		delete(p, id)
+		if len(p) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	idx.total -= doc.length
+	delete(idx.docs, id)
+}
+
+// Rebuild replaces index content with posts
+func (idx *Index) Rebuild(posts []models.BlogPost) {
+	idx.mu.Lock()
+	idx.docs = make(map[primitive.ObjectID]*document)
+	idx.postings = make(map[string]map[primitive.ObjectID]float64)
+	idx.total = 0
+	idx.mu.Unlock()
+
+	for _, post := range posts {
+		idx.Add(post)
+	}
+}
+
+// Search finds posts matching any query word, best first.
+// Posts matching more of the query words rank higher.
+func (idx *Index) Search(query string, limit int) []Hit {
+	terms := map[string]bool{}
+	for _, t := range Tokenize(query) {
+		terms[t.Term] = true
+	}
+	if len(terms) == 0 {
+		return []Hit{}
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	n := float64(len(idx.docs))
+	avg := idx.total / math.Max(n, 1)
+	scores := map[primitive.ObjectID]float64{}
+	matched := map[primitive.ObjectID]int{}
+	for term := range terms {
+		p := idx.postings[term]
+		idf := math.Log(1 + (n-float64(len(p))+0.5)/(float64(len(p))+0.5))
+		for id, tf := range p {
+			norm := bm25K1 * (1 - bm25B + bm25B*idx.docs[id].length/math.Max(avg, 1))
+			scores[id] += idf * tf * (bm25K1 + 1) / (tf + norm)
+			matched[id]++
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		post := idx.docs[id].post
+		hits = append(hits, Hit{
+			BlogPost: post,
+			Score:    score * float64(matched[id]) / float64(len(terms)),
+			Snippet:  Snippet(post.Content, terms, snippetWords),
+		})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID.Hex() > hits[j].ID.Hex()
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
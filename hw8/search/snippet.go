@@ -0,0 +1,56 @@
+package search
+
+import (
+	"html/template"
+	"strings"
+)
+
+// snippetWords is the number of words shown around the first match
+const snippetWords = 30
+
+// Snippet cuts a window of words around the first query term in text
+// and wraps matched words in mark tags, the rest of text is escaped
+func Snippet(text string, terms map[string]bool, words int) template.HTML {
+	tokens := Tokenize(text)
+	if len(tokens) == 0 {
+		return ""
+	}
+
+	first := 0
+	for i, t := range tokens {
+		if terms[t.Term] {
+			first = i
+			break
+		}
+	}
+	from := first - words/3
+	if from < 0 {
+		from = 0
+	}
+	to := from + words
+	if to > len(tokens) {
+		to = len(tokens)
+	}
+
+	b := strings.Builder{}
+	pos := 0
+	if from > 0 {
+		b.WriteString("… ")
+		pos = tokens[from].Start
+	}
+	for _, t := range tokens[from:to] {
+		b.WriteString(template.HTMLEscapeString(text[pos:t.Start]))
+		word := template.HTMLEscapeString(text[t.Start:t.End])
+		if terms[t.Term] {
+			word = "<mark>" + word + "</mark>"
+		}
+		b.WriteString(word)
+		pos = t.End
+	}
+	if to < len(tokens) {
+		b.WriteString(" …")
+	} else {
+		b.WriteString(template.HTMLEscapeString(text[pos:]))
+	}
+	return template.HTML(b.String())
+}
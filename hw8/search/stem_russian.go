@@ -0,0 +1,140 @@
+package search
+
+import "strings"
+
+// suffixes of russian snowball stemmer, see
+// https://snowballstem.org/algorithms/russian/stemmer.html
+// lists are ordered longest first so the longest suffix wins,
+// groups numbered 1 only match when preceded by а or я
+var (
+	ruPerfectiveGerund1 = []string{"вшись", "вши", "в"}
+	ruPerfectiveGerund2 = []string{"ившись", "ывшись", "ивши", "ывши", "ив", "ыв"}
+	ruReflexive         = []string{"ся", "сь"}
+	ruAdjective         = []string{
+		"ими", "ыми", "его", "ого", "ему", "ому",
+		"ее", "ие", "ые", "ое", "ей", "ий", "ый", "ой", "ем", "им", "ым", "ом",
+		"их", "ых", "ую", "юю", "ая", "яя", "ою", "ею",
+	}
+	ruParticiple1 = []string{"ем", "нн", "вш", "ющ", "щ"}
+	ruParticiple2 = []string{"ивш", "ывш", "ующ"}
+	ruVerb1       = []string{
+		"ете", "йте", "ешь", "нно",
+		"ла", "на", "ли", "ем", "ло", "но", "ет", "ют", "ны", "ть", "й", "л", "н",
+	}
+	ruVerb2 = []string{
+		"ейте", "уйте",
+		"ила", "ыла", "ена", "ите", "или", "ыли", "ило", "ыло", "ено", "ует", "уют",
+		"ены", "ить", "ыть", "ишь",
+		"ей", "уй", "ил", "ыл", "им", "ым", "ен", "ят", "ит", "ыт", "ую", "ю",
+	}
+	ruNoun = []string{
+		"иями", "ями", "ами", "ией", "иям", "ием", "иях",
+		"ев", "ов", "ие", "ье", "еи", "ии", "ей", "ой", "ий", "ям", "ем", "ам", "ом",
+		"ах", "ях", "ию", "ью", "ия", "ья",
+		"а", "е", "и", "й", "о", "у", "ы", "ь", "ю", "я",
+	}
+	ruSuperlative  = []string{"ейше", "ейш"}
+	ruDerivational = []string{"ость", "ост"}
+	ruVowels       = "аеиоуыэюя"
+	ruAfterAOrYa   = "ая"
+)
+
+func isRuVowel(r rune) bool {
+	return strings.ContainsRune(ruVowels, r)
+}
+
+// stemRussian reduces lower case russian word to its stem
+func stemRussian(word string) string {
+	w := []rune(strings.ReplaceAll(word, "ё", "е"))
+
+	// rv is the region after the first vowel
+	rv := len(w)
+	for i, r := range w {
+		if isRuVowel(r) {
+			rv = i + 1
+			break
+		}
+	}
+	// r2 is the region after the second vowel-consonant pair
+	r2 := len(w)
+	pairs := 0
+	for i := 1; i < len(w); i++ {
+		if !isRuVowel(w[i]) && isRuVowel(w[i-1]) {
+			pairs++
+			if pairs == 2 {
+				r2 = i + 1
+				break
+			}
+		}
+	}
+
+	stem, region := w[:rv], w[rv:]
+
+	// step 1
+	if r, ok := removeSuffix(region, ruPerfectiveGerund2, ""); ok {
+		region = r
+	} else if r, ok := removeSuffix(region, ruPerfectiveGerund1, ruAfterAOrYa); ok {
+		region = r
+	} else {
+		if r, ok := removeSuffix(region, ruReflexive, ""); ok {
+			region = r
+		}
+		if r, ok := removeSuffix(region, ruAdjective, ""); ok {
+			region = r
+			if r, ok := removeSuffix(region, ruParticiple2, ""); ok {
+				region = r
+			} else if r, ok := removeSuffix(region, ruParticiple1, ruAfterAOrYa); ok {
+				region = r
+			}
+		} else if r, ok := removeSuffix(region, ruVerb2, ""); ok {
+			region = r
+		} else if r, ok := removeSuffix(region, ruVerb1, ruAfterAOrYa); ok {
+			region = r
+		} else if r, ok := removeSuffix(region, ruNoun, ""); ok {
+			region = r
+		}
+	}
+
+	// step 2
+	if n := len(region); n > 0 && region[n-1] == 'и' {
+		region = region[:n-1]
+	}
+
+	// step 3, derivational suffix must be in r2
+	if r, ok := removeSuffix(region, ruDerivational, ""); ok && rv+len(r) >= r2 {
+		region = r
+	}
+
+	// step 4
+	if r, ok := removeSuffix(region, []string{"нн"}, ""); ok {
+		region = append(r, 'н')
+	} else if r, ok := removeSuffix(region, ruSuperlative, ""); ok {
+		region = r
+		if r, ok := removeSuffix(region, []string{"нн"}, ""); ok {
+			region = append(r, 'н')
+		}
+	} else if r, ok := removeSuffix(region, []string{"ь"}, ""); ok {
+		region = r
+	}
+
+	return string(stem) + string(region)
+}
+
+// removeSuffix removes the first matching suffix from word,
+// if after is set the suffix must be preceded by one of its letters
+func removeSuffix(word []rune, suffixes []string, after string) ([]rune, bool) {
+	for _, suffix := range suffixes {
+		s := []rune(suffix)
+		n := len(word) - len(s)
+		if n < 0 || string(word[n:]) != suffix {
+			continue
+		}
+		if after != "" && (n == 0 || !strings.ContainsRune(after, word[n-1])) {
+			// longer suffixes of this group are already checked,
+			// shorter ones may still match
+			continue
+		}
+		return word[:n], true
+	}
+	return word, false
+}
@@ -0,0 +1,46 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Token is a word of text with its stemmed term and byte offsets
+type Token struct {
+	Term       string
+	Start, End int
+}
+
+// Tokenize splits text into words of letters and digits
+// and stems them as russian or english by their script
+func Tokenize(text string) []Token {
+	tokens := []Token{}
+	start := -1
+	for i, r := range text {
+		word := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if word && start < 0 {
+			start = i
+		} else if !word && start >= 0 {
+			tokens = append(tokens, newToken(text, start, i))
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, newToken(text, start, len(text)))
+	}
+	return tokens
+}
+
+func newToken(text string, start, end int) Token {
+	return Token{Term: Stem(strings.ToLower(text[start:end])), Start: start, End: end}
+}
+
+// Stem reduces lower case word to its stem
+func Stem(word string) string {
+	for _, r := range word {
+		if unicode.Is(unicode.Cyrillic, r) {
+			return stemRussian(word)
+		}
+	}
+	return stemEnglish(word)
+}
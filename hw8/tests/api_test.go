@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// apiRequest sends json body with bearer token, empty token is anonymous
+func apiRequest(method, path, body, token string) *httptest.ResponseRecorder {
+	r, _ := http.NewRequest(method, path, strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	return serveRequest(r)
+}
+
+// apiLogin registers user and logs in over api
+func apiLogin(t *testing.T, name string) string {
+	register(name, name+" password")
+	return apiSession(t, name)
+}
+
+// apiSession logs in existing user having password of apiLogin
+func apiSession(t *testing.T, name string) string {
+	w := apiRequest("POST", "/api/v1/session", `{"name": "`+name+`", "password": "`+name+` password"}`, "")
+	var session struct{ Token string }
+	if err := json.Unmarshal(w.Body.Bytes(), &session); w.Code != http.StatusCreated || err != nil || session.Token == "" {
+		t.Fatalf("Api login should send token, got %v %v", w.Code, w.Body.String())
+	}
+	return session.Token
+}
+
+func TestAPIPosts(t *testing.T) {
+	token := apiLogin(t, "apiwriter")
+	if w := apiRequest("POST", "/api/v1/session", `{"name": "apiwriter", "password": "wrong"}`, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("Bad api login should be 401, got %v", w.Code)
+	}
+
+	w := apiRequest("POST", "/api/v1/posts", `{"title": "", "link": "ftp://x", "status": "scheduled"}`, token)
+	var failure struct {
+		Error  string
+		Fields map[string]string
+	}
+	json.Unmarshal(w.Body.Bytes(), &failure)
+	if w.Code != http.StatusUnprocessableEntity || failure.Fields["title"] == "" ||
+		failure.Fields["link"] == "" || failure.Fields["publish_at"] == "" {
+		t.Errorf("Invalid post should be 422 with field errors, got %v %v", w.Code, w.Body.String())
+	}
+	if w = apiRequest("POST", "/api/v1/posts", `{"title": `, token); w.Code != http.StatusBadRequest {
+		t.Errorf("Malformed json should be 400, got %v", w.Code)
+	}
+	if w = apiRequest("POST", "/api/v1/posts", `{"title": "x", "author": "me"}`, token); w.Code != http.StatusBadRequest {
+		t.Errorf("Unknown field should be 400, got %v", w.Code)
+	}
+	if w = apiRequest("POST", "/api/v1/posts", `{"title": "x"}`, ""); w.Code != http.StatusUnauthorized ||
+		!strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		t.Errorf("Anonymous create should be json 401, got %v", w.Code)
+	}
+
+	w = apiRequest("POST", "/api/v1/posts", `{"title": "Api post", "content": "Text", "tags": ["Go", "API"]}`, token)
+	var post struct {
+		ID, Slug, Status, URL string
+		Tags                  []string
+		Version               int
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &post); w.Code != http.StatusCreated || err != nil {
+		t.Fatalf("Create should be 201, got %v %v", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Location") != "/api/v1/posts/"+post.ID || post.Status != "draft" || post.Slug != "api-post" ||
+		strings.Join(post.Tags, ",") != "api,go" {
+		t.Errorf("Wrong created post %+v", post)
+	}
+	path := "/api/v1/posts/" + post.ID
+
+	if w = apiRequest("GET", path, "", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Draft should be hidden from anonymous clients, got %v", w.Code)
+	}
+	w = apiRequest("GET", path, "", token)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"title": "Api post"`) {
+		t.Fatalf("Author should get draft, got %v", w.Code)
+	}
+	r, _ := http.NewRequest("GET", path, nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+	if w = serveRequest(r); w.Code != http.StatusNotModified {
+		t.Errorf("Unchanged post should be 304, got %v", w.Code)
+	}
+	for _, missing := range []string{"/api/v1/posts/nope", "/api/v1/posts/5e4f0a0a0a0a0a0a0a0a0a0a"} {
+		if w = apiRequest("GET", missing, "", token); w.Code != http.StatusNotFound {
+			t.Errorf("%v should be 404, got %v", missing, w.Code)
+		}
+	}
+
+	if w = apiRequest("PUT", path, `{"title": "Api post"}`, token); w.Code != http.StatusPreconditionRequired {
+		t.Errorf("Update without version should be 428, got %v", w.Code)
+	}
+	w = apiRequest("PUT", path, `{"title": "Published api post", "status": "published", "version": 0}`, token)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"status": "published"`) ||
+		!strings.Contains(w.Body.String(), `"slug": "published-api-post"`) {
+		t.Fatalf("Update should publish post, got %v %v", w.Code, w.Body.String())
+	}
+	if w = apiRequest("PUT", path, `{"title": "Stale", "version": 0}`, token); w.Code != http.StatusConflict {
+		t.Errorf("Stale update should be 409, got %v", w.Code)
+	}
+	r, _ = http.NewRequest("PUT", path, strings.NewReader(`{"title": "Stale"}`))
+	r.Header.Set("Authorization", "Bearer "+token)
+	r.Header.Set("If-Match", `"0"`)
+	if w = serveRequest(r); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("Stale If-Match should be 412, got %v", w.Code)
+	}
+
+	w = apiRequest("GET", "/api/v1/posts?limit=1", "", "")
+	var page struct {
+		Posts []struct{ ID string }
+		Next  string
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil || len(page.Posts) != 1 || page.Posts[0].ID != post.ID {
+		t.Errorf("List should start with new post, got %v", w.Body.String())
+	}
+	if page.Next != "" {
+		if w = apiRequest("GET", "/api/v1/posts?limit=1&cursor="+page.Next, "", ""); strings.Contains(w.Body.String(), post.ID) {
+			t.Error("Next page should not repeat post")
+		}
+	}
+	for _, bad := range []string{"?limit=0", "?limit=x", "?cursor=bad"} {
+		if w = apiRequest("GET", "/api/v1/posts"+bad, "", ""); w.Code != http.StatusBadRequest {
+			t.Errorf("%v should be 400, got %v", bad, w.Code)
+		}
+	}
+
+	other := apiLogin(t, "apireader")
+	if w = apiRequest("DELETE", path, "", other); w.Code != http.StatusForbidden {
+		t.Errorf("Other author delete should be 403, got %v", w.Code)
+	}
+	r, _ = http.NewRequest("DELETE", path, nil)
+	r.AddCookie(register("apicookie", "apicookie password"))
+	if w = serveRequest(r); w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), `"error"`) {
+		t.Errorf("Cookie request without CSRF token should be json 403, got %v", w.Code)
+	}
+	if w = apiRequest("DELETE", path, "", token); w.Code != http.StatusNoContent {
+		t.Errorf("Delete should be 204, got %v", w.Code)
+	}
+	if w = apiRequest("GET", path, "", token); w.Code != http.StatusNotFound {
+		t.Errorf("Deleted post should be 404, got %v", w.Code)
+	}
+
+	if w = apiRequest("DELETE", "/api/v1/session", "", token); w.Code != http.StatusNoContent {
+		t.Errorf("Logout should be 204, got %v", w.Code)
+	}
+	if w = apiRequest("POST", "/api/v1/posts", `{"title": "x"}`, token); w.Code != http.StatusUnauthorized {
+		t.Errorf("Ended session token should be 401, got %v", w.Code)
+	}
+}
+
+func TestBadPostIDIsNotFound(t *testing.T) {
+	if w := serve("GET", "/post?id=nope", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Malformed post id should be 404, got %v", w.Code)
+	}
+	if w := serve("GET", "/edit?id=nope", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Malformed edit id should be 404, got %v", w.Code)
+	}
+}
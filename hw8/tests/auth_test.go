@@ -0,0 +1,145 @@
+package tests
+
+import (
+	"hw8/models"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var (
+	sessionOnce   sync.Once
+	sessionCookie *http.Cookie
+)
+
+// testSession logs in as the admin from tests/conf once and returns its session cookie
+func testSession() *http.Cookie {
+	sessionOnce.Do(func() {
+		sessionCookie = login("tester", "tester password")
+	})
+	return sessionCookie
+}
+
+// register signs up a user over HTTP and returns its session cookie
+func register(name, password string) *http.Cookie {
+	form := url.Values{"name": {name}, "password": {password}, "confirm": {password}}
+	return sessionOf(name, serveRequest(addPreSession(newFormRequest("POST", "/register", form))))
+}
+
+// login logs user in over HTTP and returns its session cookie
+func login(name, password string) *http.Cookie {
+	form := url.Values{"name": {name}, "password": {password}}
+	return sessionOf(name, serveRequest(addPreSession(newFormRequest("POST", "/login", form))))
+}
+
+func sessionOf(name string, w *httptest.ResponseRecorder) *http.Cookie {
+	for _, c := range w.Result().Cookies() {
+		if c.Name == "session" {
+			return c
+		}
+	}
+	panic("No session started for " + name)
+}
+
+func TestRegisterAndLogin(t *testing.T) {
+	store := models.NewMemoryPostStore()
+	if _, err := models.Register(store, "ann", "short"); err != models.ErrWeakPassword {
+		t.Error("Should be ErrWeakPassword")
+	}
+	if _, err := models.Register(store, "a b", "long password"); err != models.ErrBadUserName {
+		t.Error("Should be ErrBadUserName")
+	}
+	user, err := models.Register(store, "ann", "long password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(user.PasswordHash) == "long password" {
+		t.Error("Password should be hashed")
+	}
+	if user.Role != models.RoleAuthor {
+		t.Errorf("First signed up user should be author, got %v", user.Role)
+	}
+	if _, err := models.Register(store, "ann", "other password"); err != models.ErrUserExists {
+		t.Error("Should be ErrUserExists")
+	}
+
+	if _, err := models.Login(store, "ann", "wrong password"); err != models.ErrBadCredentials {
+		t.Error("Wrong password should be ErrBadCredentials")
+	}
+	if _, err := models.Login(store, "bob", "long password"); err != models.ErrBadCredentials {
+		t.Error("Unknown user should be ErrBadCredentials")
+	}
+	if _, err := models.Login(store, "ann", "long password"); err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := models.StartSession(store, user.ID, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u, err := models.SessionUser(store, token); err != nil || u.Name != "ann" {
+		t.Error("Session should belong to ann")
+	}
+	if err := models.EndSession(store, token); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := models.SessionUser(store, token); err != models.ErrSessionNotFound {
+		t.Error("Ended session should be ErrSessionNotFound")
+	}
+
+	expired, _ := models.StartSession(store, user.ID, -time.Minute)
+	if _, err := models.SessionUser(store, expired); err != models.ErrSessionNotFound {
+		t.Error("Expired session should be ErrSessionNotFound")
+	}
+}
+
+func TestLoginRequired(t *testing.T) {
+	for _, path := range []string{"/new", "/edit"} {
+		w := serveRequest(newFormRequest("POST", path, url.Values{}))
+		if w.Code != http.StatusSeeOther || !strings.HasPrefix(w.Header().Get("Location"), "/login") {
+			t.Errorf("Anonymous %v should redirect to login, got %v", path, w.Code)
+		}
+	}
+}
+
+func TestLoginPages(t *testing.T) {
+	register("loginpage", "loginpage password")
+
+	form := url.Values{"name": {"loginpage"}, "password": {"wrong"}, "next": {"/trash"}}
+	w := serveRequest(addPreSession(newFormRequest("POST", "/login", form)))
+	if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "Wrong user name or password") {
+		t.Errorf("Wrong password should be 401, got %v", w.Code)
+	}
+
+	form.Set("password", "loginpage password")
+	w = serveRequest(addPreSession(newFormRequest("POST", "/login", form)))
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/trash" {
+		t.Fatalf("Login should redirect to next page, got %v", w.Code)
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) == 0 || !cookies[0].HttpOnly {
+		t.Fatal("Login should set http only session cookie")
+	}
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	r.AddCookie(cookies[0])
+	if w = serveRequest(r); !strings.Contains(w.Body.String(), "loginpage") {
+		t.Error("Index should show logged in user")
+	}
+
+	serveRequest(addSession(newFormRequest("POST", "/logout", url.Values{}), cookies[0]))
+	r = addSession(newFormRequest("POST", "/new", url.Values{}), cookies[0])
+	if w = serveRequest(r); w.Code != http.StatusSeeOther {
+		t.Errorf("Session should end on logout, got %v", w.Code)
+	}
+
+	form.Set("next", "//evil.example.com")
+	w = serveRequest(addPreSession(newFormRequest("POST", "/login", form)))
+	if w.Header().Get("Location") != "/" {
+		t.Errorf("Login should not redirect away, got %v", w.Header().Get("Location"))
+	}
+}
@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"fmt"
+	"hw8/models"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestThread(t *testing.T) {
+	ids := make([]primitive.ObjectID, 5)
+	for i := range ids {
+		ids[i] = primitive.NewObjectID()
+	}
+	comments := []models.Comment{
+		{ID: ids[0], Text: "a"},
+		{ID: ids[1], Text: "b"},
+		{ID: ids[2], ParentID: ids[0], Text: "a1"},
+		{ID: ids[3], ParentID: ids[2], Text: "a11"},
+		{ID: ids[4], ParentID: ids[3], Text: "a111"},
+	}
+
+	thread := models.Thread(comments, 2)
+	got := ""
+	for _, c := range thread {
+		got += fmt.Sprintf("%v:%v ", c.Text, c.Depth)
+	}
+	if got != "a:0 a1:1 a11:2 a111:2 b:0 " {
+		t.Errorf("Wrong thread %v", got)
+	}
+}
+
+func TestMemoryComments(t *testing.T) {
+	testComments(t, models.NewMemoryPostStore())
+}
+
+func TestBoltComments(t *testing.T) {
+	store, err := models.NewBoltPostStore(filepath.Join(t.TempDir(), "blog.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	testComments(t, store)
+}
+
+func testComments(t *testing.T, store models.Store) {
+	post := &models.BlogPost{Title: "Commented"}
+	other := &models.BlogPost{Title: "Other"}
+	store.CreatePost(post)
+	store.CreatePost(other)
+
+	first := &models.Comment{PostID: post.ID, Author: "ann", Text: "first"}
+	if err := models.PostComment(store, first, nil); err != nil {
+		t.Fatal(err)
+	}
+	reply := &models.Comment{PostID: post.ID, ParentID: first.ID, Author: "bob", Text: "reply"}
+	if err := models.PostComment(store, reply, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	wrongPost := &models.Comment{PostID: other.ID, ParentID: first.ID, Author: "bob", Text: "reply"}
+	if err := models.PostComment(store, wrongPost, nil); err != models.ErrCommentNotFound {
+		t.Error("Reply to comment of other post should be ErrCommentNotFound")
+	}
+	if err := models.PostComment(store, &models.Comment{PostID: post.ID, Author: " ", Text: "x"}, nil); err != models.ErrBadComment {
+		t.Error("Comment without author should be ErrBadComment")
+	}
+
+	comments, err := store.ListComments(post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comments) != 2 || comments[0].Text != "first" || comments[1].ParentID != first.ID {
+		t.Errorf("Wrong comments %v", comments)
+	}
+
+	counts, err := store.CountComments([]primitive.ObjectID{post.ID, other.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts[post.ID] != 2 || counts[other.ID] != 0 {
+		t.Errorf("Wrong counts %v", counts)
+	}
+	byPost, err := store.ListCommentsOf([]primitive.ObjectID{post.ID, other.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byPost[post.ID]) != 2 || byPost[post.ID][1].Text != "reply" || byPost[other.ID] == nil || len(byPost[other.ID]) != 0 {
+		t.Errorf("Wrong comments by post %v", byPost)
+	}
+
+	store.DeletePost(post.ID)
+	if err := store.PurgePost(post.ID); err != nil {
+		t.Fatal(err)
+	}
+	if comments, _ := store.ListComments(post.ID); len(comments) != 0 {
+		t.Error("Purged post comments should be deleted")
+	}
+
+	user, err := models.Register(store, "carol", "carol password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := models.PostComment(store, &models.Comment{PostID: other.ID, Author: "carol", Text: "x"}, nil); err != models.ErrCommentAuthorTaken {
+		t.Errorf("Anonymous comment should not take user name, got %v", err)
+	}
+	signed := &models.Comment{PostID: other.ID, Author: "someone", Text: "signed"}
+	if err := models.PostComment(store, signed, user); err != nil || signed.Author != "carol" {
+		t.Errorf("Comment of user should be signed with user name, got %q %v", signed.Author, err)
+	}
+
+	draft := &models.BlogPost{Title: "Draft", Status: models.StatusDraft, AuthorID: user.ID}
+	store.CreatePost(draft)
+	if err := models.PostComment(store, &models.Comment{PostID: draft.ID, Author: "dan", Text: "x"}, nil); err != models.ErrPostNotFound {
+		t.Errorf("Comment on draft should be ErrPostNotFound, got %v", err)
+	}
+	if err := models.PostComment(store, &models.Comment{PostID: draft.ID, Text: "note"}, user); err != nil {
+		t.Errorf("Author should comment own draft, got %v", err)
+	}
+}
+
+var commentIDField = regexp.MustCompile(`id="comment-([0-9a-f]{24})"`)
+
+func TestCommentPages(t *testing.T) {
+	w := serve("POST", "/new", nil)
+	m := postIDField.FindStringSubmatch(w.Body.String())
+	if m == nil {
+		t.Fatal("Should render new post id")
+	}
+	form := url.Values{"id": {m[1]}, "version": {"0"}, "title": {"Commented"}, "status": {"published"}}
+	if w = serve("POST", "/edit", form); w.Code != http.StatusOK {
+		t.Fatalf("Publish should succeed, got %v", w.Code)
+	}
+	link := permalink(t, m[1])
+	w = serve("POST", "/post/comment", url.Values{"id": {m[1]}, "author": {"ann"}, "text": {"Nice post"}})
+	if w.Code != http.StatusFound || !strings.HasPrefix(w.Header().Get("Location"), link+"#comment-") {
+		t.Fatalf("Comment should redirect to post, got %v", w.Code)
+	}
+
+	w = serve("GET", link, nil)
+	c := commentIDField.FindStringSubmatch(w.Body.String())
+	if c == nil || !strings.Contains(w.Body.String(), "Nice post") {
+		t.Fatal("Post page should show comment")
+	}
+
+	form = url.Values{"id": {m[1]}, "parent": {c[1]}, "author": {"bob"}, "text": {"Thanks"}}
+	if w = serve("POST", "/post/comment", form); w.Code != http.StatusFound {
+		t.Fatalf("Reply should redirect, got %v", w.Code)
+	}
+	if w = serve("GET", link, nil); !strings.Contains(w.Body.String(), "margin-left: 1em") {
+		t.Error("Reply should be nested")
+	}
+
+	form.Set("parent", primitive.NewObjectID().Hex())
+	if w = serve("POST", "/post/comment", form); w.Code != http.StatusBadRequest {
+		t.Errorf("Reply to unknown comment should be 400, got %v", w.Code)
+	}
+
+	if w = serve("GET", "/", nil); !strings.Contains(w.Body.String(), "Comments: 2") {
+		t.Error("Index should show comment count")
+	}
+
+	anonymous := func(id, author string) int {
+		form := url.Values{"id": {id}, "author": {author}, "text": {"Hi"}}
+		return serveRequest(addPreSession(newFormRequest("POST", "/post/comment", form))).Code
+	}
+	if code := anonymous(m[1], "tester"); code != http.StatusBadRequest {
+		t.Errorf("Anonymous comment signed as user should be 400, got %v", code)
+	}
+	draft := postIDField.FindStringSubmatch(serve("POST", "/new", nil).Body.String())
+	if draft == nil {
+		t.Fatal("Should render new post id")
+	}
+	if code := anonymous(draft[1], "guest"); code != http.StatusNotFound {
+		t.Errorf("Comment on draft should be 404, got %v", code)
+	}
+}
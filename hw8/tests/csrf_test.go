@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"hw8/models"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCSRFToken(t *testing.T) {
+	token := models.CSRFToken("session one")
+	if token == "" || token != models.CSRFToken("session one") {
+		t.Fatal("Token should be stable for a session")
+	}
+	if !models.CheckCSRF("session one", token) {
+		t.Error("Token should pass for its session")
+	}
+	if models.CheckCSRF("session two", token) || models.CheckCSRF("session one", "") || models.CheckCSRF("", "") {
+		t.Error("Token should fail for other sessions")
+	}
+}
+
+func TestCSRFCheck(t *testing.T) {
+	session := testSession()
+
+	r := newFormRequest("POST", "/new", url.Values{})
+	r.AddCookie(session)
+	w := serveRequest(r)
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "reload the page") {
+		t.Errorf("New post without token should be 403, got %v", w.Code)
+	}
+
+	form := url.Values{"csrf_token": {models.CSRFToken("stolen")}}
+	r = newFormRequest("POST", "/new", form)
+	r.AddCookie(session)
+	if w = serveRequest(r); w.Code != http.StatusForbidden {
+		t.Errorf("New post with foreign token should be 403, got %v", w.Code)
+	}
+
+	w = serve("POST", "/new", nil)
+	m := postIDField.FindStringSubmatch(w.Body.String())
+	if m == nil {
+		t.Fatal("New post with token should succeed")
+	}
+	if !strings.Contains(w.Body.String(), `name="csrf_token" value="`+models.CSRFToken(session.Value)+`"`) {
+		t.Error("Edit form should carry CSRF token")
+	}
+
+	r = newFormRequest("POST", "/edit", url.Values{"id": {m[1]}, "title": {"Forged"}})
+	r.AddCookie(session)
+	if w = serveRequest(r); w.Code != http.StatusForbidden {
+		t.Errorf("Update without token should be 403, got %v", w.Code)
+	}
+}
+
+func TestPreSessionCSRF(t *testing.T) {
+	r, _ := http.NewRequest("GET", "/login", nil)
+	w := serveRequest(r)
+	var preSession *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == "presession" {
+			preSession = c
+		}
+	}
+	if preSession == nil || !preSession.HttpOnly {
+		t.Fatal("Login page should set http only pre-session cookie")
+	}
+	if !strings.Contains(w.Body.String(), `name="csrf_token" value="`+models.CSRFToken(preSession.Value)+`"`) {
+		t.Error("Login form should carry CSRF token of pre-session")
+	}
+
+	form := url.Values{"name": {"tester"}, "password": {"tester password"}}
+	for _, path := range []string{"/login", "/register", "/post/comment"} {
+		if w = serveRequest(newFormRequest("POST", path, form)); w.Code != http.StatusForbidden {
+			t.Errorf("Anonymous %v without token should be 403, got %v", path, w.Code)
+		}
+	}
+	r = newFormRequest("POST", "/login", url.Values{"name": {"tester"}, "password": {"tester password"},
+		"csrf_token": {models.CSRFToken("attacker pre-session")}})
+	r.AddCookie(preSession)
+	if w = serveRequest(r); w.Code != http.StatusForbidden {
+		t.Errorf("Login with foreign token should be 403, got %v", w.Code)
+	}
+
+	r = newFormRequest("POST", "/login", url.Values{"name": {"tester"}, "password": {"tester password"},
+		"csrf_token": {models.CSRFToken(preSession.Value)}})
+	r.AddCookie(preSession)
+	if w = serveRequest(r); w.Code != http.StatusSeeOther {
+		t.Errorf("Login with pre-session token should succeed, got %v", w.Code)
+	}
+}
@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"hw8/datefmt"
+	"hw8/models"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseDate(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	d, err := models.ParseDate("2020-02-21", moscow)
+	if err != nil || !d.Equal(time.Date(2020, 2, 21, 0, 0, 0, 0, moscow)) {
+		t.Errorf("Wrong date %v %v", d, err)
+	}
+	if d, _ = models.ParseDate("2020-02-21T10:00:00Z", moscow); d.Hour() != 10 || d.Location() != time.UTC {
+		t.Errorf("Date with zone should keep it, got %v", d)
+	}
+	for _, bad := range []string{"2020-21-02", "tomorrow", ""} {
+		if _, err := models.ParseDate(bad, moscow); err != models.ErrBadDate {
+			t.Errorf("%q should be ErrBadDate", bad)
+		}
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	f := &datefmt.Formatter{Zone: time.FixedZone("MSK", 3*60*60)}
+	d := time.Date(2020, 2, 21, 21, 30, 0, 0, time.UTC)
+	cases := []struct{ got, want string }{
+		{f.Date(d, "en"), "February 22, 2020"},
+		{f.Date(d, "ru"), "22 февраля 2020"},
+		{f.DateTime(d, "en"), "February 22, 2020 12:30 AM"},
+		{f.DateTime(d, "ru"), "22 февраля 2020, 00:30"},
+		{f.Date(d, "fr"), "February 22, 2020"},
+		{f.Month(2020, time.March, "ru"), "Март 2020"},
+		{f.Date(time.Time{}, "en"), ""},
+	}
+	for _, c := range cases {
+		if c.got != c.want {
+			t.Errorf("Got %q, want %q", c.got, c.want)
+		}
+	}
+
+	if l := datefmt.Locale("fr-CH, ru-RU;q=0.9, en;q=0.8"); l != "ru" {
+		t.Errorf("Should pick ru, got %v", l)
+	}
+	if l := datefmt.Locale(""); l != datefmt.DefaultLocale {
+		t.Errorf("Should use default locale, got %v", l)
+	}
+}
+
+func TestLegacyDates(t *testing.T) {
+	id := primitive.NewObjectIDFromTimestamp(time.Date(2019, 10, 1, 12, 0, 0, 0, time.UTC))
+	data, _ := bson.Marshal(bson.M{"_id": id, "title": "Old", "date": "2020-02-21"})
+	post := models.BlogPost{}
+	if err := bson.Unmarshal(data, &post); err != nil {
+		t.Fatal(err)
+	}
+	if !post.Date.Equal(time.Date(2020, 2, 21, 0, 0, 0, 0, time.UTC)) || post.Title != "Old" {
+		t.Errorf("String date should be parsed, got %v", post.Date)
+	}
+	if !post.CreatedAt.Equal(id.Timestamp()) || !post.UpdatedAt.Equal(post.CreatedAt) {
+		t.Errorf("Created time should come from id, got %v", post.CreatedAt)
+	}
+
+	data, _ = bson.Marshal(bson.M{"_id": id, "title": "Broken", "date": "2020-21-02"})
+	post = models.BlogPost{}
+	if err := bson.Unmarshal(data, &post); err != nil {
+		t.Fatal(err)
+	}
+	if !post.Date.Equal(id.Timestamp()) {
+		t.Errorf("Broken date should fall back to creation, got %v", post.Date)
+	}
+}
+
+func TestMemoryPostDates(t *testing.T) {
+	testPostDates(t, models.NewMemoryPostStore())
+}
+
+func TestBoltPostDates(t *testing.T) {
+	store, err := models.NewBoltPostStore(filepath.Join(t.TempDir(), "blog.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	testPostDates(t, store)
+}
+
+func testPostDates(t *testing.T, store models.PostStore) {
+	day := func(d int) time.Time {
+		return time.Date(2020, 2, d, 0, 0, 0, 0, time.UTC)
+	}
+	// created in other order than dated
+	posts := []*models.BlogPost{
+		{Title: "Middle", Date: day(10), Tags: []string{"t"}},
+		{Title: "Late", Date: day(20), Tags: []string{"t"}},
+		{Title: "Early", Date: day(1), Tags: []string{"t"}},
+		{Title: "March", Date: day(31), Tags: []string{"t"}},
+	}
+	for _, p := range posts {
+		if err := store.CreatePost(p); err != nil {
+			t.Fatal(err)
+		}
+		if p.CreatedAt.IsZero() || !p.UpdatedAt.Equal(p.CreatedAt) {
+			t.Fatal("Store should set created and updated times")
+		}
+	}
+
+	tagged, _ := store.ListPostsByTag("t")
+	if len(tagged) != 4 || tagged[0].Title != "March" || tagged[3].Title != "Early" {
+		t.Errorf("Tag list should be ordered by date, got %v", tagged)
+	}
+	feb, _ := store.ListPostsBetween(day(1), day(1).AddDate(0, 1, 0))
+	if len(feb) != 3 || feb[0].Title != "Late" || feb[2].Title != "Early" {
+		t.Errorf("February should have 3 posts by date, got %v", feb)
+	}
+
+	created := posts[0].CreatedAt
+	time.Sleep(2 * time.Millisecond)
+	posts[0].Title = "Changed"
+	if err := store.UpdatePost(posts[0]); err != nil {
+		t.Fatal(err)
+	}
+	stored, _ := store.GetPost(posts[0].ID)
+	// bolt keeps times in milliseconds
+	if d := stored.CreatedAt.Sub(created); d > time.Millisecond || d < -time.Millisecond {
+		t.Errorf("Update should keep created time, got %v", stored.CreatedAt)
+	}
+	if !stored.UpdatedAt.After(stored.CreatedAt) || !posts[0].CreatedAt.Equal(stored.CreatedAt) {
+		t.Error("Update should set updated time and read created one")
+	}
+}
+
+func TestPostDatePages(t *testing.T) {
+	m := postIDField.FindStringSubmatch(serve("POST", "/new", nil).Body.String())
+	if m == nil {
+		t.Fatal("Should render new post id")
+	}
+	form := url.Values{"id": {m[1]}, "version": {"0"}, "title": {"Dated"}, "date": {"2020-21-02"}, "status": {"published"}}
+	if w := serve("POST", "/edit", form); w.Code != http.StatusBadRequest {
+		t.Errorf("Invalid date should be 400, got %v", w.Code)
+	}
+	form.Set("date", "1999-12-31")
+	if w := serve("POST", "/edit", form); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "December 31, 1999") {
+		t.Fatalf("Edit should show formatted date, got %v", w.Code)
+	}
+
+	r, _ := http.NewRequest("GET", "/archive/1999/12", nil)
+	r.Header.Set("Accept-Language", "ru")
+	w := serveRequest(r)
+	if !strings.Contains(w.Body.String(), m[1]) || !strings.Contains(w.Body.String(), "31 декабря 1999") {
+		t.Error("Archive should list post with russian date")
+	}
+	if w = serve("GET", "/archive/1999/13", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Unknown month should be 404, got %v", w.Code)
+	}
+}
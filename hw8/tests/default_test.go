@@ -3,6 +3,7 @@ package tests
 import (
 	ctx "context"
 	"hw8/controllers"
+	"hw8/models"
 	_ "hw8/routers"
 	"log"
 	"net/http"
@@ -47,7 +48,7 @@ func TestPosts(t *testing.T) {
 		return
 	}
 
-	controller := &controllers.MainController{DB: db, DBName: "TestDb"}
+	controller := &controllers.MainController{Store: models.NewMongoPostStore(db, "TestDb")}
 	posts, err := controller.GetAllPosts()
 	if err != nil {
 		t.Error(err)
@@ -82,8 +83,8 @@ func TestPosts(t *testing.T) {
 		t.Error(err)
 		return
 	}
-	if post.Title != "TestPost1" {
-		t.Error("Should be TestPost1 title")
+	if post.Title != "Untitled" || post.Status != models.StatusDraft {
+		t.Error("Should be Untitled draft")
 		return
 	}
 }
@@ -106,6 +107,7 @@ func TestGetRoot(t *testing.T) {
 
 func TestShowPost(t *testing.T) {
 	r, _ := http.NewRequest("POST", "/new", nil)
+	addSession(r, testSession())
 	w := httptest.NewRecorder()
 	beego.BeeApp.Handlers.ServeHTTP(w, r)
 
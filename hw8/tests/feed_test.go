@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"encoding/xml"
+	"hw8/feed"
+	"hw8/markdown"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFeedDocuments(t *testing.T) {
+	d := time.Date(2020, 2, 21, 10, 0, 0, 0, time.UTC)
+	f := &feed.Feed{Title: "Blog", Link: "http://blog/", Self: "http://blog/feed.xml", Author: "Blog", Updated: d,
+		Items: []feed.Item{
+			{ID: "http://blog/post?id=1", Title: "Full", Link: "http://blog/posts/2020/02/full",
+				Content: "<p>Text & more</p>", Categories: []string{"go"}, Published: d, Updated: d},
+			{ID: "http://blog/post?id=2", Title: "Short", Link: "http://blog/posts/2020/02/short",
+				Summary: "Text", Published: d, Updated: d},
+		}}
+
+	data, err := f.RSS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rss struct {
+		Channel struct {
+			Items []struct {
+				Description string `xml:"description"`
+				GUID        string `xml:"guid"`
+				PubDate     string `xml:"pubDate"`
+				Category    string `xml:"category"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(data, &rss); err != nil || len(rss.Channel.Items) != 2 {
+		t.Fatalf("Should read rss back, got %v", err)
+	}
+	item := rss.Channel.Items[0]
+	if item.Description != "<p>Text & more</p>" || item.GUID != "http://blog/post?id=1" ||
+		item.PubDate != "Fri, 21 Feb 2020 10:00:00 +0000" || item.Category != "go" {
+		t.Errorf("Wrong rss item %+v", item)
+	}
+
+	data, err = f.Atom()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var atom struct {
+		ID      string `xml:"id"`
+		Entries []struct {
+			Published string `xml:"published"`
+			Content   struct {
+				Type string `xml:"type,attr"`
+				Body string `xml:",chardata"`
+			} `xml:"content"`
+			Summary string `xml:"summary"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(data, &atom); err != nil || len(atom.Entries) != 2 {
+		t.Fatalf("Should read atom back, got %v", err)
+	}
+	if atom.ID != f.Self || atom.Entries[0].Published != "2020-02-21T10:00:00Z" ||
+		atom.Entries[0].Content.Type != "html" || atom.Entries[1].Summary != "Text" {
+		t.Errorf("Wrong atom feed %+v", atom)
+	}
+
+	empty := &feed.Feed{Title: "Blog", Link: "http://blog/", Self: "http://blog/atom.xml"}
+	if data, err = empty.Atom(); err != nil {
+		t.Fatal(err)
+	}
+	var updated struct {
+		Updated time.Time `xml:"updated"`
+	}
+	if err := xml.Unmarshal(data, &updated); err != nil || time.Since(updated.Updated) > time.Minute {
+		t.Errorf("Empty atom feed should be updated now, got %v %v", updated.Updated, err)
+	}
+}
+
+func TestMarkdownSummary(t *testing.T) {
+	r := markdown.NewRenderer(0)
+	if s := r.Summary("## Hi\n\n**Bold** & <b>tags</b>", 10); s != "Hi Bold & tags" {
+		t.Errorf("Summary should be plain text, got %q", s)
+	}
+	if s := r.Summary("one two three", 2); s != "one two …" {
+		t.Errorf("Long summary should be cut, got %q", s)
+	}
+}
+
+func TestFeeds(t *testing.T) {
+	m := postIDField.FindStringSubmatch(serve("POST", "/new", nil).Body.String())
+	draft := postIDField.FindStringSubmatch(serve("POST", "/new", nil).Body.String())
+	if m == nil || draft == nil {
+		t.Fatal("Should render new post id")
+	}
+	form := url.Values{"id": {m[1]}, "version": {"0"}, "title": {"Feed post"}, "content": {"**Followed**"},
+		"tags": {"feeds"}, "status": {"published"}}
+	if w := serve("POST", "/edit", form); w.Code != http.StatusOK {
+		t.Fatalf("Publish should succeed, got %v", w.Code)
+	}
+
+	w := serve("GET", "/feed.xml", nil)
+	body := w.Body.String()
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/rss+xml") {
+		t.Fatalf("Should send rss, got %v %v", w.Code, w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(body, "<title>Feed post</title>") ||
+		!strings.Contains(body, "<link>http://localhost:8080"+permalink(t, m[1])+"</link>") ||
+		!strings.Contains(body, "&lt;strong&gt;Followed&lt;/strong&gt;") {
+		t.Error("Rss should have post with absolute link and content")
+	}
+	if strings.Contains(body, draft[1]) {
+		t.Error("Rss should not have drafts")
+	}
+	if w := serve("GET", "/atom.xml", nil); !strings.Contains(w.Body.String(), `<feed xmlns="http://www.w3.org/2005/Atom">`) {
+		t.Error("Should send atom feed")
+	}
+	if w := serve("GET", "/tag/feeds/atom.xml", nil); !strings.Contains(w.Body.String(), "Feed post") {
+		t.Error("Tag feed should have tagged post")
+	}
+	if w := serve("GET", "/tag/other/feed.xml", nil); strings.Contains(w.Body.String(), "Feed post") {
+		t.Error("Other tag feed should not have post")
+	}
+
+	etag, modified := w.Header().Get("ETag"), w.Header().Get("Last-Modified")
+	r, _ := http.NewRequest("GET", "/feed.xml", nil)
+	r.Header.Set("If-None-Match", etag)
+	if w := serveRequest(r); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("Unchanged feed should be 304 by ETag, got %v", w.Code)
+	}
+	r.Header.Del("If-None-Match")
+	r.Header.Set("If-Modified-Since", modified)
+	if w := serveRequest(r); w.Code != http.StatusNotModified {
+		t.Errorf("Unchanged feed should be 304 by date, got %v", w.Code)
+	}
+
+	form.Set("version", "1")
+	form.Set("title", "Feed post edited")
+	serve("POST", "/edit", form)
+	r.Header.Set("If-None-Match", etag)
+	if w := serveRequest(r); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Feed post edited") {
+		t.Errorf("Changed feed should be sent, got %v", w.Code)
+	}
+}
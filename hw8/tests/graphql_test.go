@@ -0,0 +1,286 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"hw8/controllers"
+	"hw8/models"
+	"hw8/search"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/astaxie/beego"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// graphResult is GraphQL response
+type graphResult struct {
+	Data   map[string]json.RawMessage
+	Errors []struct {
+		Message    string
+		Extensions map[string]interface{}
+	}
+}
+
+// graphQuery posts query with variables and bearer token
+func graphQuery(t *testing.T, token, query string, variables map[string]interface{}) (int, *graphResult) {
+	body, _ := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	w := apiRequest("POST", "/graphql", string(body), token)
+	result := &graphResult{}
+	if err := json.Unmarshal(w.Body.Bytes(), result); err != nil {
+		t.Fatalf("Should send json, got %v %v", w.Code, w.Body.String())
+	}
+	return w.Code, result
+}
+
+const graphCreate = `mutation($input: PostInput!) { createPost(input: $input) { id slug status version author { name } } }`
+
+func TestGraphQLPosts(t *testing.T) {
+	token := apiLogin(t, "graphwriter")
+
+	code, result := graphQuery(t, "", graphCreate, map[string]interface{}{"input": map[string]interface{}{"title": "x"}})
+	if code != http.StatusOK || len(result.Errors) == 0 || result.Errors[0].Message != "Login required" {
+		t.Errorf("Anonymous create should fail, got %v %+v", code, result)
+	}
+	_, result = graphQuery(t, token, graphCreate, map[string]interface{}{"input": map[string]interface{}{"title": " ", "link": "ftp://x"}})
+	if len(result.Errors) == 0 || result.Errors[0].Extensions["fields"] == nil {
+		t.Errorf("Invalid post should have field errors, got %+v", result)
+	}
+
+	ids := []string{}
+	for i, status := range []string{"PUBLISHED", "PUBLISHED", "DRAFT"} {
+		input := map[string]interface{}{"title": fmt.Sprintf("Graph post %v", i), "tags": []string{"Graph"}, "status": status}
+		_, result = graphQuery(t, token, graphCreate, map[string]interface{}{"input": input})
+		var created struct {
+			CreatePost struct {
+				ID, Slug, Status string
+				Author           struct{ Name string }
+			}
+		}
+		if len(result.Errors) > 0 {
+			t.Fatalf("Create should succeed, got %+v", result.Errors)
+		}
+		json.Unmarshal(result.Data["createPost"], &created.CreatePost)
+		if created.CreatePost.Status != status || created.CreatePost.Author.Name != "graphwriter" {
+			t.Errorf("Wrong created post %+v", created.CreatePost)
+		}
+		ids = append(ids, created.CreatePost.ID)
+	}
+
+	query := `query($after: String) { posts(filter: {tag: "graph"}, first: 1, after: $after) { nodes { id title tags } endCursor hasNextPage } }`
+	var page struct {
+		Nodes       []struct{ ID, Title string }
+		EndCursor   string
+		HasNextPage bool
+	}
+	_, result = graphQuery(t, "", query, nil)
+	json.Unmarshal(result.Data["posts"], &page)
+	if len(page.Nodes) != 1 || !page.HasNextPage {
+		t.Fatalf("First page should have one post and more, got %+v %+v", page, result.Errors)
+	}
+	seen := page.Nodes[0].ID
+	_, result = graphQuery(t, "", query, map[string]interface{}{"after": page.EndCursor})
+	json.Unmarshal(result.Data["posts"], &page)
+	if len(page.Nodes) != 1 || page.Nodes[0].ID == seen || page.HasNextPage {
+		t.Errorf("Second page should have other post and be last, got %+v", page)
+	}
+
+	drafts := `{ posts(filter: {status: DRAFT, tag: "graph"}) { nodes { id } } }`
+	if _, result = graphQuery(t, "", drafts, nil); strings.Contains(string(result.Data["posts"]), ids[2]) {
+		t.Error("Drafts should be hidden from anonymous clients")
+	}
+	if _, result = graphQuery(t, token, drafts, nil); !strings.Contains(string(result.Data["posts"]), ids[2]) {
+		t.Errorf("Author should see own draft, got %s", result.Data["posts"])
+	}
+	other := apiLogin(t, "graphreader")
+	if _, result = graphQuery(t, other, drafts, nil); strings.Contains(string(result.Data["posts"]), ids[2]) {
+		t.Error("Drafts should be hidden from other authors")
+	}
+	admin := apiSession(t, "tester")
+	if _, result = graphQuery(t, admin, drafts, nil); !strings.Contains(string(result.Data["posts"]), ids[2]) {
+		t.Errorf("Admin should see drafts of others, got %s", result.Data["posts"])
+	}
+
+	roles := `{ posts(filter: {tag: "graph"}, first: 1) { nodes { author { role } } } }`
+	var authors struct {
+		Nodes []struct{ Author struct{ Role *string } }
+	}
+	_, result = graphQuery(t, other, roles, nil)
+	json.Unmarshal(result.Data["posts"], &authors)
+	if len(authors.Nodes) != 1 || authors.Nodes[0].Author.Role != nil {
+		t.Errorf("Role should be hidden from non-admins, got %s %+v", result.Data["posts"], result.Errors)
+	}
+	_, result = graphQuery(t, admin, roles, nil)
+	json.Unmarshal(result.Data["posts"], &authors)
+	if len(authors.Nodes) != 1 || authors.Nodes[0].Author.Role == nil || *authors.Nodes[0].Author.Role != models.RoleAuthor {
+		t.Errorf("Admin should see roles, got %s %+v", result.Data["posts"], result.Errors)
+	}
+
+	update := `mutation($id: ID!, $version: Int!) {
+		updatePost(id: $id, version: $version, input: {title: "Graph draft edited", status: PUBLISHED}) { slug version status } }`
+	_, result = graphQuery(t, token, update, map[string]interface{}{"id": ids[2], "version": 0})
+	var updated struct{ Slug, Status string }
+	json.Unmarshal(result.Data["updatePost"], &updated)
+	if len(result.Errors) > 0 || updated.Slug != "graph-draft-edited" || updated.Status != "PUBLISHED" {
+		t.Errorf("Update should publish draft, got %+v %+v", updated, result.Errors)
+	}
+	_, result = graphQuery(t, token, update, map[string]interface{}{"id": ids[2], "version": 0})
+	if len(result.Errors) == 0 || result.Errors[0].Message != models.ErrVersionConflict.Error() {
+		t.Errorf("Stale update should conflict, got %+v", result)
+	}
+	_, result = graphQuery(t, other, update, map[string]interface{}{"id": ids[2], "version": 1})
+	if len(result.Errors) == 0 || result.Errors[0].Message != models.ErrForbidden.Error() {
+		t.Errorf("Other author update should be forbidden, got %+v", result)
+	}
+
+	_, result = graphQuery(t, "", `query($id: ID) { post(id: $id) { title } missing: post(slug: "nope") { title } }`,
+		map[string]interface{}{"id": ids[2]})
+	if !strings.Contains(string(result.Data["post"]), "Graph draft edited") || string(result.Data["missing"]) != "null" {
+		t.Errorf("Should find post by id and null for missing slug, got %+v", result.Data)
+	}
+}
+
+func TestGraphQLRequests(t *testing.T) {
+	if code, result := graphQuery(t, "", `{ posts { nodes { id } `, nil); code != http.StatusBadRequest || len(result.Errors) == 0 {
+		t.Errorf("Malformed query should be 400, got %v", code)
+	}
+	if code, _ := graphQuery(t, "", `{ posts { nodes { nope } } }`, nil); code != http.StatusBadRequest {
+		t.Errorf("Invalid query should be 400, got %v", code)
+	}
+
+	deep := `{ tags { posts { author { name } } } }`
+	if code, result := graphQuery(t, "", deep, nil); code != http.StatusOK || len(result.Errors) > 0 {
+		t.Errorf("Query in limits should run, got %v %+v", code, result.Errors)
+	}
+	deep = `fragment p on Post { comments { author } } { posts { nodes { ...p author { name } } } tags { posts(first: 2) { ...p } } }`
+	if code, _ := graphQuery(t, "", deep, nil); code != http.StatusOK {
+		t.Errorf("Fragments in limits should run, got %v", code)
+	}
+	expensive := `query($n: Int) { posts(first: $n) { nodes { comments { author text createdAt } } } }`
+	code, result := graphQuery(t, "", expensive, map[string]interface{}{"n": 100})
+	if code != http.StatusBadRequest || len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Message, "complexity") {
+		t.Errorf("Too complex query should be 400, got %v %+v", code, result)
+	}
+	if code, _ = graphQuery(t, "", `{ posts(first: 101) { endCursor } }`, nil); code != http.StatusOK {
+		t.Errorf("Page size error should be in result, got %v", code)
+	}
+
+	beego.AppConfig.Set("graphqlMaxDepth", "3")
+	defer beego.AppConfig.Set("graphqlMaxDepth", "8")
+	code, result = graphQuery(t, "", `{ tags { posts { author { name } } } }`, nil)
+	if code != http.StatusBadRequest || len(result.Errors) == 0 || !strings.Contains(result.Errors[0].Message, "depth") {
+		t.Errorf("Too deep query should be 400, got %v %+v", code, result)
+	}
+
+	w := serve("GET", "/graphql?query="+url.QueryEscape(`{ tags { name } }`), nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"tags"`) {
+		t.Errorf("Query over GET should run, got %v %v", w.Code, w.Body.String())
+	}
+	w = serve("GET", "/graphql?query="+url.QueryEscape(`mutation { createPost(input: {title: "x"}) { id } }`), nil)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Mutation over GET should be 405, got %v", w.Code)
+	}
+}
+
+// countingStore counts store calls resolvers make
+type countingStore struct {
+	models.Store
+	calls map[string]int
+}
+
+func (s *countingStore) ListPosts() ([]models.BlogPost, error) {
+	s.calls["ListPosts"]++
+	return s.Store.ListPosts()
+}
+
+func (s *countingStore) ListPostsByTag(tag string) ([]models.BlogPost, error) {
+	s.calls["ListPostsByTag"]++
+	return s.Store.ListPostsByTag(tag)
+}
+
+func (s *countingStore) ListComments(id primitive.ObjectID) ([]models.Comment, error) {
+	s.calls["ListComments"]++
+	return s.Store.ListComments(id)
+}
+
+func (s *countingStore) ListCommentsOf(ids []primitive.ObjectID) (map[primitive.ObjectID][]models.Comment, error) {
+	s.calls["ListCommentsOf"]++
+	return s.Store.ListCommentsOf(ids)
+}
+
+func (s *countingStore) CountComments(ids []primitive.ObjectID) (map[primitive.ObjectID]int, error) {
+	s.calls["CountComments"]++
+	return s.Store.CountComments(ids)
+}
+
+func (s *countingStore) GetUser(id primitive.ObjectID) (*models.User, error) {
+	s.calls["GetUser"]++
+	return s.Store.GetUser(id)
+}
+
+func (s *countingStore) ListUsers() ([]models.User, error) {
+	s.calls["ListUsers"]++
+	return s.Store.ListUsers()
+}
+
+func TestGraphQLBatching(t *testing.T) {
+	memory := models.NewMemoryPostStore()
+	store := &countingStore{Store: memory, calls: map[string]int{}}
+	for i := 0; i < 4; i++ {
+		user, _ := models.Register(memory, fmt.Sprintf("batch%v", i), "batch password")
+		post := &models.BlogPost{Title: fmt.Sprintf("Batch %v", i), Status: models.StatusPublished,
+			Tags: []string{"batch", fmt.Sprintf("tag%v", i)}, AuthorID: user.ID}
+		if err := memory.CreatePost(post); err != nil {
+			t.Fatal(err)
+		}
+		models.PostComment(memory, &models.Comment{PostID: post.ID, Author: "reader", Text: "comment"}, nil)
+	}
+
+	handlers := beego.NewControllerRegister()
+	handlers.Add("/graphql", &controllers.MainController{Store: store, Index: search.NewIndex()}, "post:GraphQL")
+	query := `{ posts(first: 4) { nodes { title author { name } comments { text } commentCount } }
+		tags { name posts(first: 2) { title author { name } commentCount } } }`
+	body, _ := json.Marshal(map[string]string{"query": query})
+	r, _ := http.NewRequest("POST", "/graphql", strings.NewReader(string(body)))
+	w := httptest.NewRecorder()
+	handlers.ServeHTTP(w, r)
+
+	result := &graphResult{}
+	if err := json.Unmarshal(w.Body.Bytes(), result); err != nil || len(result.Errors) > 0 {
+		t.Fatalf("Query should succeed, got %v %v", w.Code, w.Body.String())
+	}
+	var posts struct {
+		Nodes []struct {
+			Author       struct{ Name string }
+			Comments     []struct{ Text string }
+			CommentCount int
+		}
+	}
+	json.Unmarshal(result.Data["posts"], &posts)
+	if len(posts.Nodes) != 4 || posts.Nodes[0].Author.Name == "" || len(posts.Nodes[3].Comments) != 1 || posts.Nodes[3].CommentCount != 1 {
+		t.Errorf("Wrong posts %s", result.Data["posts"])
+	}
+	var tags []struct {
+		Name  string
+		Posts []struct{ Author struct{ Name string } }
+	}
+	json.Unmarshal(result.Data["tags"], &tags)
+	if len(tags) != 5 || tags[4].Name != "tag3" || len(tags[4].Posts) != 1 || tags[4].Posts[0].Author.Name != "batch3" ||
+		len(tags[0].Posts) != 2 {
+		t.Errorf("Wrong tags %s", result.Data["tags"])
+	}
+
+	// tag posts were loaded for posts already, so their counts are not read again
+	want := map[string]int{"ListCommentsOf": 1, "CountComments": 1, "ListUsers": 1, "ListPosts": 1}
+	for name, n := range want {
+		if store.calls[name] != n {
+			t.Errorf("%v should be called %v times, got %v", name, n, store.calls[name])
+		}
+	}
+	if store.calls["ListComments"]+store.calls["GetUser"]+store.calls["ListPostsByTag"] > 0 {
+		t.Errorf("Should not load per post, got %v", store.calls)
+	}
+}
@@ -0,0 +1,243 @@
+package tests
+
+import (
+	"context"
+	"hw8/blogpb"
+	"hw8/controllers"
+	"hw8/models"
+	"hw8/search"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// grpcClient serves gRPC api of store over in-process listener
+func grpcClient(t *testing.T, store models.Store) *grpc.ClientConn {
+	lis := bufconn.Listen(1 << 20)
+	srv := controllers.NewGRPCServer(store, search.NewIndex())
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// grpcUser registers author and returns context with session token
+func grpcUser(t *testing.T, store models.Store, name string) context.Context {
+	user, err := models.Register(store, name, name+" password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return grpcSession(t, store, user)
+}
+
+// grpcAdmin creates admin like the configured one and returns context with session token
+func grpcAdmin(t *testing.T, store models.Store, name string) context.Context {
+	user, err := models.EnsureAdmin(store, name, name+" password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return grpcSession(t, store, user)
+}
+
+func grpcSession(t *testing.T, store models.Store, user *models.User) context.Context {
+	token, err := models.StartSession(store, user.ID, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+// grpcCode is status code of call error
+func grpcCode(err error) codes.Code {
+	return status.Code(err)
+}
+
+func TestGRPCServices(t *testing.T) {
+	conn := grpcClient(t, models.NewMemoryPostStore())
+	ctx := context.Background()
+
+	res, err := healthpb.NewHealthClient(conn).Check(ctx,
+		&healthpb.HealthCheckRequest{Service: blogpb.BlogService_ServiceDesc.ServiceName})
+	if err != nil || res.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("Blog service should be serving, got %v %v", res, err)
+	}
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, s := range reply.GetListServicesResponse().GetService() {
+		found = found || s.Name == blogpb.BlogService_ServiceDesc.ServiceName
+	}
+	if !found {
+		t.Errorf("Reflection should list blog service, got %v", reply)
+	}
+}
+
+func TestGRPCPosts(t *testing.T) {
+	store := models.NewMemoryPostStore()
+	admin := grpcAdmin(t, store, "grpcadmin")
+	author := grpcUser(t, store, "grpcauthor")
+	other := grpcUser(t, store, "grpcother")
+	client := blogpb.NewBlogServiceClient(grpcClient(t, store))
+	anonymous := context.Background()
+
+	_, err := client.CreatePost(anonymous, &blogpb.CreatePostRequest{Post: &blogpb.PostInput{Title: "x"}})
+	if grpcCode(err) != codes.Unauthenticated {
+		t.Errorf("Anonymous create should be Unauthenticated, got %v", err)
+	}
+
+	_, err = client.CreatePost(author, &blogpb.CreatePostRequest{Post: &blogpb.PostInput{
+		Link: "ftp://x", Status: blogpb.Status_STATUS_SCHEDULED}})
+	violations := map[string]bool{}
+	for _, d := range status.Convert(err).Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.FieldViolations {
+				violations[v.Field] = true
+			}
+		}
+	}
+	if grpcCode(err) != codes.InvalidArgument || !violations["title"] || !violations["link"] || !violations["publish_at"] {
+		t.Errorf("Invalid post should be InvalidArgument with field violations, got %v %v", err, violations)
+	}
+
+	post, err := client.CreatePost(author, &blogpb.CreatePostRequest{Post: &blogpb.PostInput{
+		Title: "Grpc post", Content: "Text", Tags: []string{"Go", "RPC"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.Status != blogpb.Status_STATUS_DRAFT || post.Slug != "grpc-post" || len(post.Tags) != 2 || post.Tags[0] != "go" {
+		t.Errorf("Wrong created post %v", post)
+	}
+
+	if _, err = client.GetPost(anonymous, &blogpb.GetPostRequest{Id: post.Id}); grpcCode(err) != codes.NotFound {
+		t.Errorf("Draft should be hidden from anonymous clients, got %v", err)
+	}
+	if _, err = client.GetPost(anonymous, &blogpb.GetPostRequest{Id: "bad"}); grpcCode(err) != codes.NotFound {
+		t.Errorf("Bad id should be NotFound, got %v", err)
+	}
+	got, err := client.GetPost(author, &blogpb.GetPostRequest{Id: post.Id})
+	if err != nil || got.Title != "Grpc post" {
+		t.Fatalf("Author should get draft, got %v %v", got, err)
+	}
+
+	update := &blogpb.UpdatePostRequest{Id: post.Id, Post: &blogpb.PostInput{
+		Title: "Grpc post edited", Status: blogpb.Status_STATUS_PUBLISHED}}
+	if _, err = client.UpdatePost(author, update); grpcCode(err) != codes.FailedPrecondition {
+		t.Errorf("Update without version should be FailedPrecondition, got %v", err)
+	}
+	update.Version = proto.Int32(got.Version)
+	if _, err = client.UpdatePost(other, update); grpcCode(err) != codes.NotFound {
+		t.Errorf("Draft should be hidden from other authors, got %v", err)
+	}
+	updated, err := client.UpdatePost(author, update)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Title != "Grpc post edited" || updated.Status != blogpb.Status_STATUS_PUBLISHED || updated.Version != got.Version+1 {
+		t.Errorf("Wrong updated post %v", updated)
+	}
+	if _, err = client.UpdatePost(admin, update); grpcCode(err) != codes.Aborted {
+		t.Errorf("Stale version should be Aborted, got %v", err)
+	}
+	id, _ := primitive.ObjectIDFromHex(post.Id)
+	if revisions, err := store.ListRevisions(id); err != nil || len(revisions) != 2 {
+		t.Errorf("Create and update should keep revisions, got %v %v", len(revisions), err)
+	}
+
+	if _, err = client.DeletePost(other, &blogpb.DeletePostRequest{Id: post.Id}); grpcCode(err) != codes.PermissionDenied {
+		t.Errorf("Delete by other author should be PermissionDenied, got %v", err)
+	}
+	if _, err = client.DeletePost(author, &blogpb.DeletePostRequest{Id: post.Id}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = client.GetPost(author, &blogpb.GetPostRequest{Id: post.Id}); grpcCode(err) != codes.NotFound {
+		t.Errorf("Deleted post should be NotFound, got %v", err)
+	}
+}
+
+// listPosts reads titles of streamed posts
+func listPosts(t *testing.T, client blogpb.BlogServiceClient, req *blogpb.ListPostsRequest) []string {
+	stream, err := client.ListPosts(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	titles := []string{}
+	for {
+		post, err := stream.Recv()
+		if err == io.EOF {
+			return titles
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		titles = append(titles, post.Title)
+	}
+}
+
+func TestGRPCListPosts(t *testing.T) {
+	store := models.NewMemoryPostStore()
+	author := grpcUser(t, store, "grpclister")
+	client := blogpb.NewBlogServiceClient(grpcClient(t, store))
+
+	day := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, title := range []string{"First", "Second", "Third"} {
+		tags := []string{"all"}
+		if i != 1 {
+			tags = append(tags, "odd")
+		}
+		_, err := client.CreatePost(author, &blogpb.CreatePostRequest{Post: &blogpb.PostInput{
+			Title: title, Tags: tags, Status: blogpb.Status_STATUS_PUBLISHED,
+			Date: timestamppb.New(day.AddDate(0, 0, i))}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err := client.CreatePost(author, &blogpb.CreatePostRequest{Post: &blogpb.PostInput{Title: "Draft", Tags: []string{"odd"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if titles := listPosts(t, client, &blogpb.ListPostsRequest{}); len(titles) != 3 || titles[0] != "Third" || titles[2] != "First" {
+		t.Errorf("Should stream published posts newest first, got %v", titles)
+	}
+	if titles := listPosts(t, client, &blogpb.ListPostsRequest{Limit: 2}); len(titles) != 2 || titles[1] != "Second" {
+		t.Errorf("Limit should cap streamed posts, got %v", titles)
+	}
+	if titles := listPosts(t, client, &blogpb.ListPostsRequest{Tag: "Odd"}); len(titles) != 2 || titles[0] != "Third" || titles[1] != "First" {
+		t.Errorf("Tag should filter posts, got %v", titles)
+	}
+}
@@ -0,0 +1,69 @@
+package tests
+
+import (
+	"hw8/markdown"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestMarkdownRender(t *testing.T) {
+	r := markdown.NewRenderer(2)
+	cases := []struct {
+		src, want string
+	}{
+		{"# Title\n\n*em* and **strong**", "<h1>Title</h1>"},
+		{"| a | b |\n|---|:-:|\n| 1 | 2 |", `<td align="center">2</td>`},
+		{"```go\nfmt.Println(1 < 2)\n```", `<code class="language-go">fmt.Println(1 &lt; 2)`},
+		{"see https://example.com now", `<a href="https://example.com" rel="nofollow">https://example.com</a>`},
+	}
+	for _, c := range cases {
+		if got := string(r.Render(c.src)); !strings.Contains(got, c.want) {
+			t.Errorf("Render(%q) = %q, should contain %q", c.src, got, c.want)
+		}
+	}
+
+	unsafe := []string{
+		"<script>alert(1)</script>",
+		`<img src="x" onerror="alert(1)">`,
+		"[click](javascript:alert(1))",
+		`<a href="javascript:alert(1)">click</a>`,
+		`<iframe src="https://evil.example.com"></iframe>`,
+	}
+	for _, src := range unsafe {
+		got := strings.ToLower(string(r.Render(src)))
+		if strings.Contains(got, "<script") || strings.Contains(got, "onerror") ||
+			strings.Contains(got, "javascript:") || strings.Contains(got, "<iframe") {
+			t.Errorf("Render(%q) = %q, should be sanitized", src, got)
+		}
+	}
+
+	if r.Cached() != 2 {
+		t.Errorf("Cache should keep 2 texts, got %v", r.Cached())
+	}
+}
+
+func TestPreviewAndShowMarkdown(t *testing.T) {
+	w := serve("POST", "/preview", url.Values{"content": {"**bold** <script>x</script>"}})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "<strong>bold</strong>") ||
+		strings.Contains(w.Body.String(), "<script>") {
+		t.Errorf("Preview should render sanitized html, got %v %q", w.Code, w.Body.String())
+	}
+	if w = serveRequest(newFormRequest("POST", "/preview", url.Values{"content": {"x"}})); w.Code != http.StatusSeeOther {
+		t.Errorf("Anonymous preview should go to login, got %v", w.Code)
+	}
+
+	m := postIDField.FindStringSubmatch(serve("POST", "/new", nil).Body.String())
+	if m == nil {
+		t.Fatal("Should render new post id")
+	}
+	form := url.Values{"id": {m[1]}, "title": {"Markdown"}, "content": {"## Heading\n\n<script>alert(1)</script>"}, "version": {"0"}}
+	if w = serve("POST", "/edit", form); w.Code != http.StatusOK {
+		t.Fatalf("Edit should succeed, got %v", w.Code)
+	}
+	body := serve("GET", permalink(t, m[1]), nil).Body.String()
+	if !strings.Contains(body, "<h2>Heading</h2>") || strings.Contains(body, "<script>alert") {
+		t.Error("Post page should show rendered and sanitized content")
+	}
+}
@@ -0,0 +1,217 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"hw8/migrations"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestMigrationsOrdered(t *testing.T) {
+	if err := migrations.Validate(migrations.All); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMigrationsValidate(t *testing.T) {
+	list := []migrations.Migration{
+		{Version: 2, Name: "second", Up: []string{"select 1"}, Down: []string{"select 1"}},
+		{Version: 1, Name: "first", Up: []string{"select 1"}, Down: []string{"select 1"}},
+	}
+	if err := migrations.Validate(list); err == nil {
+		t.Error("Should fail for out of order migrations")
+	}
+
+	changed := list[0]
+	changed.Up = []string{"select 2"}
+	if changed.Checksum() == list[0].Checksum() {
+		t.Error("Checksum should change with migration")
+	}
+}
+
+// fakeSchema is database of fake sql driver, it keeps schema_migrations
+// rows and logs other statements so migrator runs without mysql
+type fakeSchema struct {
+	mu       sync.Mutex
+	applied  map[int]string
+	executed []string
+	// failOn makes statement with this text fail
+	failOn string
+}
+
+func (s *fakeSchema) Connect(context.Context) (driver.Conn, error) { return &fakeConn{s: s}, nil }
+func (s *fakeSchema) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct {
+	s *fakeSchema
+	// undo restores schema on rollback
+	undo *fakeSchema
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{c: c, query: query}, nil
+}
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+	c.undo = &fakeSchema{applied: map[int]string{}, executed: append([]string{}, c.s.executed...)}
+	for v, sum := range c.s.applied {
+		c.undo.applied[v] = sum
+	}
+	return c, nil
+}
+
+func (c *fakeConn) Commit() error {
+	c.undo = nil
+	return nil
+}
+
+func (c *fakeConn) Rollback() error {
+	c.s.mu.Lock()
+	defer c.s.mu.Unlock()
+	c.s.applied, c.s.executed, c.undo = c.undo.applied, c.undo.executed, nil
+	return nil
+}
+
+type fakeStmt struct {
+	c     *fakeConn
+	query string
+}
+
+func (st *fakeStmt) Close() error  { return nil }
+func (st *fakeStmt) NumInput() int { return -1 }
+
+func (st *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s := st.c.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case st.query == s.failOn:
+		return nil, errors.New("fake failure")
+	case strings.HasPrefix(st.query, "create table if not exists schema_migrations"):
+	case strings.HasPrefix(st.query, "insert into schema_migrations"):
+		s.applied[int(args[0].(int64))] = args[2].(string)
+	case strings.HasPrefix(st.query, "delete from schema_migrations"):
+		delete(s.applied, int(args[0].(int64)))
+	default:
+		s.executed = append(s.executed, st.query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (st *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s := st.c.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rows := &fakeRows{}
+	for v, sum := range s.applied {
+		rows.rows = append(rows.rows, []driver.Value{int64(v), sum, "2020-02-21 10:00:00"})
+	}
+	return rows, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return []string{"version", "checksum", "applied_at"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func testMigrator() (*migrations.Migrator, *fakeSchema) {
+	schema := &fakeSchema{applied: map[int]string{}}
+	m := migrations.NewMigrator(sql.OpenDB(schema))
+	m.Migrations = []migrations.Migration{
+		{Version: 1, Name: "first", Up: []string{"create 1"}, Down: []string{"drop 1"}},
+		{Version: 2, Name: "second", Up: []string{"create 2", "index 2"}, Down: []string{"drop index 2", "drop 2"}},
+		{Version: 3, Name: "third", Up: []string{"create 3"}, Down: []string{"drop 3"}},
+	}
+	return m, schema
+}
+
+// versions lists migration versions
+func versions(list []migrations.Migration) []int {
+	r := []int{}
+	for _, m := range list {
+		r = append(r, m.Version)
+	}
+	return r
+}
+
+func TestMigratorUpDown(t *testing.T) {
+	m, schema := testMigrator()
+
+	done, err := m.Up(2)
+	if err != nil || fmt.Sprint(versions(done)) != "[1 2]" {
+		t.Fatalf("Up to 2 should apply 1 and 2, got %v %v", versions(done), err)
+	}
+	done, err = m.Up(0)
+	if err != nil || fmt.Sprint(versions(done)) != "[3]" {
+		t.Fatalf("Up should apply pending 3, got %v %v", versions(done), err)
+	}
+	if done, _ = m.Up(0); len(done) != 0 {
+		t.Errorf("Applied migrations should not run again, got %v", versions(done))
+	}
+	if got := strings.Join(schema.executed, ","); got != "create 1,create 2,index 2,create 3" {
+		t.Errorf("Up statements should run in order, got %v", got)
+	}
+
+	status, err := m.Status()
+	if err != nil || len(status) != 3 || !status[2].Applied || status[2].AppliedAt.IsZero() {
+		t.Fatalf("Status should show applied migrations, got %+v %v", status, err)
+	}
+
+	schema.executed = nil
+	done, err = m.Down(2)
+	if err != nil || fmt.Sprint(versions(done)) != "[3 2]" {
+		t.Fatalf("Down 2 should roll back 3 then 2, got %v %v", versions(done), err)
+	}
+	if got := strings.Join(schema.executed, ","); got != "drop 3,drop index 2,drop 2" {
+		t.Errorf("Down statements should run in order, got %v", got)
+	}
+	status, _ = m.Status()
+	if !status[0].Applied || status[1].Applied || status[2].Applied {
+		t.Errorf("Only first migration should stay applied, got %+v", status)
+	}
+}
+
+func TestMigratorFailure(t *testing.T) {
+	m, schema := testMigrator()
+	schema.failOn = "index 2"
+
+	done, err := m.Up(0)
+	if err == nil || fmt.Sprint(versions(done)) != "[1]" {
+		t.Fatalf("Up should stop at failed migration, got %v %v", versions(done), err)
+	}
+	status, _ := m.Status()
+	if !status[0].Applied || status[1].Applied || status[2].Applied {
+		t.Errorf("Failed migration should not be recorded, got %+v", status)
+	}
+
+	schema.failOn = ""
+	m.Migrations[0].Up = []string{"create 1 changed"}
+	if _, err := m.Up(0); err == nil {
+		t.Error("Changed applied migration should fail")
+	}
+	m.Migrations = m.Migrations[1:]
+	if _, err := m.Status(); err == nil {
+		t.Error("Unknown applied migration should fail")
+	}
+}
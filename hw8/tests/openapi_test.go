@@ -0,0 +1,126 @@
+package tests
+
+import (
+	"encoding/json"
+	"hw8/models"
+	"hw8/openapi"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/astaxie/beego"
+)
+
+// apiSpec loads served OpenAPI document
+func apiSpec(t *testing.T) *openapi.Document {
+	w := serve("GET", "/api/openapi.json", nil)
+	spec := &openapi.Document{}
+	if err := json.Unmarshal(w.Body.Bytes(), spec); w.Code != http.StatusOK || err != nil {
+		t.Fatalf("Should send OpenAPI document, got %v %v", w.Code, err)
+	}
+	return spec
+}
+
+// snakeCase is json name api uses for Go field name
+func snakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) && unicode.IsLower(runes[i-1]) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+func TestAPISpecMatchesRoutes(t *testing.T) {
+	documented := map[string]string{}
+	for path, item := range apiSpec(t).Paths {
+		for method, op := range *item {
+			documented[strings.ToUpper(method)+" "+path] = op.OperationID
+		}
+	}
+
+	routed := map[string]string{}
+	for method, routes := range beego.PrintTree()["Data"].(beego.M) {
+		for _, route := range *routes.(*[][]string) {
+			if !strings.HasPrefix(route[0], "/api/v") {
+				continue
+			}
+			// route[1] prints handlers by method like map[GET:List POST:Create]
+			handlers := strings.Fields(strings.TrimSuffix(strings.TrimPrefix(route[1], "map["), "]"))
+			for _, handler := range handlers {
+				if parts := strings.SplitN(handler, ":", 2); parts[0] == method {
+					routed[method+" "+openapi.PathOf(route[0])] = parts[1]
+				}
+			}
+		}
+	}
+
+	if len(routed) == 0 {
+		t.Fatal("Should find api routes")
+	}
+	for route, handler := range routed {
+		if documented[route] != handler {
+			t.Errorf("Route %v to %v is documented as %q", route, handler, documented[route])
+		}
+	}
+	for route := range documented {
+		if _, ok := routed[route]; !ok {
+			t.Errorf("Documented %v has no route", route)
+		}
+	}
+}
+
+func TestAPISpecHasPostFields(t *testing.T) {
+	spec := apiSpec(t)
+	post := spec.Components.Schemas["Post"]
+	if post == nil {
+		t.Fatal("Should define Post schema")
+	}
+	// fields api does not send
+	hidden := map[string]bool{"deleted_at": true}
+	postType := reflect.TypeOf(models.BlogPost{})
+	for i := 0; i < postType.NumField(); i++ {
+		name := snakeCase(postType.Field(i).Name)
+		if _, ok := post.Properties[name]; !ok && !hidden[name] {
+			t.Errorf("BlogPost field %v is missing in Post schema", name)
+		}
+	}
+
+	if date := post.Properties["date"]; date.Type != "string" || date.Format != "date-time" {
+		t.Errorf("Date should be date-time string, got %+v", date)
+	}
+	if status := post.Properties["status"]; strings.Join(status.Enum, ",") != "draft,scheduled,published" {
+		t.Errorf("Status should list statuses, got %+v", status)
+	}
+	if page := spec.Components.Schemas["PostsPage"]; page.Properties["posts"].Items.Ref != "#/components/schemas/Post" {
+		t.Error("Posts page should refer to Post schema")
+	}
+	input := spec.Components.Schemas["PostInput"]
+	if strings.Join(input.Required, ",") != "title" || !input.Properties["version"].Nullable {
+		t.Errorf("Only post title should be required, got %v", input.Required)
+	}
+
+	create := (*spec.Paths["/api/v1/posts/{id}"])["put"]
+	if create == nil || len(create.Security) == 0 || create.Responses["401"].Description == "" ||
+		create.Parameters[0].Name != "id" || create.Parameters[0].In != "path" {
+		t.Errorf("Update should need token and take id in path, got %+v", create)
+	}
+	if list := (*spec.Paths["/api/v1/posts"])["get"]; len(list.Security) != 0 {
+		t.Error("Listing posts should be public")
+	}
+}
+
+func TestAPIDocs(t *testing.T) {
+	w := serve("GET", "/api/docs", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `<a href="/api/openapi.json">`) {
+		t.Errorf("Should show docs page reading spec, got %v", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "<script src=") {
+		t.Error("Docs page should not load scripts from elsewhere")
+	}
+}
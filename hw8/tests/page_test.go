@@ -0,0 +1,209 @@
+package tests
+
+import (
+	"fmt"
+	"hw8/models"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMemoryPostsPage(t *testing.T) {
+	testPostsPage(t, models.NewMemoryPostStore())
+}
+
+func TestBoltPostsPage(t *testing.T) {
+	store, err := models.NewBoltPostStore(filepath.Join(t.TempDir(), "blog.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	testPostsPage(t, store)
+}
+
+func TestMemoryDatedPostsPage(t *testing.T) {
+	testDatedPostsPage(t, models.NewMemoryPostStore())
+}
+
+func TestBoltDatedPostsPage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blog.db")
+	store, err := models.NewBoltPostStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testDatedPostsPage(t, store)
+
+	// data files written before the date index existed get it on open
+	err = store.DB.Update(func(tx *bbolt.Tx) error {
+		return tx.DeleteBucket([]byte("postdates"))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+	if store, err = models.NewBoltPostStore(path); err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if page, _ := models.ListPage(store, "", 10); pageTitles(page) != "ecadb" {
+		t.Errorf("Reopened store should page by date, got %q", pageTitles(page))
+	}
+}
+
+func pageTitles(page *models.Page) string {
+	s := ""
+	for _, p := range page.Posts {
+		s += p.Title
+	}
+	return s
+}
+
+func testPostsPage(t *testing.T, store models.PostStore) {
+	posts := []*models.BlogPost{}
+	for i := 0; i < 7; i++ {
+		post := &models.BlogPost{Title: fmt.Sprint(i)}
+		if err := store.CreatePost(post); err != nil {
+			t.Fatal(err)
+		}
+		posts = append(posts, post)
+	}
+	if err := store.DeletePost(posts[3].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := models.ListPage(store, "", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pageTitles(page) != "65" || page.Prev != "" || page.Next == "" {
+		t.Fatalf("Wrong first page %q", pageTitles(page))
+	}
+
+	page, _ = models.ListPage(store, page.Next, 2)
+	if pageTitles(page) != "42" || page.Prev == "" || page.Next == "" {
+		t.Fatalf("Deleted post should be skipped, got %q", pageTitles(page))
+	}
+	second := page
+
+	page, _ = models.ListPage(store, page.Next, 2)
+	if pageTitles(page) != "10" || page.Prev == "" || page.Next != "" {
+		t.Fatalf("Wrong last page %q", pageTitles(page))
+	}
+
+	page, _ = models.ListPage(store, page.Prev, 2)
+	if pageTitles(page) != pageTitles(second) || page.Next == "" {
+		t.Fatalf("Prev should return to second page, got %q", pageTitles(page))
+	}
+	page, _ = models.ListPage(store, page.Prev, 2)
+	if pageTitles(page) != "65" || page.Prev != "" {
+		t.Fatalf("Prev should return to first page, got %q", pageTitles(page))
+	}
+
+	if _, err := models.ListPage(store, "garbage", 2); err != models.ErrBadCursor {
+		t.Error("Should be ErrBadCursor")
+	}
+}
+
+// testDatedPostsPage pages posts whose dates differ from creation order,
+// posts of the same date are ordered by id
+func testDatedPostsPage(t *testing.T, store models.PostStore) {
+	day := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	for _, p := range []struct {
+		title string
+		days  int
+	}{{"a", 1}, {"b", 0}, {"c", 1}, {"d", 0}, {"e", 2}} {
+		post := &models.BlogPost{Title: p.title, Date: day.AddDate(0, 0, p.days)}
+		if err := store.CreatePost(post); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	page, _ := models.ListPage(store, "", 2)
+	if pageTitles(page) != "ec" {
+		t.Fatalf("First page should have latest dates, got %q", pageTitles(page))
+	}
+	page, _ = models.ListPage(store, page.Next, 2)
+	if pageTitles(page) != "ad" {
+		t.Fatalf("Second page should continue within the same date, got %q", pageTitles(page))
+	}
+	page, _ = models.ListPage(store, page.Next, 2)
+	if pageTitles(page) != "b" || page.Next != "" {
+		t.Fatalf("Wrong last page %q", pageTitles(page))
+	}
+	page, _ = models.ListPage(store, page.Prev, 2)
+	if pageTitles(page) != "ad" {
+		t.Errorf("Prev should return to second page, got %q", pageTitles(page))
+	}
+}
+
+func TestMemoryFilteredPostsPage(t *testing.T) {
+	testFilteredPostsPage(t, models.NewMemoryPostStore())
+}
+
+func TestBoltFilteredPostsPage(t *testing.T) {
+	store, err := models.NewBoltPostStore(filepath.Join(t.TempDir(), "blog.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	testFilteredPostsPage(t, store)
+}
+
+// testFilteredPostsPage pages posts of a tag and month, posts outside
+// the month, without the tag or unpublished are skipped
+func testFilteredPostsPage(t *testing.T, store models.PostStore) {
+	may := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	author := primitive.NewObjectID()
+	for _, p := range []struct {
+		title, tag, status string
+		date               time.Time
+	}{
+		{"a", "go", models.StatusPublished, may.AddDate(0, -1, 0)},
+		{"b", "go", models.StatusPublished, may},
+		{"c", "go", models.StatusDraft, may.AddDate(0, 0, 1)},
+		{"d", "db", models.StatusPublished, may.AddDate(0, 0, 2)},
+		{"e", "go", models.StatusPublished, may.AddDate(0, 0, 3)},
+		{"f", "go", models.StatusPublished, may.AddDate(0, 1, 0)},
+	} {
+		post := &models.BlogPost{Title: p.title, Tags: []string{p.tag}, Status: p.status, Date: p.date, AuthorID: author}
+		if err := store.CreatePost(post); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	filter := models.PostFilter{Tag: "go", Status: models.StatusPublished, From: may, To: may.AddDate(0, 1, 0)}
+	page, err := models.ListFilteredPage(store, filter, "", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pageTitles(page) != "e" || page.Next == "" {
+		t.Fatalf("First page should have latest matching post, got %q", pageTitles(page))
+	}
+	page, _ = models.ListFilteredPage(store, filter, page.Next, 1)
+	if pageTitles(page) != "b" || page.Next != "" {
+		t.Fatalf("Last page should stop at month start, got %q %q", pageTitles(page), page.Next)
+	}
+	page, _ = models.ListFilteredPage(store, filter, page.Prev, 1)
+	if pageTitles(page) != "e" || page.Prev != "" {
+		t.Errorf("Prev should stop at month end, got %q %q", pageTitles(page), page.Prev)
+	}
+
+	drafts := models.PostFilter{Status: models.StatusDraft, AuthorID: author}
+	if page, _ = models.ListFilteredPage(store, drafts, "", 10); pageTitles(page) != "c" {
+		t.Errorf("Draft filter should list drafts of author, got %q", pageTitles(page))
+	}
+	drafts.AuthorID = primitive.NewObjectID()
+	if page, _ = models.ListFilteredPage(store, drafts, "", 10); pageTitles(page) != "" {
+		t.Errorf("Draft filter should skip other authors, got %q", pageTitles(page))
+	}
+}
+
+func TestListPostsBadCursor(t *testing.T) {
+	if w := serve("GET", "/?cursor=garbage", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("Bad cursor should be 400, got %v", w.Code)
+	}
+}
@@ -0,0 +1,128 @@
+package tests
+
+import (
+	"hw8/models"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestMemoryPostStore(t *testing.T) {
+	testPostStore(t, models.NewMemoryPostStore())
+}
+
+func TestBoltPostStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "blog.db")
+	store, err := models.NewBoltPostStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testPostStore(t, store)
+
+	post := &models.BlogPost{Title: "Persisted"}
+	if err := store.CreatePost(post); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	store, err = models.NewBoltPostStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	stored, err := store.GetPost(post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Title != "Persisted" {
+		t.Error("Should be Persisted title")
+	}
+}
+
+func testPostStore(t *testing.T, store models.PostStore) {
+	post := &models.BlogPost{Title: "Title1", Content: "Content1"}
+	if err := store.CreatePost(post); err != nil {
+		t.Fatal(err)
+	}
+	if post.ID.IsZero() {
+		t.Fatal("Should set post id")
+	}
+
+	post.Title = "Title2"
+	if err := store.UpdatePost(post); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := store.GetPost(post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Title != "Title2" {
+		t.Error("Should be Title2 title")
+	}
+	if stored.Version != 1 || post.Version != 1 {
+		t.Error("Should be version 1")
+	}
+
+	stale := *stored
+	stale.Version = 0
+	stale.Title = "Stale"
+	if err := store.UpdatePost(&stale); err != models.ErrVersionConflict {
+		t.Error("Should be ErrVersionConflict")
+	}
+	if stored, _ := store.GetPost(post.ID); stored.Title != "Title2" {
+		t.Error("Stale update should not change post")
+	}
+
+	posts, err := store.ListPosts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 1 {
+		t.Error("Should be 1 post")
+	}
+
+	if err := store.DeletePost(post.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetPost(post.ID); err != models.ErrPostNotFound {
+		t.Error("Should be ErrPostNotFound")
+	}
+	if posts, _ := store.ListPosts(); len(posts) != 0 {
+		t.Error("Deleted post should not be listed")
+	}
+
+	trash, err := store.ListTrash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trash) != 1 || !trash[0].IsDeleted() {
+		t.Fatal("Should be 1 post in trash")
+	}
+
+	if err := store.RestorePost(post.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetPost(post.ID); err != nil {
+		t.Error("Restored post should be visible")
+	}
+
+	if err := store.PurgePost(post.ID); err != models.ErrPostNotFound {
+		t.Error("Should not purge post that is not in trash")
+	}
+	store.DeletePost(post.ID)
+	if n, _ := store.PurgeTrash(time.Now().Add(-time.Hour)); n != 0 {
+		t.Error("Should not purge recently deleted post")
+	}
+	if n, _ := store.PurgeTrash(time.Now().Add(time.Second)); n != 1 {
+		t.Error("Should purge 1 post")
+	}
+	if trash, _ := store.ListTrash(); len(trash) != 0 {
+		t.Error("Trash should be empty")
+	}
+	if err := store.UpdatePost(&models.BlogPost{ID: primitive.NewObjectID()}); err != models.ErrPostNotFound {
+		t.Error("Should be ErrPostNotFound")
+	}
+}
@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"hw8/diff"
+	"hw8/models"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	lines := diff.Lines("a\nb\nc\n", "a\nc\nd\n")
+	expected := []diff.Line{
+		{Op: diff.Equal, Text: "a"},
+		{Op: diff.Delete, Text: "b"},
+		{Op: diff.Equal, Text: "c"},
+		{Op: diff.Insert, Text: "d"},
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("Should be %v lines, got %v", len(expected), lines)
+	}
+	for i := range expected {
+		if lines[i] != expected[i] {
+			t.Errorf("Line %v should be %v, got %v", i, expected[i], lines[i])
+		}
+	}
+}
+
+func TestMemoryRevisions(t *testing.T) {
+	testRevisions(t, models.NewMemoryPostStore())
+}
+
+func TestBoltRevisions(t *testing.T) {
+	store, err := models.NewBoltPostStore(filepath.Join(t.TempDir(), "blog.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	testRevisions(t, store)
+}
+
+func testRevisions(t *testing.T, store models.Store) {
+	post := &models.BlogPost{Title: "Title1", Content: "Content1"}
+	if err := models.CreatePostWithRevision(store, post, "alice"); err != nil {
+		t.Fatal(err)
+	}
+
+	post.Content = "Content2"
+	rev, err := models.UpdatePostWithRevision(store, post, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev.Number != 2 {
+		t.Errorf("Should be revision 2, got %v", rev.Number)
+	}
+
+	rev, err = models.RestoreRevision(store, post.ID, 1, "carol")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev.Number != 3 || rev.RestoredFrom != 1 {
+		t.Errorf("Should be revision 3 restored from 1, got %+v", rev)
+	}
+
+	stored, _ := store.GetPost(post.ID)
+	if stored.Content != "Content1" {
+		t.Error("Should restore Content1")
+	}
+
+	revs, err := store.ListRevisions(post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != 3 || revs[0].Number != 3 || revs[2].Editor != "alice" {
+		t.Errorf("Should list 3 revisions newest first, got %+v", revs)
+	}
+
+	if _, err := store.GetRevision(post.ID, 4); err != models.ErrRevisionNotFound {
+		t.Error("Should be ErrRevisionNotFound")
+	}
+
+	store.DeletePost(post.ID)
+	store.PurgePost(post.ID)
+	if revs, _ := store.ListRevisions(post.ID); len(revs) != 0 {
+		t.Error("Purged post should have no revisions")
+	}
+}
+
+func TestHistoryEditor(t *testing.T) {
+	w := serve("POST", "/new", nil)
+	m := postIDField.FindStringSubmatch(w.Body.String())
+	if m == nil {
+		t.Fatal("Should render new post id")
+	}
+	form := url.Values{"id": {m[1]}, "version": {"0"}, "title": {"Edited"}}
+	if w = serve("POST", "/edit", form); w.Code != http.StatusOK {
+		t.Fatalf("Edit should succeed, got %v", w.Code)
+	}
+
+	w = serve("GET", "/post/history?id="+m[1], nil)
+	if !strings.Contains(w.Body.String(), "<td>tester</td>") {
+		t.Errorf("History should name logged in editor, got %v", w.Body.String())
+	}
+
+	restore := url.Values{"id": {m[1]}, "revision": {"9"}}
+	if w = serve("POST", "/post/history/restore", restore); w.Code != http.StatusNotFound {
+		t.Errorf("Missing revision should be 404, got %v", w.Code)
+	}
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"hw8/models"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUserRoles(t *testing.T) {
+	author := &models.User{ID: primitive.NewObjectID(), Role: models.RoleAuthor}
+	editor := &models.User{ID: primitive.NewObjectID(), Role: models.RoleEditor}
+	admin := &models.User{ID: primitive.NewObjectID(), Role: models.RoleAdmin}
+
+	if author.HasRole(models.RoleEditor) || !editor.HasRole(models.RoleEditor) || !admin.HasRole(models.RoleEditor) {
+		t.Error("Editor role should be held by editors and admins only")
+	}
+	var anonymous *models.User
+	if anonymous.HasRole(models.RoleAuthor) {
+		t.Error("Anonymous user should have no role")
+	}
+
+	own := &models.BlogPost{AuthorID: author.ID}
+	other := &models.BlogPost{AuthorID: editor.ID}
+	if !author.CanEditPost(own) || author.CanEditPost(other) {
+		t.Error("Author should edit only own posts")
+	}
+	if !editor.CanEditPost(own) {
+		t.Error("Editor should edit any post")
+	}
+}
+
+func TestChangeRole(t *testing.T) {
+	store := models.NewMemoryPostStore()
+	user, err := models.Register(store, "worker", "worker password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.Role != models.RoleAuthor {
+		t.Fatalf("Signed up users should be authors, got %v", user.Role)
+	}
+	admin, err := models.EnsureAdmin(store, "boss", "boss password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if admin.Role != models.RoleAdmin {
+		t.Fatalf("Configured admin should be admin, got %v", admin.Role)
+	}
+	if again, err := models.EnsureAdmin(store, "boss", "other password"); err != nil || again.ID != admin.ID {
+		t.Errorf("Existing admin should be kept, got %v %v", again, err)
+	}
+	if _, err := models.EnsureAdmin(store, "worker", "worker password"); err == nil {
+		t.Error("Signed up user name should not be made admin")
+	}
+
+	if err = models.ChangeRole(store, user, admin.ID, models.RoleAuthor); err != models.ErrForbidden {
+		t.Error("Author should not change roles")
+	}
+	if err = models.ChangeRole(store, admin, admin.ID, models.RoleAuthor); err != models.ErrForbidden {
+		t.Error("Admin should not change own role")
+	}
+	if err = models.ChangeRole(store, admin, user.ID, "root"); err != models.ErrBadRole {
+		t.Error("Should be ErrBadRole")
+	}
+	if err = models.ChangeRole(store, admin, primitive.NewObjectID(), models.RoleEditor); err != models.ErrUserNotFound {
+		t.Error("Should be ErrUserNotFound")
+	}
+	if err = models.ChangeRole(store, admin, user.ID, models.RoleEditor); err != nil {
+		t.Fatal(err)
+	}
+	if user, _ = store.GetUser(user.ID); user.Role != models.RoleEditor {
+		t.Errorf("Role should be editor, got %v", user.Role)
+	}
+}
+
+func TestAuthorAccess(t *testing.T) {
+	author := register("author", "author password")
+	serveAs := func(method, path string, form url.Values) int {
+		return serveRequest(addSession(newFormRequest(method, path, form), author)).Code
+	}
+
+	m := postIDField.FindStringSubmatch(serve("POST", "/new", nil).Body.String())
+	if m == nil {
+		t.Fatal("Should render new post id")
+	}
+	if code := serveAs("GET", "/edit?id="+m[1], nil); code != http.StatusForbidden {
+		t.Errorf("Author should not edit other posts, got %v", code)
+	}
+	if code := serveAs("POST", "/delete", url.Values{"id": {m[1]}}); code != http.StatusForbidden {
+		t.Errorf("Author should not delete other posts, got %v", code)
+	}
+	for _, id := range []string{"nope", "5e4f0a0a0a0a0a0a0a0a0a0a"} {
+		if code := serveAs("POST", "/delete", url.Values{"id": {id}}); code != http.StatusForbidden {
+			t.Errorf("Author should be denied post %v that is not theirs, got %v", id, code)
+		}
+	}
+	if code := serve("GET", "/edit?id=5e4f0a0a0a0a0a0a0a0a0a0a", nil).Code; code != http.StatusNotFound {
+		t.Errorf("Editor should get 404 for missing post, got %v", code)
+	}
+	if code := serveAs("GET", "/trash", nil); code != http.StatusForbidden {
+		t.Errorf("Author should not see trash, got %v", code)
+	}
+	if code := serveAs("GET", "/admin/users", nil); code != http.StatusForbidden {
+		t.Errorf("Author should not manage users, got %v", code)
+	}
+
+	r := addSession(newFormRequest("POST", "/new", nil), author)
+	m = postIDField.FindStringSubmatch(serveRequest(r).Body.String())
+	if m == nil {
+		t.Fatal("Author should create posts")
+	}
+	if code := serveAs("GET", "/edit?id="+m[1], nil); code != http.StatusOK {
+		t.Errorf("Author should edit own post, got %v", code)
+	}
+
+	w := serve("GET", "/admin/users", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "author") {
+		t.Errorf("Admin should see users, got %v", w.Code)
+	}
+}
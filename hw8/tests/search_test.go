@@ -0,0 +1,99 @@
+package tests
+
+import (
+	"hw8/models"
+	"hw8/search"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestStem(t *testing.T) {
+	words := map[string]string{
+		"running":          "run",
+		"connections":      "connect",
+		"generalization":   "gener",
+		"ponies":           "poni",
+		"go":               "go",
+		"красивая":         "красив",
+		"книгами":          "книг",
+		"программирование": "программирован",
+		"вечерний":         "вечерн",
+		"ёлки":             "елк",
+	}
+	for word, stem := range words {
+		if s := search.Stem(word); s != stem {
+			t.Errorf("Stem of %v should be %v, got %v", word, stem, s)
+		}
+	}
+}
+
+func TestIndexSearch(t *testing.T) {
+	idx := search.NewIndex()
+	posts := []models.BlogPost{
+		{Title: "Cooking pasta", Content: "Boil water and cook the pasta."},
+		{Title: "Gardening", Content: "Tomatoes need water. Cooking them is another story."},
+		{Title: "Программирование на Go", Content: "Пишем веб сервер на языке Go."},
+	}
+	for i := range posts {
+		posts[i].ID = [12]byte{byte(i + 1)}
+		idx.Add(posts[i])
+	}
+
+	hits := idx.Search("cooked", 10)
+	if len(hits) != 2 || hits[0].Title != "Cooking pasta" {
+		t.Fatalf("Title match should rank first, got %v hits", len(hits))
+	}
+	if !strings.Contains(string(hits[1].Snippet), "<mark>Cooking</mark>") {
+		t.Errorf("Snippet should highlight match, got %v", hits[1].Snippet)
+	}
+
+	if hits := idx.Search("программированию", 10); len(hits) != 1 {
+		t.Error("Should find russian post by other word form")
+	}
+
+	posts[0].Title = "Baking bread"
+	posts[0].Content = "Knead dough."
+	idx.Add(posts[0])
+	if hits := idx.Search("pasta", 10); len(hits) != 0 {
+		t.Error("Updated post should not match old content")
+	}
+
+	idx.Remove(posts[1].ID)
+	if hits := idx.Search("tomato", 10); len(hits) != 0 {
+		t.Error("Removed post should not match")
+	}
+}
+
+func TestSnippetEscapes(t *testing.T) {
+	terms := map[string]bool{search.Stem("script"): true}
+	snippet := string(search.Snippet("<b>script</b> & more", terms, 30))
+	if snippet != "&lt;b&gt;<mark>script</mark>&lt;/b&gt; &amp; more" {
+		t.Errorf("Snippet should escape text, got %v", snippet)
+	}
+}
+
+func TestSearchPage(t *testing.T) {
+	w := serve("POST", "/new", nil)
+	m := postIDField.FindStringSubmatch(w.Body.String())
+	if m == nil {
+		t.Fatal("Should render new post id")
+	}
+
+	form := url.Values{"id": {m[1]}, "version": {"0"}, "title": {"Searchable"}, "content": {"Unusual zeppelins"},
+		"status": {"published"}}
+	if w = serve("POST", "/edit", form); w.Code != http.StatusOK {
+		t.Fatalf("Edit should succeed, got %v", w.Code)
+	}
+
+	w = serve("GET", "/search?q=zeppelin", nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "<mark>zeppelins</mark>") {
+		t.Fatal("Search should find edited post")
+	}
+
+	serve("POST", "/delete", url.Values{"id": {m[1]}})
+	if w = serve("GET", "/search?q=zeppelin", nil); strings.Contains(w.Body.String(), m[1]) {
+		t.Error("Deleted post should not be found")
+	}
+}
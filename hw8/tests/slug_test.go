@@ -0,0 +1,154 @@
+package tests
+
+import (
+	"hw8/models"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// permalink gets post address the old id link redirects to
+func permalink(t *testing.T, id string) string {
+	w := serve("GET", "/post?id="+id, nil)
+	if w.Code != http.StatusMovedPermanently {
+		t.Fatalf("Id link should redirect to permalink, got %v", w.Code)
+	}
+	return w.Header().Get("Location")
+}
+
+func TestSlugify(t *testing.T) {
+	cases := []struct{ title, want string }{
+		{"Hello, World!", "hello-world"},
+		{"Привет, мир", "privet-mir"},
+		{"Щука и ёж", "shchuka-i-yozh"},
+		{"Go 1.14 released", "go-1-14-released"},
+		{"  --  ", "post"},
+		{"日本", "post"},
+		{strings.Repeat("word ", 20), strings.TrimSuffix(strings.Repeat("word-", 12), "-")},
+	}
+	for _, c := range cases {
+		if got := models.Slugify(c.title); got != c.want {
+			t.Errorf("Slugify(%q) = %q, want %q", c.title, got, c.want)
+		}
+	}
+}
+
+func TestMemorySlugs(t *testing.T) {
+	testSlugs(t, models.NewMemoryPostStore())
+}
+
+func TestBoltSlugs(t *testing.T) {
+	store, err := models.NewBoltPostStore(filepath.Join(t.TempDir(), "blog.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	testSlugs(t, store)
+}
+
+func testSlugs(t *testing.T, store models.PostStore) {
+	first := &models.BlogPost{Title: "Hello"}
+	second := &models.BlogPost{Title: "Hello!"}
+	for _, p := range []*models.BlogPost{first, second} {
+		if err := store.CreatePost(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if first.Slug != "hello" || second.Slug != "hello-2" {
+		t.Fatalf("Taken slug should get suffix, got %q %q", first.Slug, second.Slug)
+	}
+	if p, err := store.GetPostBySlug("hello-2"); err != nil || p.ID != second.ID {
+		t.Errorf("Should find post by slug, got %v", err)
+	}
+
+	second.Slug = "hello"
+	if err := store.UpdatePost(second); err != nil || second.Slug != "hello-2" {
+		t.Errorf("Update should keep slug unique, got %q %v", second.Slug, err)
+	}
+	first.Slug = ""
+	first.Title = "Renamed"
+	if err := store.UpdatePost(first); err != nil || first.Slug != "hello" {
+		t.Errorf("Update without slug should keep stored one, got %q %v", first.Slug, err)
+	}
+
+	store.DeletePost(first.ID)
+	if _, err := store.GetPostBySlug("hello"); err != models.ErrPostNotFound {
+		t.Error("Deleted post should not be found by slug")
+	}
+	third := &models.BlogPost{Title: "Hello"}
+	if store.CreatePost(third); third.Slug != "hello-3" {
+		t.Errorf("Slug of post in trash should stay taken, got %q", third.Slug)
+	}
+	if err := store.PurgePost(first.ID); err != nil {
+		t.Fatal(err)
+	}
+	fourth := &models.BlogPost{Title: "Hello"}
+	if store.CreatePost(fourth); fourth.Slug != "hello" {
+		t.Errorf("Purged post should free slug, got %q", fourth.Slug)
+	}
+
+	// more posts of one title than numbered suffixes are tried
+	slugs := map[string]bool{}
+	for i := 0; i < 120; i++ {
+		post := &models.BlogPost{Title: "Untitled"}
+		if err := store.CreatePost(post); err != nil {
+			t.Fatalf("Post %v of the same title should be created, got %v", i, err)
+		}
+		if slugs[post.Slug] || !strings.HasPrefix(post.Slug, "untitled") {
+			t.Fatalf("Slug should be unique, got %q", post.Slug)
+		}
+		slugs[post.Slug] = true
+	}
+}
+
+func TestPermalinks(t *testing.T) {
+	m := postIDField.FindStringSubmatch(serve("POST", "/new", nil).Body.String())
+	if m == nil {
+		t.Fatal("Should render new post id")
+	}
+	form := url.Values{"id": {m[1]}, "version": {"0"}, "title": {"Черновик"}, "date": {"2020-02-21"}}
+	if w := serve("POST", "/edit", form); w.Code != http.StatusOK {
+		t.Fatalf("Edit should succeed, got %v", w.Code)
+	}
+	if link := permalink(t, m[1]); link != "/posts/2020/02/chernovik" {
+		t.Errorf("Draft slug should follow title, got %v", link)
+	}
+
+	form = url.Values{"id": {m[1]}, "version": {"1"}, "title": {"Привет, мир"}, "date": {"2020-02-21"}, "status": {"published"}}
+	if w := serve("POST", "/edit", form); w.Code != http.StatusOK {
+		t.Fatalf("Publish should succeed, got %v", w.Code)
+	}
+	link := permalink(t, m[1])
+	if link != "/posts/2020/02/privet-mir" {
+		t.Fatalf("Wrong permalink %v", link)
+	}
+	if w := serve("GET", link, nil); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Привет, мир") {
+		t.Errorf("Permalink should show post, got %v", w.Code)
+	}
+	r, _ := http.NewRequest("GET", "/post/?id=ObjectID(%22"+m[1]+"%22)", nil)
+	if w := serveRequest(r); w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != link {
+		t.Errorf("Old id link should redirect to permalink, got %v", w.Code)
+	}
+	if w := serve("GET", "/posts/1999/01/privet-mir", nil); w.Code != http.StatusMovedPermanently ||
+		w.Header().Get("Location") != link {
+		t.Errorf("Link with other month should redirect, got %v", w.Code)
+	}
+	if w := serve("GET", "/", nil); !strings.Contains(w.Body.String(), `href="`+link+`"`) {
+		t.Error("Index should link permalink")
+	}
+
+	form = url.Values{"id": {m[1]}, "version": {"2"}, "title": {"Renamed"}, "date": {time.Now().Format(models.DateLayout)}}
+	if w := serve("POST", "/edit", form); w.Code != http.StatusOK {
+		t.Fatalf("Edit should succeed, got %v", w.Code)
+	}
+	if w := serve("GET", link, nil); w.Code != http.StatusMovedPermanently ||
+		!strings.HasSuffix(w.Header().Get("Location"), "/privet-mir") {
+		t.Errorf("Published post should keep slug, got %v %v", w.Code, w.Header().Get("Location"))
+	}
+	if w := serve("GET", "/posts/2020/02/no-such-post", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Unknown slug should be 404, got %v", w.Code)
+	}
+}
@@ -0,0 +1,150 @@
+package tests
+
+import (
+	"hw8/models"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSetStatus(t *testing.T) {
+	post := &models.BlogPost{}
+	if !post.IsPublished() {
+		t.Error("Post without status should be published")
+	}
+	if err := post.SetStatus("hidden", nil); err != models.ErrBadStatus {
+		t.Error("Should be ErrBadStatus")
+	}
+	if err := post.SetStatus(models.StatusScheduled, nil); err != models.ErrNoPublishTime {
+		t.Error("Should be ErrNoPublishTime")
+	}
+	if err := post.SetStatus(models.StatusPublished, nil); err != nil || post.PublishAt == nil {
+		t.Error("Published post should get publish time")
+	}
+	if err := post.SetStatus(models.StatusDraft, nil); err != nil || post.PublishAt != nil || post.IsPublished() {
+		t.Error("Draft should not be published")
+	}
+}
+
+func TestMemoryPostStatus(t *testing.T) {
+	testPostStatus(t, models.NewMemoryPostStore())
+}
+
+func TestBoltPostStatus(t *testing.T) {
+	store, err := models.NewBoltPostStore(filepath.Join(t.TempDir(), "blog.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	testPostStatus(t, store)
+}
+
+func testPostStatus(t *testing.T, store models.PostStore) {
+	author := primitive.NewObjectID()
+	soon := time.Now().Add(time.Hour)
+	posts := []*models.BlogPost{
+		{Title: "Public", Tags: []string{"shared"}},
+		{Title: "Draft", Tags: []string{"shared", "secret"}, Category: "news", AuthorID: author, Status: models.StatusDraft},
+		{Title: "Later", AuthorID: author, Status: models.StatusScheduled, PublishAt: &soon},
+	}
+	for _, p := range posts {
+		if err := store.CreatePost(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	page, _ := store.ListPostsPage(models.PostFilter{Status: models.StatusPublished}, models.Cursor{}, 10)
+	if len(page) != 1 || page[0].Title != "Public" {
+		t.Errorf("Page should list only published posts, got %v", len(page))
+	}
+	if byTag, _ := store.ListPostsByTag("shared"); len(byTag) != 1 {
+		t.Errorf("Tag list should skip drafts, got %v", len(byTag))
+	}
+	if byCategory, _ := store.ListPostsByCategory("news"); len(byCategory) != 0 {
+		t.Errorf("Category list should skip drafts, got %v", len(byCategory))
+	}
+	if tags, _ := store.ListTags(); len(tags) != 1 || tags[0].Count != 1 {
+		t.Errorf("Tags should count only published posts, got %v", tags)
+	}
+
+	drafts, _ := store.ListDrafts(author)
+	if len(drafts) != 2 || drafts[0].Title != "Later" {
+		t.Errorf("Drafts should list unpublished posts of author newest first, got %v", len(drafts))
+	}
+	if other, _ := store.ListDrafts(primitive.NewObjectID()); len(other) != 0 {
+		t.Error("Drafts of other author should be empty")
+	}
+
+	if due, _ := store.PublishDue(time.Now()); len(due) != 0 {
+		t.Errorf("Nothing should be due yet, got %v", len(due))
+	}
+	due, err := store.PublishDue(soon)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 1 || due[0].Title != "Later" || due[0].Status != models.StatusPublished {
+		t.Fatalf("Scheduled post should be published, got %v", due)
+	}
+	if page, _ = store.ListPostsPage(models.PostFilter{Status: models.StatusPublished}, models.Cursor{}, 10); len(page) != 2 || page[0].Title != "Later" {
+		t.Error("Published post should appear on page")
+	}
+	if stored, _ := store.GetPost(posts[2].ID); stored.Version != posts[2].Version+1 {
+		t.Error("Publishing should change post version")
+	}
+}
+
+func TestDraftPages(t *testing.T) {
+	w := serve("POST", "/new", nil)
+	m := postIDField.FindStringSubmatch(w.Body.String())
+	if m == nil {
+		t.Fatal("Should render new post id")
+	}
+
+	r, _ := http.NewRequest("GET", "/post?id="+m[1], nil)
+	if w = serveRequest(r); w.Code != http.StatusNotFound {
+		t.Errorf("Draft should be hidden from readers, got %v", w.Code)
+	}
+	r, _ = http.NewRequest("GET", "/post/history?id="+m[1], nil)
+	if w = serveRequest(r); w.Code != http.StatusNotFound {
+		t.Errorf("Draft history should be hidden from readers, got %v", w.Code)
+	}
+	if w = serve("GET", "/post/history?id="+m[1], nil); w.Code != http.StatusOK {
+		t.Errorf("Draft history should be shown to its editor, got %v", w.Code)
+	}
+	if w = serve("GET", "/", nil); strings.Contains(w.Body.String(), m[1]) {
+		t.Error("Index should not list drafts")
+	}
+	if w = serve("GET", "/drafts", nil); !strings.Contains(w.Body.String(), m[1]) {
+		t.Error("My drafts should list new post")
+	}
+
+	form := url.Values{"id": {m[1]}, "version": {"0"}, "title": {"Scheduled"}, "status": {"scheduled"}}
+	if w = serve("POST", "/edit", form); w.Code != http.StatusBadRequest {
+		t.Errorf("Scheduled post without time should be 400, got %v", w.Code)
+	}
+	form.Set("publish_at", "tomorrow")
+	if w = serve("POST", "/edit", form); w.Code != http.StatusBadRequest {
+		t.Errorf("Bad publish time should be 400, got %v", w.Code)
+	}
+	form.Set("publish_at", time.Now().Add(time.Hour).Format("2006-01-02T15:04"))
+	if w = serve("POST", "/edit", form); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "publishes at") {
+		t.Fatalf("Post should be scheduled, got %v", w.Code)
+	}
+
+	form = url.Values{"id": {m[1]}, "version": {"1"}, "title": {"Published"}, "status": {"published"}}
+	if w = serve("POST", "/edit", form); w.Code != http.StatusOK {
+		t.Fatalf("Post should be published, got %v", w.Code)
+	}
+	r, _ = http.NewRequest("GET", permalink(t, m[1]), nil)
+	if w = serveRequest(r); w.Code != http.StatusOK {
+		t.Errorf("Published post should be public, got %v", w.Code)
+	}
+	if w = serve("GET", "/drafts", nil); strings.Contains(w.Body.String(), m[1]) {
+		t.Error("My drafts should not list published post")
+	}
+}
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"encoding/json"
+	"hw8/models"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTags(t *testing.T) {
+	tags := models.ParseTags(" Go, web  dev,go,, Веб ")
+	if !reflect.DeepEqual(tags, []string{"go", "web-dev", "веб"}) {
+		t.Errorf("Wrong tags %v", tags)
+	}
+}
+
+func TestTagCloud(t *testing.T) {
+	tags := models.TagCloud([]models.TagCount{{Name: "a", Count: 1}, {Name: "b", Count: 10}, {Name: "c", Count: 100}})
+	if tags[0].Weight != 1 || tags[1].Weight != 3 || tags[2].Weight != 5 {
+		t.Errorf("Wrong weights %v", tags)
+	}
+}
+
+func TestMemoryTaxonomy(t *testing.T) {
+	testTaxonomy(t, models.NewMemoryPostStore())
+}
+
+func TestBoltTaxonomy(t *testing.T) {
+	store, err := models.NewBoltPostStore(filepath.Join(t.TempDir(), "blog.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	testTaxonomy(t, store)
+}
+
+func testTaxonomy(t *testing.T, store models.PostStore) {
+	posts := []*models.BlogPost{
+		{Title: "1", Tags: []string{"go", "web"}, Category: "code"},
+		{Title: "2", Tags: []string{"go"}, Category: "code"},
+		{Title: "3", Tags: []string{"life"}, Category: "notes"},
+	}
+	for _, p := range posts {
+		if err := store.CreatePost(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tagged, err := store.ListPostsByTag("go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tagged) != 2 || tagged[0].Title != "2" {
+		t.Errorf("Should be 2 go posts newest first, got %v", len(tagged))
+	}
+
+	inCategory, err := store.ListPostsByCategory("notes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inCategory) != 1 || inCategory[0].Title != "3" {
+		t.Error("Should be 1 notes post")
+	}
+
+	posts[1].Tags = []string{"web"}
+	if err := store.UpdatePost(posts[1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DeletePost(posts[2].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	tags, err := store.ListTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.TagCount{{Name: "go", Count: 1}, {Name: "web", Count: 2}}
+	if !reflect.DeepEqual(tags, want) {
+		t.Errorf("Wrong tag counts %v", tags)
+	}
+}
+
+func TestTagPages(t *testing.T) {
+	w := serve("POST", "/new", nil)
+	m := postIDField.FindStringSubmatch(w.Body.String())
+	if m == nil {
+		t.Fatal("Should render new post id")
+	}
+
+	form := url.Values{"id": {m[1]}, "version": {"0"}, "title": {"Tagged"},
+		"tags": {"Pagetag, Other Tag"}, "category": {"Pagecat"}, "status": {"published"}}
+	if w = serve("POST", "/edit", form); w.Code != http.StatusOK {
+		t.Fatalf("Edit should succeed, got %v", w.Code)
+	}
+
+	if w = serve("GET", "/tag/pagetag", nil); !strings.Contains(w.Body.String(), m[1]) {
+		t.Error("Tag page should list post")
+	}
+	if w = serve("GET", "/category/pagecat", nil); !strings.Contains(w.Body.String(), m[1]) {
+		t.Error("Category page should list post")
+	}
+	if w = serve("GET", "/", nil); !strings.Contains(w.Body.String(), `href="/tag/other-tag"`) {
+		t.Error("Index should show tag cloud")
+	}
+	if w = serve("GET", "/edit?id="+m[1], nil); !strings.Contains(w.Body.String(), `value="other-tag, pagetag"`) {
+		t.Error("Edit form should contain tags")
+	}
+
+	w = serve("GET", "/tags?q=page", nil)
+	names := []string{}
+	if err := json.Unmarshal(w.Body.Bytes(), &names); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"pagetag"}) {
+		t.Errorf("Wrong suggestions %v", names)
+	}
+}
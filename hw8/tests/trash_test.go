@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"hw8/models"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/astaxie/beego"
+)
+
+var postIDField = regexp.MustCompile(`name="id" value="([0-9a-f]{24})"`)
+
+// serve sends request as logged in test user, posted forms carry CSRF token
+func serve(method, path string, form url.Values) *httptest.ResponseRecorder {
+	session := testSession()
+	if method == "POST" {
+		if form == nil {
+			form = url.Values{}
+		}
+		form.Set("csrf_token", models.CSRFToken(session.Value))
+	}
+	r := newFormRequest(method, path, form)
+	r.AddCookie(session)
+	return serveRequest(r)
+}
+
+// addSession makes request come from session owner page
+func addSession(r *http.Request, session *http.Cookie) *http.Request {
+	r.AddCookie(session)
+	r.Header.Set("X-CSRF-Token", models.CSRFToken(session.Value))
+	return r
+}
+
+// testPreSession is pre-session cookie of anonymous test visitor
+var testPreSession = &http.Cookie{Name: "presession", Value: "test pre-session"}
+
+// addPreSession makes anonymous request come from a page of the site
+func addPreSession(r *http.Request) *http.Request {
+	r.AddCookie(testPreSession)
+	r.Header.Set("X-CSRF-Token", models.CSRFToken(testPreSession.Value))
+	return r
+}
+
+func newFormRequest(method, path string, form url.Values) *http.Request {
+	r, _ := http.NewRequest(method, path, strings.NewReader(form.Encode()))
+	if form != nil {
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	return r
+}
+
+func serveRequest(r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	beego.BeeApp.Handlers.ServeHTTP(w, r)
+	return w
+}
+
+func TestTrash(t *testing.T) {
+	w := serve("POST", "/new", nil)
+	m := postIDField.FindStringSubmatch(w.Body.String())
+	if m == nil {
+		t.Fatal("Should render new post id")
+	}
+	form := url.Values{"id": {m[1]}}
+
+	if w = serve("POST", "/delete", form); w.Code != http.StatusFound {
+		t.Fatalf("Delete should redirect, got %v", w.Code)
+	}
+	if w = serve("GET", "/post?id="+m[1], nil); w.Code != http.StatusNotFound {
+		t.Errorf("Deleted post should be 404, got %v", w.Code)
+	}
+	if w = serve("GET", "/trash", nil); !strings.Contains(w.Body.String(), m[1]) {
+		t.Error("Trash should show deleted post")
+	}
+
+	if w = serve("POST", "/trash/restore", form); w.Code != http.StatusFound {
+		t.Fatalf("Restore should redirect, got %v", w.Code)
+	}
+	if w = serve("GET", permalink(t, m[1]), nil); w.Code != http.StatusOK {
+		t.Errorf("Restored post should be 200, got %v", w.Code)
+	}
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestEditConflict(t *testing.T) {
+	w := serve("POST", "/new", nil)
+	m := postIDField.FindStringSubmatch(w.Body.String())
+	if m == nil {
+		t.Fatal("Should render new post id")
+	}
+
+	if w = serve("GET", "/edit?id="+m[1], nil); !strings.Contains(w.Body.String(), `name="version" value="0"`) {
+		t.Fatal("Edit form should contain post version")
+	}
+
+	form := url.Values{"id": {m[1]}, "version": {"0"}, "title": {"First"}}
+	if w = serve("POST", "/edit", form); w.Code != http.StatusOK {
+		t.Fatalf("First edit should succeed, got %v", w.Code)
+	}
+	if etag := w.Header().Get("ETag"); etag != `"1"` {
+		t.Errorf("Should send new version ETag, got %v", etag)
+	}
+
+	form.Set("title", "Second")
+	w = serve("POST", "/edit", form)
+	if w.Code != http.StatusConflict {
+		t.Fatalf("Stale edit should be 409, got %v", w.Code)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "Second") || !strings.Contains(body, "First") {
+		t.Error("Conflict page should show both versions")
+	}
+	if !strings.Contains(body, `name="version" value="1"`) {
+		t.Error("Conflict form should carry current version")
+	}
+
+	form.Del("version")
+	if w = serve("POST", "/edit", form); w.Code != http.StatusPreconditionRequired {
+		t.Errorf("Edit without version should be 428, got %v", w.Code)
+	}
+}
+
+func TestEditIfMatch(t *testing.T) {
+	w := serve("POST", "/new", nil)
+	m := postIDField.FindStringSubmatch(w.Body.String())
+	if m == nil {
+		t.Fatal("Should render new post id")
+	}
+
+	w = serve("GET", "/edit?id="+m[1], nil)
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("Edit page should send ETag")
+	}
+
+	// post pages carry CSRF token and user, so they are not revalidated
+	r, _ := http.NewRequest("GET", permalink(t, m[1]), nil)
+	r.Header.Set("If-None-Match", etag)
+	if w = serveRequest(addSession(r, testSession())); w.Code != http.StatusOK || w.Header().Get("ETag") != "" {
+		t.Errorf("Post page should be sent without ETag, got %v %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	edit := func(title string) int {
+		form := url.Values{"id": {m[1]}, "title": {title}}
+		r, _ := http.NewRequest("POST", "/edit", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.Header.Set("If-Match", etag)
+		addSession(r, testSession())
+		return serveRequest(r).Code
+	}
+	if code := edit("First"); code != http.StatusOK {
+		t.Fatalf("Edit with current ETag should succeed, got %v", code)
+	}
+	if code := edit("Second"); code != http.StatusPreconditionFailed {
+		t.Errorf("Edit with stale ETag should be 412, got %v", code)
+	}
+	etag = "*"
+	if code := edit("Any version"); code != http.StatusOK {
+		t.Errorf("Edit with If-Match: * should update current version, got %v", code)
+	}
+}
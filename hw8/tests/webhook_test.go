@@ -0,0 +1,273 @@
+package tests
+
+import (
+	"encoding/json"
+	"hw8/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// receiver records webhook requests and answers with status
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   []string
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, string(body))
+	w.WriteHeader(r.status)
+}
+
+// answer sets status of next answers
+func (r *receiver) answer(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	if models.WebhookBackoff(1) != time.Minute || models.WebhookBackoff(3) != 4*time.Minute {
+		t.Errorf("Backoff should double, got %v %v", models.WebhookBackoff(1), models.WebhookBackoff(3))
+	}
+	if models.WebhookBackoff(100) != 6*time.Hour {
+		t.Errorf("Backoff should be capped, got %v", models.WebhookBackoff(100))
+	}
+}
+
+func TestPostChangeEvent(t *testing.T) {
+	draft := &models.BlogPost{Status: models.StatusDraft}
+	scheduled := &models.BlogPost{Status: models.StatusScheduled}
+	published := &models.BlogPost{Status: models.StatusPublished}
+	for _, c := range []struct {
+		before, after *models.BlogPost
+		event         string
+	}{
+		{nil, draft, ""},
+		{nil, published, models.EventPostCreated},
+		{draft, scheduled, ""},
+		{scheduled, published, models.EventPostPublished},
+		{published, published, models.EventPostUpdated},
+		{published, draft, models.EventPostUnpublished},
+	} {
+		if event := models.PostChangeEvent(c.before, c.after); event != c.event {
+			t.Errorf("Change %+v to %v should be %q, got %q", c.before, c.after.Status, c.event, event)
+		}
+	}
+}
+
+func TestMemoryWebhooks(t *testing.T) {
+	testWebhooks(t, models.NewMemoryPostStore())
+}
+
+func TestBoltWebhooks(t *testing.T) {
+	store, err := models.NewBoltPostStore(filepath.Join(t.TempDir(), "blog.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	testWebhooks(t, store)
+}
+
+func testWebhooks(t *testing.T, store models.Store) {
+	rcv := &receiver{status: http.StatusOK}
+	server := httptest.NewServer(rcv)
+	defer server.Close()
+
+	if _, err := models.AddWebhook(store, "ftp://x", []string{models.EventPostCreated}); err != models.ErrBadWebhook {
+		t.Error("Non http url should be ErrBadWebhook")
+	}
+	if _, err := models.AddWebhook(store, server.URL, []string{"post.eaten"}); err != models.ErrBadWebhook {
+		t.Error("Unknown event should be ErrBadWebhook")
+	}
+	created, err := models.AddWebhook(store, server.URL, []string{models.EventPostCreated})
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, err := models.AddWebhook(store, server.URL+"/all", models.WebhookEvents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Secret == "" || created.Secret == all.Secret {
+		t.Error("Webhooks should get own secrets")
+	}
+
+	now := time.Now()
+	if err = models.QueueEvent(store, models.EventPostUpdated, map[string]string{"title": "Updated"}); err != nil {
+		t.Fatal(err)
+	}
+	if err = models.QueueEvent(store, models.EventPostCreated, map[string]string{"title": "Created"}); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := store.ListDeliveries(created.ID, 10); len(list) != 1 || list[0].Event != models.EventPostCreated {
+		t.Fatalf("Event filter should skip updates, got %v", list)
+	}
+	if list, _ := store.ListDeliveries(all.ID, 10); len(list) != 2 || list[0].Event != models.EventPostCreated {
+		t.Fatalf("Deliveries should be newest first, got %v", list)
+	}
+
+	if n, err := models.DeliverDue(store, http.DefaultClient, now.Add(time.Second)); err != nil || n != 3 {
+		t.Fatalf("Should deliver 3, got %v %v", n, err)
+	}
+	if len(rcv.requests) != 3 {
+		t.Fatalf("Receiver should get 3 requests, got %v", len(rcv.requests))
+	}
+	req, body := rcv.requests[0], rcv.bodies[0]
+	if req.URL.Path != "/all" || req.Header.Get(models.WebhookEventHeader) != models.EventPostUpdated {
+		t.Errorf("Queue should be sent oldest first, got %v %v", req.URL.Path, req.Header.Get(models.WebhookEventHeader))
+	}
+	sentAt := now.Add(time.Second).Unix()
+	if req.Header.Get(models.WebhookTimestampHeader) != strconv.FormatInt(sentAt, 10) {
+		t.Errorf("Request should carry attempt time, got %v", req.Header.Get(models.WebhookTimestampHeader))
+	}
+	if req.Header.Get(models.WebhookSignatureHeader) != models.SignWebhook(all.Secret, sentAt, []byte(body)) ||
+		req.Header.Get(models.WebhookSignatureHeader) == models.SignWebhook(created.Secret, sentAt, []byte(body)) {
+		t.Error("Request should be signed with webhook secret")
+	}
+	if models.SignWebhook(all.Secret, sentAt+600, []byte(body)) == models.SignWebhook(all.Secret, sentAt, []byte(body)) {
+		t.Error("Signature should cover timestamp")
+	}
+	var payload struct {
+		Event string
+		Data  map[string]string
+	}
+	if err := json.Unmarshal([]byte(body), &payload); err != nil || payload.Event != models.EventPostUpdated ||
+		payload.Data["title"] != "Updated" {
+		t.Errorf("Wrong payload %v", body)
+	}
+	if n, _ := models.DeliverDue(store, http.DefaultClient, now.Add(time.Hour)); n != 0 || len(rcv.requests) != 3 {
+		t.Error("Delivered events should not be sent again")
+	}
+
+	rcv.answer(http.StatusServiceUnavailable)
+	models.QueueEvent(store, models.EventPostCreated, nil)
+	list, _ := store.ListDeliveries(created.ID, 1)
+	failing := list[0]
+	at := now.Add(time.Second)
+	for i := 1; i <= 8; i++ {
+		if n, err := models.DeliverDue(store, http.DefaultClient, at); err != nil || n != 0 {
+			t.Fatalf("Attempt %v should fail, got %v %v", i, n, err)
+		}
+		d, _ := store.GetDelivery(failing.ID)
+		if d.Attempts != i || d.ResponseStatus != http.StatusServiceUnavailable || d.LastError == "" {
+			t.Fatalf("Wrong failed delivery %+v", d)
+		}
+		if i == 8 {
+			if d.Status != models.DeliveryFailed {
+				t.Errorf("Delivery should fail after 8 attempts, got %v", d.Status)
+			}
+			break
+		}
+		if d.Status != models.DeliveryPending || !d.NextAttemptAt.After(at.Add(models.WebhookBackoff(i)-time.Second)) {
+			t.Fatalf("Failed delivery should wait %v, got %+v", models.WebhookBackoff(i), d)
+		}
+		if n, _ := models.DeliverDue(store, http.DefaultClient, d.NextAttemptAt.Add(-time.Second)); n != 0 {
+			t.Fatal("Delivery should not be retried early")
+		}
+		at = d.NextAttemptAt
+	}
+
+	rcv.answer(http.StatusNoContent)
+	again, err := models.Redeliver(store, failing.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := models.DeliverDue(store, http.DefaultClient, time.Now().Add(time.Second)); err != nil || n != 1 {
+		t.Fatalf("Only redelivery should be sent, got %v %v", n, err)
+	}
+	if d, _ := store.GetDelivery(again.ID); d.Status != models.DeliveryDone || d.DeliveredAt == nil || d.Payload != failing.Payload {
+		t.Errorf("Redelivery should be delivered with old payload, got %+v", d)
+	}
+	if d, _ := store.GetDelivery(failing.ID); d.Status != models.DeliveryFailed {
+		t.Error("Redelivered delivery should stay in log as it was")
+	}
+
+	if err = store.DeleteWebhook(created.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = store.GetDelivery(failing.ID); err != models.ErrDeliveryNotFound {
+		t.Error("Deleting webhook should remove its deliveries")
+	}
+	if hooks, _ := store.ListWebhooks(); len(hooks) != 1 || hooks[0].ID != all.ID {
+		t.Errorf("Wrong webhooks left %v", hooks)
+	}
+}
+
+var deliveryIDField = regexp.MustCompile(`name="id" value="([0-9a-f]{24})">\s*<button type="submit">Redeliver`)
+
+func TestWebhookPages(t *testing.T) {
+	author := register("hookauthor", "hookauthor password")
+	r := addSession(newFormRequest("GET", "/admin/webhooks", nil), author)
+	if code := serveRequest(r).Code; code != http.StatusForbidden {
+		t.Errorf("Author should not manage webhooks, got %v", code)
+	}
+
+	if w := serve("POST", "/admin/webhooks", url.Values{"url": {"http://x"}, "events": {"post.eaten"}}); w.Code != http.StatusBadRequest {
+		t.Errorf("Unknown event should be 400, got %v", w.Code)
+	}
+	events := []string{models.EventPostCreated, models.EventPostPublished, models.EventPostUpdated, models.EventPostDeleted}
+	w := serve("POST", "/admin/webhooks", url.Values{"url": {"http://127.0.0.1:1/hook"}, "events": events})
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("Add webhook should redirect, got %v %v", w.Code, w.Body.String())
+	}
+	w = serve("GET", "/admin/webhooks", nil)
+	m := regexp.MustCompile(`deliveries\?id=([0-9a-f]{24})">http://127.0.0.1:1/hook`).FindStringSubmatch(w.Body.String())
+	if w.Code != http.StatusOK || m == nil {
+		t.Fatalf("Admin should see webhook, got %v", w.Code)
+	}
+	log := "/admin/webhooks/deliveries?id=" + m[1]
+
+	post := postIDField.FindStringSubmatch(serve("POST", "/new", nil).Body.String())
+	if post == nil {
+		t.Fatal("Should render new post id")
+	}
+	if w = serve("GET", log, nil); deliveryIDField.MatchString(w.Body.String()) {
+		t.Fatalf("New draft should not be sent to webhook, got %v", w.Body.String())
+	}
+	form := url.Values{"id": {post[1]}, "version": {"0"}, "title": {"Hooked"}, "status": {"published"}}
+	if w = serve("POST", "/edit", form); w.Code != http.StatusOK {
+		t.Fatalf("Post should be published, got %v", w.Code)
+	}
+	w = serve("GET", log, nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), models.EventPostPublished) ||
+		strings.Contains(w.Body.String(), models.EventPostCreated) ||
+		!strings.Contains(w.Body.String(), "&#34;title&#34;:&#34;Hooked&#34;") {
+		t.Fatalf("Published post should be queued for webhook, got %v %v", w.Code, w.Body.String())
+	}
+	d := deliveryIDField.FindStringSubmatch(w.Body.String())
+	if d == nil {
+		t.Fatal("Delivery log should have redeliver form")
+	}
+	if w = serve("POST", "/admin/webhooks/redeliver", url.Values{"id": {d[1]}}); w.Code != http.StatusSeeOther ||
+		w.Header().Get("Location") != log {
+		t.Errorf("Redeliver should go back to log, got %v %v", w.Code, w.Header().Get("Location"))
+	}
+	if n := len(deliveryIDField.FindAllString(serve("GET", log, nil).Body.String(), -1)); n != 2 {
+		t.Errorf("Redelivery should be added to log, got %v deliveries", n)
+	}
+	serve("POST", "/delete", url.Values{"id": {post[1]}})
+	if w = serve("GET", log, nil); !strings.Contains(w.Body.String(), models.EventPostDeleted) {
+		t.Errorf("Deleted post should be queued for webhook, got %v", w.Body.String())
+	}
+
+	if w = serve("POST", "/admin/webhooks/delete", url.Values{"id": {m[1]}}); w.Code != http.StatusSeeOther {
+		t.Errorf("Delete webhook should redirect, got %v", w.Code)
+	}
+	if w = serve("GET", log, nil); w.Code != http.StatusNotFound {
+		t.Errorf("Deleted webhook log should be 404, got %v", w.Code)
+	}
+}
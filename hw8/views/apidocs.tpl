@@ -0,0 +1,148 @@
+<!DOCTYPE html>
+<html>
+
+<head>
+    <meta charset="UTF-8">
+    <title>{{.Title}}</title>
+    <style>
+        .operation { border: 1px solid #ccc; margin: 8px 0; padding: 8px; }
+        .method { font-weight: bold; display: inline-block; width: 70px; }
+        pre { background: #f5f5f5; padding: 4px; white-space: pre-wrap; }
+        textarea { width: 100%; height: 120px; font-family: monospace; }
+    </style>
+</head>
+
+<body>
+    <div class="container">
+        <h3 id="title">API</h3>
+        <p>OpenAPI document: <a href="{{.SpecURL}}">{{.SpecURL}}</a></p>
+        <label>Bearer token</label>
+        <input type="text" id="token" size="50">
+        <a href="/">Back</a>
+        <div id="operations"></div>
+        <h3>Schemas</h3>
+        <div id="schemas"></div>
+    </div>
+    <script>
+        "use strict";
+        var specURL = {{.SpecURL}};
+
+        function el(tag, text, cls) {
+            var e = document.createElement(tag);
+            if (text !== undefined) e.textContent = text;
+            if (cls) e.className = cls;
+            return e;
+        }
+
+        function resolve(spec, schema) {
+            if (schema && schema.$ref) {
+                return spec.components.schemas[schema.$ref.split("/").pop()];
+            }
+            return schema;
+        }
+
+        // example makes sample value of schema for request bodies
+        function example(spec, schema, depth) {
+            schema = resolve(spec, schema) || {};
+            if (depth > 4) return null;
+            if (schema.enum) return schema.enum[0];
+            switch (schema.type) {
+                case "object":
+                    var obj = {};
+                    Object.keys(schema.properties || {}).forEach(function (name) {
+                        obj[name] = example(spec, schema.properties[name], depth + 1);
+                    });
+                    return obj;
+                case "array": return [example(spec, schema.items, depth + 1)];
+                case "integer": case "number": return 0;
+                case "boolean": return false;
+                case "string": return schema.format === "date-time" ? new Date().toISOString() : "";
+            }
+            return null;
+        }
+
+        function schemaName(schema) {
+            if (!schema) return "";
+            if (schema.$ref) return schema.$ref.split("/").pop();
+            if (schema.type === "array") return schemaName(schema.items) + "[]";
+            return schema.type || "";
+        }
+
+        function renderOperation(spec, path, method, op) {
+            var div = el("div", undefined, "operation");
+            var head = el("div");
+            head.appendChild(el("span", method.toUpperCase(), "method"));
+            head.appendChild(el("code", path));
+            head.appendChild(document.createTextNode(" " + op.summary + (op.security ? " (token required)" : "")));
+            div.appendChild(head);
+
+            var inputs = {};
+            (op.parameters || []).forEach(function (p) {
+                var row = el("div");
+                row.appendChild(el("label", p.name + " (" + p.in + ") "));
+                inputs[p.name] = el("input");
+                inputs[p.name].title = p.description || "";
+                row.appendChild(inputs[p.name]);
+                if (p.description) row.appendChild(el("small", " " + p.description));
+                div.appendChild(row);
+            });
+            var body;
+            if (op.requestBody) {
+                var schema = op.requestBody.content["application/json"].schema;
+                div.appendChild(el("div", "Body: " + schemaName(schema)));
+                body = el("textarea");
+                body.value = JSON.stringify(example(spec, schema, 0), null, 2);
+                div.appendChild(body);
+            }
+            var responses = el("div", "Responses: " + Object.keys(op.responses).map(function (status) {
+                var content = op.responses[status].content;
+                var name = content ? schemaName(content["application/json"].schema) : "";
+                return status + (name ? " " + name : "");
+            }).join(", "));
+            div.appendChild(responses);
+
+            var result = el("pre");
+            var send = el("button", "Send");
+            send.onclick = function () {
+                var url = path, query = [], headers = { "Content-Type": "application/json" };
+                (op.parameters || []).forEach(function (p) {
+                    var v = inputs[p.name].value;
+                    if (p.in === "path") url = url.replace("{" + p.name + "}", encodeURIComponent(v));
+                    else if (v && p.in === "query") query.push(p.name + "=" + encodeURIComponent(v));
+                    else if (v && p.in === "header") headers[p.name] = v;
+                });
+                if (query.length) url += "?" + query.join("&");
+                var token = document.getElementById("token").value;
+                if (token) headers["Authorization"] = "Bearer " + token;
+                fetch(url, {
+                    method: method.toUpperCase(), headers: headers,
+                    body: body ? body.value : undefined, credentials: "omit"
+                }).then(function (r) {
+                    return r.text().then(function (text) {
+                        result.textContent = r.status + " " + r.statusText + "\n" + text;
+                    });
+                }).catch(function (e) { result.textContent = e; });
+            };
+            div.appendChild(send);
+            div.appendChild(result);
+            return div;
+        }
+
+        fetch(specURL).then(function (r) { return r.json(); }).then(function (spec) {
+            document.getElementById("title").textContent = spec.info.title + " " + spec.info.version;
+            var ops = document.getElementById("operations");
+            Object.keys(spec.paths).sort().forEach(function (path) {
+                Object.keys(spec.paths[path]).forEach(function (method) {
+                    ops.appendChild(renderOperation(spec, path, method, spec.paths[path][method]));
+                });
+            });
+            var schemas = document.getElementById("schemas");
+            Object.keys(spec.components.schemas).sort().forEach(function (name) {
+                schemas.appendChild(el("h4", name));
+                schemas.appendChild(el("pre", JSON.stringify(spec.components.schemas[name], null, 2)));
+            });
+        });
+    </script>
+</body>
+
+</html>
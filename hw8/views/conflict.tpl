@@ -0,0 +1,88 @@
+<!DOCTYPE html>
+<html>
+
+<head>
+    <meta charset="UTF-8">
+    <title>{{.Title}}</title>
+</head>
+
+<body>
+    <div class="container">
+        <h3>Edit Conflict</h3>
+        <p>The post was changed by someone else while you were editing it.
+            Compare both versions, merge your changes below and submit again.</p>
+        <table>
+            <tr>
+                <th>Your version</th>
+                <th>Current version</th>
+            </tr>
+            <tr>
+                <td>{{.Mine.Title}}</td>
+                <td>{{.Current.Title}}</td>
+            </tr>
+            <tr>
+                <td>{{formatDate .Mine.Date $.Locale}}</td>
+                <td>{{formatDate .Current.Date $.Locale}}</td>
+            </tr>
+            <tr>
+                <td>{{.Mine.Link}}</td>
+                <td>{{.Current.Link}}</td>
+            </tr>
+            <tr>
+                <td>{{.Mine.Category}}</td>
+                <td>{{.Current.Category}}</td>
+            </tr>
+            <tr>
+                <td>{{.Mine.TagList}}</td>
+                <td>{{.Current.TagList}}</td>
+            </tr>
+            <tr>
+                <td><pre>{{.Mine.Content}}</pre></td>
+                <td><pre>{{.Current.Content}}</pre></td>
+            </tr>
+        </table>
+        <form method="POST" action="/edit">
+            {{csrfField $.CSRFToken}}
+            <input type="hidden" name="id" value="{{.Current.ID.Hex}}">
+            <input type="hidden" name="version" value="{{.Current.Version}}">
+            <table>
+                <tr>
+                    <td>
+                        <label>Title</label>
+                        <input type="title" name="title" value="{{.Mine.Title}}">
+                    </td>
+                    <td>
+                        <label>Date</label>
+                        <input type="date" name="date" value="{{inputDate .Mine.Date}}">
+                    </td>
+                </tr>
+                <tr>
+                    <td colspan="2">
+                        <label>Link</label>
+                        <input type="text" name="link" value="{{.Mine.Link}}">
+                    </td>
+                </tr>
+                <tr>
+                    <td>
+                        <label>Category</label>
+                        <input type="text" name="category" value="{{.Mine.Category}}">
+                    </td>
+                    <td>
+                        <label>Tags</label>
+                        <input type="text" name="tags" value="{{.Mine.TagList}}">
+                    </td>
+                </tr>
+                <tr>
+                    <td colspan="2">
+                        <label>Content</label><br>
+                        <textarea name="content" rows="10" cols="40">{{.Mine.Content}}</textarea>
+                    </td>
+                </tr>
+            </table>
+            <input type="submit" value="submit">
+            <a href="{{postURL .Current}}">Back</a>
+        </form>
+    </div>
+</body>
+
+</html>
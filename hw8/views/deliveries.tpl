@@ -0,0 +1,48 @@
+<!DOCTYPE html>
+<html>
+
+<head>
+    <meta charset="UTF-8">
+    <title>{{.Title}}</title>
+</head>
+
+<body>
+    <div>
+        <h1>{{.Title}}</h1>
+        <table>
+            <tr>
+                <th>Event</th>
+                <th>Queued</th>
+                <th>Status</th>
+                <th>Attempts</th>
+                <th>Response</th>
+                <th>Next attempt</th>
+                <th></th>
+            </tr>
+            {{range .Deliveries}}
+            <tr>
+                <td><details><summary>{{.Event}}</summary><pre>{{.Payload}}</pre></details></td>
+                <td>{{formatDateTime .CreatedAt $.Locale}}</td>
+                <td>{{.Status}}{{if .DeliveredAt}} {{formatDateTime .DeliveredAt $.Locale}}{{end}}</td>
+                <td>{{.Attempts}}</td>
+                <td>{{if .ResponseStatus}}{{.ResponseStatus}}{{end}} {{.LastError}}</td>
+                <td>{{if eq .Status "pending"}}{{formatDateTime .NextAttemptAt $.Locale}}{{end}}</td>
+                <td>
+                    <form action="/admin/webhooks/redeliver" method="post">
+                        {{csrfField $.CSRFToken}}
+                        <input type="hidden" name="id" value="{{.ID.Hex}}">
+                        <button type="submit">Redeliver</button>
+                    </form>
+                </td>
+            </tr>
+            {{else}}
+            <tr>
+                <td colspan="7">Nothing delivered yet</td>
+            </tr>
+            {{end}}
+        </table>
+        <a href="/admin/webhooks">Back</a>
+    </div>
+</body>
+
+</html>
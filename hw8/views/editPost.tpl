@@ -10,11 +10,13 @@
     <div class="container">
         <h3>Edit Post</h3>
         <form method="POST" action="/edit">
+            {{csrfField $.CSRFToken}}
             <table>
                 <tr>
                     <td style="display:none;">
                         <label>Id</label>
-                        <input type="id" name="id" value="{{.Post.ID}}">
+                        <input type="id" name="id" value="{{.Post.ID.Hex}}">
+                        <input type="hidden" name="version" value="{{.Post.Version}}">
                     </td>
                     <td>
                         <label>Title</label>
@@ -22,7 +24,7 @@
                     </td>
                     <td>
                         <label>Date</label>
-                        <input type="date" name="date" value="{{.Post.Date}}">
+                        <input type="date" name="date" value="{{inputDate .Post.Date}}">
                     </td>
                 </tr>
                 <tr>
@@ -32,9 +34,42 @@
                     </td>
                 </tr>
                 <tr>
-                    <td colspan="3">
-                        <label>Content</label><br>
-                        <textarea name="content" rows="10" cols="40">{{.Post.Content}}</textarea>
+                    <td>
+                        <label>Status</label>
+                        <select name="status">
+                            {{$status := .Post.Status}}
+                            {{range .Statuses}}
+                            <option value="{{.}}" {{if eq . $status}}selected{{end}}>{{.}}</option>
+                            {{end}}
+                        </select>
+                    </td>
+                    <td colspan="2">
+                        <label>Publish at</label>
+                        <input type="datetime-local" name="publish_at"
+                            value="{{with .Post.PublishAt}}{{inputDateTime .}}{{end}}">
+                        <small>needed for scheduled posts</small>
+                    </td>
+                </tr>
+                <tr>
+                    <td>
+                        <label>Category</label>
+                        <input type="text" name="category" value="{{.Post.Category}}">
+                    </td>
+                    <td colspan="2">
+                        <label>Tags</label>
+                        <input type="text" id="tags" name="tags" value="{{.Post.TagList}}" list="tag-suggestions"
+                            autocomplete="off" placeholder="comma, separated, tags">
+                        <datalist id="tag-suggestions"></datalist>
+                    </td>
+                </tr>
+                <tr>
+                    <td colspan="2">
+                        <label>Content (Markdown)</label><br>
+                        <textarea id="content" name="content" rows="20" cols="60">{{.Post.Content}}</textarea>
+                    </td>
+                    <td style="vertical-align: top;">
+                        <label>Preview</label>
+                        <div id="preview" style="min-width: 30em; border: 1px solid #ccc; padding: 0 1em;"></div>
                     </td>
                 </tr>
             </table>
@@ -42,6 +77,57 @@
             <a href="/">Back</a>
         </form>
     </div>
+    <script>
+        // render content preview shortly after typing stops
+        (function () {
+            var content = document.getElementById("content");
+            var preview = document.getElementById("preview");
+            var timer;
+            function update() {
+                var body = new URLSearchParams({ content: content.value });
+                fetch("/preview", {
+                    method: "POST",
+                    headers: { "X-CSRF-Token": "{{.CSRFToken}}" },
+                    body: body
+                })
+                    .then(function (r) { return r.ok ? r.text() : ""; })
+                    .then(function (html) { preview.innerHTML = html; });
+            }
+            content.addEventListener("input", function () {
+                clearTimeout(timer);
+                timer = setTimeout(update, 300);
+            });
+            update();
+        })();
+
+        // suggest known tags for the tag being typed, keeping the ones before it
+        (function () {
+            var input = document.getElementById("tags");
+            var list = document.getElementById("tag-suggestions");
+            input.addEventListener("input", function () {
+                var parts = input.value.split(",");
+                var prefix = parts.pop().trim();
+                var head = parts.map(function (t) { return t.trim(); }).filter(Boolean);
+                if (!prefix) {
+                    list.innerHTML = "";
+                    return;
+                }
+                fetch("/tags?q=" + encodeURIComponent(prefix))
+                    .then(function (r) { return r.json(); })
+                    .then(function (tags) {
+                        list.innerHTML = "";
+                        tags.forEach(function (tag) {
+                            if (head.indexOf(tag) >= 0) {
+                                return;
+                            }
+                            var option = document.createElement("option");
+                            option.value = head.concat([tag]).join(", ");
+                            list.appendChild(option);
+                        });
+                    });
+            });
+        })();
+    </script>
 </body>
 
 </html>
\ No newline at end of file
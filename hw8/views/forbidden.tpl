@@ -0,0 +1,21 @@
+<!DOCTYPE html>
+<html>
+
+<head>
+    <meta charset="UTF-8">
+    <title>{{.Title}}</title>
+</head>
+
+<body>
+    <div>
+        <h1>Forbidden</h1>
+        {{if .Message}}
+        <p>{{.Message}}</p>
+        {{else}}
+        <p>{{if .User}}{{.User.Name}}, you{{else}}You{{end}} are not allowed to do this.</p>
+        {{end}}
+        <a href="/">Back</a>
+    </div>
+</body>
+
+</html>
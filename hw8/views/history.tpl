@@ -0,0 +1,58 @@
+<!DOCTYPE html>
+<html>
+
+<head>
+    <meta charset="UTF-8">
+    <title>{{.Title}}</title>
+    <style>
+        .ins { background: #dfd; }
+        .del { background: #fdd; }
+    </style>
+</head>
+
+<body>
+    <div>
+        <h1>History: {{.Post.Title}}</h1>
+        <form method="GET" action="/post/history">
+            <input type="hidden" name="id" value="{{.Post.ID.Hex}}">
+            <table>
+                <tr>
+                    <th>From</th>
+                    <th>To</th>
+                    <th>Revision</th>
+                    <th>Saved</th>
+                    <th>Editor</th>
+                    <th></th>
+                </tr>
+                {{range .Revisions}}
+                <tr>
+                    <td><input type="radio" name="from" value="{{.Number}}" {{if eq .Number $.From}}checked{{end}}></td>
+                    <td><input type="radio" name="to" value="{{.Number}}" {{if eq .Number $.To}}checked{{end}}></td>
+                    <td>#{{.Number}}{{if .RestoredFrom}} (restored from #{{.RestoredFrom}}){{end}}</td>
+                    <td>{{formatDateTime .CreatedAt $.Locale}}</td>
+                    <td>{{.Editor}}</td>
+                    <td>
+                        <button type="submit" form="restore{{.Number}}">Restore</button>
+                    </td>
+                </tr>
+                {{end}}
+            </table>
+            <input type="submit" value="Compare">
+        </form>
+        {{range .Revisions}}
+        <form id="restore{{.Number}}" method="POST" action="/post/history/restore">
+            {{csrfField $.CSRFToken}}
+            <input type="hidden" name="id" value="{{$.Post.ID.Hex}}">
+            <input type="hidden" name="revision" value="{{.Number}}">
+        </form>
+        {{end}}
+        {{if .Diff}}
+        <h3>Changes from #{{.From}} to #{{.To}}</h3>
+        <pre>{{range .Diff}}{{if .IsInsert}}<span class="ins">+ {{.Text}}</span>{{else if .IsDelete}}<span class="del">- {{.Text}}</span>{{else}}  {{.Text}}{{end}}
+{{end}}</pre>
+        {{end}}
+        <a href="{{postURL .Post}}">Back</a>
+    </div>
+</body>
+
+</html>
@@ -4,29 +4,54 @@
 <head>
     <meta charset="UTF-8">
     <title>{{.Title}}</title>
+    <link rel="alternate" type="application/rss+xml" title="RSS" href="/feed.xml">
+    <link rel="alternate" type="application/atom+xml" title="Atom" href="/atom.xml">
+    <style>
+        .tag1 { font-size: 0.8em; }
+        .tag2 { font-size: 1em; }
+        .tag3 { font-size: 1.2em; }
+        .tag4 { font-size: 1.5em; }
+        .tag5 { font-size: 1.8em; }
+    </style>
 </head>
 
 <body>
     <div>
         <h1>{{.Title}}</h1>
+        <div>
+            {{if .User}}
+            <form action="/logout" method="post">
+                {{csrfField $.CSRFToken}}
+                {{.User.Name}} ({{.User.Role}})
+                {{if .User.HasRole "admin"}}<a href="/admin/users">Users</a> <a href="/admin/webhooks">Webhooks</a>{{end}}
+                <button type="submit">Logout</button>
+            </form>
+            {{else}}
+            <a href="/login">Login</a>
+            <a href="/register">Register</a>
+            {{end}}
+        </div>
         <div>
             <form action="/new" method="post">
+                {{csrfField $.CSRFToken}}
                 <button type="submit" name="newPost" value="newPost">New</button>
             </form>
-            <ul>
-                {{range .Posts}}
-                <li>
-                    <div>
-                        <h3>{{.Title}}</h3>
-                        <h4>{{.Date}}</h4>
-                        <p>{{.Content}}</p>
-                        <p>{{.Link}}</p>
-                        <a href="/post/?id={{.ID}}">Read</a>
-                        <a href="/edit/?id={{.ID}}">Edit</a>
-                    </div>
-                </li>
-                {{end}}
-            </ul>
+            {{if .User}}<a href="/drafts">My drafts</a>{{end}}
+            <a href="/trash">Trash</a>
+            <form action="/search" method="get">
+                <input type="search" name="q">
+                <button type="submit">Search</button>
+            </form>
+            {{if .Tags}}
+            <div>
+                {{range .Tags}}<a class="tag{{.Weight}}" href="/tag/{{.Name}}" title="{{.Count}} posts">{{.Name}}</a> {{end}}
+            </div>
+            {{end}}
+            {{template "posts.tpl" .}}
+            <div>
+                {{if .Page.Prev}}<a href="/?cursor={{.Page.Prev}}">Newer posts</a>{{end}}
+                {{if .Page.Next}}<a href="/?cursor={{.Page.Next}}">Older posts</a>{{end}}
+            </div>
         </div>
     </div>
 </body>
@@ -0,0 +1,20 @@
+<!DOCTYPE html>
+<html>
+
+<head>
+    <meta charset="UTF-8">
+    <title>{{.Title}}</title>
+    {{with .FeedURL}}<link rel="alternate" type="application/rss+xml" title="RSS" href="{{.}}">{{end}}
+</head>
+
+<body>
+    <div>
+        <h1>{{.Title}}</h1>
+        <div>
+            <a href="/">Back</a>
+            {{template "posts.tpl" .}}
+        </div>
+    </div>
+</body>
+
+</html>
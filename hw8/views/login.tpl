@@ -0,0 +1,29 @@
+<!DOCTYPE html>
+<html>
+
+<head>
+    <meta charset="UTF-8">
+    <title>{{.Title}}</title>
+</head>
+
+<body>
+    <div class="container">
+        <h3>Login</h3>
+        {{if .Error}}<p>{{.Error}}</p>{{end}}
+        <form method="POST" action="/login">
+            {{csrfField $.CSRFToken}}
+            <input type="hidden" name="next" value="{{.Next}}">
+            <label>Name</label>
+            <input type="text" name="name" value="{{.Name}}" autocomplete="username">
+            <br>
+            <label>Password</label>
+            <input type="password" name="password" autocomplete="current-password">
+            <br>
+            <input type="submit" value="Login">
+            <a href="/register">Register</a>
+            <a href="/">Back</a>
+        </form>
+    </div>
+</body>
+
+</html>
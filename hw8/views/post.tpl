@@ -11,10 +11,61 @@
         <h1>{{.Title}}</h1>
         <div>
             <h3>{{.Post.Title}}</h3>
-            <h4>{{.Post.Date}}</h4>
-            <p>{{.Post.Content}}</p>
+            <h4><a href="{{archiveURL .Post.Date}}">{{formatDate .Post.Date .Locale}}</a></h4>
+            <p><small>Updated {{formatDateTime .Post.UpdatedAt .Locale}}</small></p>
+            {{if not .Post.IsPublished}}
+            <p><b>{{.Post.Status}}</b>{{with .Post.PublishAt}}, publishes at {{formatDateTime . $.Locale}}{{end}}</p>
+            {{end}}
+            {{if .Post.Category}}<p>Category: <a href="/category/{{.Post.Category}}">{{.Post.Category}}</a></p>{{end}}
+            {{if .Post.Tags}}<p>Tags: {{range .Post.Tags}}<a href="/tag/{{.}}">{{.}}</a> {{end}}</p>{{end}}
+            <div>{{markdown .Post.Content}}</div>
             <p>{{.Post.Link}}</p>
-            <a href="/edit/?id={{.Post.ID}}">Edit</a>
+            <a href="/edit?id={{.Post.ID.Hex}}">Edit</a>
+            <a href="/post/history?id={{.Post.ID.Hex}}">History</a>
+            <form action="/delete" method="post">
+                {{csrfField $.CSRFToken}}
+                <input type="hidden" name="id" value="{{.Post.ID.Hex}}">
+                <button type="submit">Delete</button>
+            </form>
+        </div>
+        <div>
+            <h3>Comments</h3>
+            {{range .Comments}}
+            <div id="comment-{{.ID.Hex}}" style="margin-left: {{.Depth}}em; border-left: 1px solid #ccc; padding-left: 1em;">
+                <p><b>{{.Author}}</b> {{formatDateTime .CreatedAt $.Locale}}</p>
+                <p>{{.Text}}</p>
+                <details>
+                    <summary>Reply</summary>
+                    <form action="/post/comment" method="post">
+                        {{csrfField $.CSRFToken}}
+                        <input type="hidden" name="id" value="{{$.Post.ID.Hex}}">
+                        <input type="hidden" name="parent" value="{{.ID.Hex}}">
+                        {{if not $.User}}
+                        <input type="text" name="author" placeholder="Name">
+                        <br>
+                        {{end}}
+                        <textarea name="text" rows="3" cols="40"></textarea>
+                        <br>
+                        <button type="submit">Reply</button>
+                    </form>
+                </details>
+            </div>
+            {{else}}
+            <p>No comments yet</p>
+            {{end}}
+            <form action="/post/comment" method="post">
+                {{csrfField $.CSRFToken}}
+                <input type="hidden" name="id" value="{{.Post.ID.Hex}}">
+                {{if .User}}
+                <p>Comment as {{.User.Name}}</p>
+                {{else}}
+                <input type="text" name="author" placeholder="Name">
+                <br>
+                {{end}}
+                <textarea name="text" rows="5" cols="40"></textarea>
+                <br>
+                <button type="submit">Comment</button>
+            </form>
         </div>
     </div>
 </body>
@@ -4,13 +4,30 @@
         <li>
             <div>
                 <h3>{{.Title}}</h3>
-                <h4>{{.Date}}</h4>
-                <p>{{.Content}}</p>
+                <h4><a href="{{archiveURL .Date}}">{{formatDate .Date $.Locale}}</a></h4>
+                {{if not .IsPublished}}
+                <p><b>{{.Status}}</b>{{with .PublishAt}}, publishes at {{formatDateTime . $.Locale}}{{end}}</p>
+                {{end}}
+                {{if .Category}}<p>Category: <a href="/category/{{.Category}}">{{.Category}}</a></p>{{end}}
+                {{if .Tags}}<p>Tags: {{range .Tags}}<a href="/tag/{{.}}">{{.}}</a> {{end}}</p>{{end}}
+                {{if $.Search}}
+                <p>{{.Snippet}}</p>
+                {{else}}
+                <div>{{markdown .Content}}</div>
+                {{end}}
                 <p>{{.Link}}</p>
-                <a href="/post/?id={{.ID}}">Read</a>
-                <a href="/edit/?id={{.ID}}">Edit</a>
+                {{if $.CommentCounts}}<p>Comments: {{index $.CommentCounts .ID.Hex}}</p>{{end}}
+                <a href="{{postURL .}}">Read</a>
+                <a href="/edit?id={{.ID.Hex}}">Edit</a>
+                <form action="/delete" method="post">
+                    {{csrfField $.CSRFToken}}
+                    <input type="hidden" name="id" value="{{.ID.Hex}}">
+                    <button type="submit">Delete</button>
+                </form>
             </div>
         </li>
+        {{else}}
+        <li>No posts found</li>
         {{end}}
     </ul>
-</div>
\ No newline at end of file
+</div>
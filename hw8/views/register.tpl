@@ -0,0 +1,31 @@
+<!DOCTYPE html>
+<html>
+
+<head>
+    <meta charset="UTF-8">
+    <title>{{.Title}}</title>
+</head>
+
+<body>
+    <div class="container">
+        <h3>Register</h3>
+        {{if .Error}}<p>{{.Error}}</p>{{end}}
+        <form method="POST" action="/register">
+            {{csrfField $.CSRFToken}}
+            <label>Name</label>
+            <input type="text" name="name" value="{{.Name}}" autocomplete="username">
+            <br>
+            <label>Password</label>
+            <input type="password" name="password" autocomplete="new-password">
+            <br>
+            <label>Repeat password</label>
+            <input type="password" name="confirm" autocomplete="new-password">
+            <br>
+            <input type="submit" value="Register">
+            <a href="/login">Login</a>
+            <a href="/">Back</a>
+        </form>
+    </div>
+</body>
+
+</html>
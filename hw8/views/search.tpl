@@ -0,0 +1,23 @@
+<!DOCTYPE html>
+<html>
+
+<head>
+    <meta charset="UTF-8">
+    <title>{{.Title}}</title>
+</head>
+
+<body>
+    <div>
+        <h1>{{.Title}}</h1>
+        <div>
+            <form action="/search" method="get">
+                <input type="search" name="q" value="{{.Query}}">
+                <button type="submit">Search</button>
+            </form>
+            <a href="/">Back</a>
+            {{template "posts.tpl" .}}
+        </div>
+    </div>
+</body>
+
+</html>
@@ -0,0 +1,41 @@
+<!DOCTYPE html>
+<html>
+
+<head>
+    <meta charset="UTF-8">
+    <title>{{.Title}}</title>
+</head>
+
+<body>
+    <div>
+        <h1>{{.Title}}</h1>
+        <p>Posts are removed permanently after {{.RetentionDays}} days in trash.</p>
+        <div>
+            <ul>
+                {{range .Posts}}
+                <li>
+                    <div>
+                        <h3>{{.Title}}</h3>
+                        <h4>Deleted: {{formatDateTime .DeletedAt $.Locale}}</h4>
+                        <form action="/trash/restore" method="post">
+                            {{csrfField $.CSRFToken}}
+                            <input type="hidden" name="id" value="{{.ID.Hex}}">
+                            <button type="submit">Restore</button>
+                        </form>
+                        <form action="/trash/purge" method="post">
+                            {{csrfField $.CSRFToken}}
+                            <input type="hidden" name="id" value="{{.ID.Hex}}">
+                            <button type="submit">Delete forever</button>
+                        </form>
+                    </div>
+                </li>
+                {{else}}
+                <li>Trash is empty</li>
+                {{end}}
+            </ul>
+            <a href="/">Back</a>
+        </div>
+    </div>
+</body>
+
+</html>
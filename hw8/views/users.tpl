@@ -0,0 +1,46 @@
+<!DOCTYPE html>
+<html>
+
+<head>
+    <meta charset="UTF-8">
+    <title>{{.Title}}</title>
+</head>
+
+<body>
+    <div>
+        <h1>{{.Title}}</h1>
+        <table>
+            <tr>
+                <th>Name</th>
+                <th>Registered</th>
+                <th>Role</th>
+            </tr>
+            {{range .Users}}
+            <tr>
+                <td>{{.Name}}</td>
+                <td>{{formatDate .CreatedAt $.Locale}}</td>
+                <td>
+                    {{if eq .ID.Hex $.User.ID.Hex}}
+                    {{.Role}}
+                    {{else}}
+                    <form action="/admin/users/role" method="post">
+                        {{csrfField $.CSRFToken}}
+                        <input type="hidden" name="id" value="{{.ID.Hex}}">
+                        <select name="role">
+                            {{$role := .Role}}
+                            {{range $.Roles}}
+                            <option value="{{.}}" {{if eq . $role}}selected{{end}}>{{.}}</option>
+                            {{end}}
+                        </select>
+                        <button type="submit">Save</button>
+                    </form>
+                    {{end}}
+                </td>
+            </tr>
+            {{end}}
+        </table>
+        <a href="/">Back</a>
+    </div>
+</body>
+
+</html>
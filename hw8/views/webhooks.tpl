@@ -0,0 +1,57 @@
+<!DOCTYPE html>
+<html>
+
+<head>
+    <meta charset="UTF-8">
+    <title>{{.Title}}</title>
+</head>
+
+<body>
+    <div>
+        <h1>{{.Title}}</h1>
+        <p>Receivers get json posts signed with their secret in X-Webhook-Signature header,
+            sha256= followed by hex HMAC-SHA256 of X-Webhook-Timestamp value, a dot and the body.
+            Reject requests whose timestamp is minutes old, they may be replayed.
+            Events are sent only about published posts.</p>
+        <table>
+            <tr>
+                <th>Url</th>
+                <th>Events</th>
+                <th>Secret</th>
+                <th>Added</th>
+                <th></th>
+            </tr>
+            {{range .Webhooks}}
+            <tr>
+                <td><a href="/admin/webhooks/deliveries?id={{.ID.Hex}}">{{.URL}}</a></td>
+                <td>{{range .Events}}{{.}} {{end}}</td>
+                <td><code>{{.Secret}}</code></td>
+                <td>{{formatDate .CreatedAt $.Locale}}</td>
+                <td>
+                    <form action="/admin/webhooks/delete" method="post">
+                        {{csrfField $.CSRFToken}}
+                        <input type="hidden" name="id" value="{{.ID.Hex}}">
+                        <button type="submit">Delete</button>
+                    </form>
+                </td>
+            </tr>
+            {{else}}
+            <tr>
+                <td colspan="5">No webhooks</td>
+            </tr>
+            {{end}}
+        </table>
+        <h2>Add webhook</h2>
+        <form action="/admin/webhooks" method="post">
+            {{csrfField $.CSRFToken}}
+            <input type="url" name="url" placeholder="https://example.com/hook" required>
+            {{range .Events}}
+            <label><input type="checkbox" name="events" value="{{.}}" checked>{{.}}</label>
+            {{end}}
+            <button type="submit">Add</button>
+        </form>
+        <a href="/">Back</a>
+    </div>
+</body>
+
+</html>